@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/uselagoon/build-deploy-tool/internal/dbaasclient"
+)
+
+var fakeDBaaSOperatorCmd = &cobra.Command{
+	Use:   "fake-dbaas-operator",
+	Short: "Run a fake dbaas-operator",
+	Long: `Run a fake dbaas-operator that responds with the providers in the config file
+This can be used to test which services will use dbaas or fall back to single services in local and CI builds,
+without a real dbaas-operator. Set DBAAS_OPERATOR_HTTP to the address the fake dbaas-operator is listening on.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return fmt.Errorf("error reading config flag: %v", err)
+		}
+		listen, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			return fmt.Errorf("error reading listen flag: %v", err)
+		}
+		config, err := dbaasclient.ReadFakeOperatorConfig(configFile)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("fake dbaas-operator listening on %s\n", listen)
+		return http.ListenAndServe(listen, dbaasclient.NewFakeOperator(*config))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fakeDBaaSOperatorCmd)
+	fakeDBaaSOperatorCmd.Flags().StringP("config", "c", "",
+		"The config file with the providers of the fake dbaas-operator")
+	fakeDBaaSOperatorCmd.MarkFlagRequired("config")
+	fakeDBaaSOperatorCmd.Flags().String("listen", ":5000",
+		"The address the fake dbaas-operator listens on")
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uselagoon/build-deploy-tool/internal/deploy"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+)
+
+var deployApply = &cobra.Command{
+	Use:     "apply",
+	Aliases: []string{"a"},
+	Short:   "Server-side apply the generated templates and prune anything no longer generated",
+	Long: `Server-side apply the generated templates and prune anything no longer generated
+All the yaml files within the provided paths (or the saved-templates-path if none are provided) are applied to the namespace
+using the build-deploy-tool field manager. Any jobs are run once the resources they depend on are applied, and the
+deployments are only applied once every job has completed successfully. With --prune, any resources labeled as managed
+by build-deploy-tool for this environment that are not in the provided paths are then removed, so it should only be used
+when the paths contain every template of the build. Persistent volume claims are only removed if --prune-volumes is set.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths, err := cmd.Flags().GetStringSlice("path")
+		if err != nil {
+			return fmt.Errorf("error reading path flag: %v", err)
+		}
+		if len(paths) == 0 {
+			savedTemplates, err := rootCmd.PersistentFlags().GetString("saved-templates-path")
+			if err != nil {
+				return fmt.Errorf("error reading saved-templates-path flag: %v", err)
+			}
+			paths = []string{savedTemplates}
+		}
+		prune, err := cmd.Flags().GetBool("prune")
+		if err != nil {
+			return fmt.Errorf("error reading prune flag: %v", err)
+		}
+		pruneVolumes, err := cmd.Flags().GetBool("prune-volumes")
+		if err != nil {
+			return fmt.Errorf("error reading prune-volumes flag: %v", err)
+		}
+		jobTimeout, err := cmd.Flags().GetDuration("job-timeout")
+		if err != nil {
+			return fmt.Errorf("error reading job-timeout flag: %v", err)
+		}
+		logLines, err := cmd.Flags().GetInt64("log-lines")
+		if err != nil {
+			return fmt.Errorf("error reading log-lines flag: %v", err)
+		}
+		namespace, err := deployCmd.PersistentFlags().GetString("namespace")
+		if err != nil {
+			return fmt.Errorf("error reading namespace flag: %v", err)
+		}
+		client, err := newDeployClient(namespace, true)
+		if err != nil {
+			return err
+		}
+		return DeployApply(client, paths, prune, pruneVolumes, deploy.JobOptions{
+			Timeout:  jobTimeout,
+			Interval: 5 * time.Second,
+			LogLines: logLines,
+		})
+	},
+}
+
+// newDeployClient returns a deploy client for the namespace and environment of this build
+func newDeployClient(namespace string, debug bool) (*deploy.Client, error) {
+	environmentName, err := rootCmd.PersistentFlags().GetString("environment-name")
+	if err != nil {
+		return nil, fmt.Errorf("error reading environment-name flag: %v", err)
+	}
+	// source the namespace and environment the same way the generator does
+	environmentName = helpers.GetEnv("ENVIRONMENT", environmentName, debug)
+	namespace = helpers.GetEnv("NAMESPACE", namespace, debug)
+	namespace, err = helpers.GetNamespace(namespace, "/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+	if err != nil {
+		return nil, err
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("unable to determine the namespace to deploy to")
+	}
+	client, err := deploy.NewClient(namespace, environmentName, debug)
+	if err != nil {
+		return nil, err
+	}
+	// any additional dbaas types are loaded the same way the generator does, so their consumers can be pruned
+	client.DBaaSTypes, err = servicetypes.GetDBaaSTypes(
+		helpers.GetEnv("DBAAS_TYPES_FILE", "", debug),
+		helpers.GetEnvBool("DBAAS_TYPES_ALLOW_OVERRIDES", false, debug),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// DeployApply applies the templates in the provided paths, and optionally prunes anything not in them.
+// Jobs are run after the resources they depend on are applied, and must succeed before the deployments are applied
+func DeployApply(client *deploy.Client, paths []string, prune, pruneVolumes bool, jobOpts deploy.JobOptions) error {
+	// the result is set before anything is applied, so a failure still reports what was done up to that point
+	result := &deployApplyResult{Applied: []string{}, Jobs: []deploy.JobStatus{}, Pruned: []string{}}
+	setResult(result)
+	objects, err := deploy.ReadManifests(paths...)
+	if err != nil {
+		return err
+	}
+	before, jobs, after := deploy.SplitJobs(objects)
+	applied, err := client.Apply(context.TODO(), before)
+	for _, a := range applied {
+		fmt.Printf("%s applied\n", a)
+	}
+	result.Applied = append(result.Applied, applied...)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		fmt.Printf("Running job %s\n", job.GetName())
+		jobResult := client.RunJob(context.TODO(), job, jobOpts)
+		printJobResult(jobResult)
+		result.Jobs = append(result.Jobs, jobResult)
+		if !jobResult.Succeeded {
+			return fmt.Errorf("job %s failed, the deployments have not been applied", jobResult.Job)
+		}
+	}
+	applied, err = client.Apply(context.TODO(), after)
+	for _, a := range applied {
+		fmt.Printf("%s applied\n", a)
+	}
+	result.Applied = append(result.Applied, applied...)
+	if err != nil {
+		return err
+	}
+	if !prune {
+		return nil
+	}
+	pruned, err := client.Prune(context.TODO(), objects, pruneVolumes)
+	for _, p := range pruned {
+		fmt.Printf("%s pruned\n", p)
+	}
+	result.Pruned = append(result.Pruned, pruned...)
+	return err
+}
+
+// deployApplyResult is the result of the deploy apply command
+type deployApplyResult struct {
+	Applied []string           `json:"applied"`
+	Jobs    []deploy.JobStatus `json:"jobs"`
+	Pruned  []string           `json:"pruned"`
+}
+
+func printJobResult(result deploy.JobStatus) {
+	exitCode := "unknown"
+	if result.ExitCode != nil {
+		exitCode = fmt.Sprintf("%d", *result.ExitCode)
+	}
+	if result.Succeeded {
+		fmt.Printf("Job %s completed, exit code %s\n", result.Job, exitCode)
+	} else {
+		fmt.Println("##############################################")
+		fmt.Printf("Job %s failed: %s, exit code %s\n", result.Job, result.Reason, exitCode)
+		if result.Message != "" {
+			fmt.Printf("  %s\n", result.Message)
+		}
+	}
+	if result.Logs != "" {
+		fmt.Printf("  Logs (%s):\n", result.Pod)
+		for _, line := range strings.Split(strings.TrimRight(result.Logs, "\n"), "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+	if !result.Succeeded {
+		fmt.Println("##############################################")
+	}
+}
+
+func init() {
+	deployCmd.AddCommand(deployApply)
+	deployCmd.PersistentFlags().StringP("namespace", "n", "",
+		"The namespace of the environment, defaults to the namespace of the service account if running in a cluster")
+	deployApply.Flags().StringSliceP("path", "", []string{},
+		"Path to a directory or file of templates to apply, can be provided multiple times (defaults to the saved-templates-path)")
+	deployApply.Flags().BoolP("prune", "", false,
+		"Remove any resources managed by build-deploy-tool for this environment that are not in the provided paths")
+	deployApply.Flags().BoolP("prune-volumes", "", false,
+		"Also remove persistent volume claims that are no longer generated")
+	// each job is waited on for its activeDeadlineSeconds (lagoon.job.timeout) plus a margin, this is the upper bound
+	deployApply.Flags().DurationP("job-timeout", "", 24*time.Hour,
+		"The longest time to wait for each job to complete, jobs are otherwise waited on until shortly after their own timeout")
+	deployApply.Flags().Int64P("log-lines", "", 100,
+		"How many lines of logs to show from each job")
+}
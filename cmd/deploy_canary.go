@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uselagoon/build-deploy-tool/internal/deploy"
+)
+
+var deployCanary = &cobra.Command{
+	Use:     "canary",
+	Aliases: []string{"c"},
+	Short:   "Change the weight of the canary of a route",
+	Long: `Change the weight of the canary of a route
+Sets the percentage of requests for the route that are sent to its canary with --weight, or moves it by --step.
+With --until, the weight is moved by --step every --interval until it reaches the provided weight, so a release can be
+given more of the requests step by step. A build that uses deploy apply sets the weight back to the one in the .lagoon.yml.
+A build that uses kubectl apply keeps the weight set by this command until the weight in the .lagoon.yml is changed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		route, err := cmd.Flags().GetString("route")
+		if err != nil {
+			return fmt.Errorf("error reading route flag: %v", err)
+		}
+		if route == "" {
+			return fmt.Errorf("the route to change the canary weight of must be provided")
+		}
+		weight, err := cmd.Flags().GetInt("weight")
+		if err != nil {
+			return fmt.Errorf("error reading weight flag: %v", err)
+		}
+		step, err := cmd.Flags().GetInt("step")
+		if err != nil {
+			return fmt.Errorf("error reading step flag: %v", err)
+		}
+		until, err := cmd.Flags().GetInt("until")
+		if err != nil {
+			return fmt.Errorf("error reading until flag: %v", err)
+		}
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return fmt.Errorf("error reading interval flag: %v", err)
+		}
+		namespace, err := deployCmd.PersistentFlags().GetString("namespace")
+		if err != nil {
+			return fmt.Errorf("error reading namespace flag: %v", err)
+		}
+		client, err := newDeployClient(namespace, false)
+		if err != nil {
+			return err
+		}
+		return DeployCanary(client, route, weight, step, until, interval)
+	},
+}
+
+// DeployCanary sets the weight of the canary of a route, or moves it by step until it reaches the until weight.
+// A weight or until of -1 is not set
+func DeployCanary(client *deploy.Client, route string, weight, step, until int, interval time.Duration) error {
+	if weight >= 0 {
+		if err := client.SetCanaryWeight(context.TODO(), route, weight); err != nil {
+			return err
+		}
+		setResult(deployCanaryResult{Route: route, Weight: weight})
+		fmt.Printf("Canary weight of %s set to %d\n", route, weight)
+		return nil
+	}
+	if step == 0 {
+		return fmt.Errorf("either --weight or --step must be provided")
+	}
+	current, err := client.CanaryWeight(context.TODO(), route)
+	if err != nil {
+		return err
+	}
+	// the result is the weight the canary has been moved to so far
+	result := &deployCanaryResult{Route: route, Weight: current}
+	setResult(result)
+	target := until
+	if target < 0 {
+		target = current + step
+	}
+	target = min(max(target, 0), 100)
+	if (target-current)*step < 0 {
+		return fmt.Errorf("a step of %d moves the canary weight of %s away from %d", step, route, target)
+	}
+	for current != target {
+		next := current + step
+		// don't step past the target
+		if (step > 0 && next > target) || (step < 0 && next < target) {
+			next = target
+		}
+		if err := client.SetCanaryWeight(context.TODO(), route, next); err != nil {
+			return err
+		}
+		fmt.Printf("Canary weight of %s moved from %d to %d\n", route, current, next)
+		current = next
+		result.Weight = current
+		if current != target {
+			time.Sleep(interval)
+		}
+	}
+	return nil
+}
+
+// deployCanaryResult is the result of the deploy canary command
+type deployCanaryResult struct {
+	Route  string `json:"route"`
+	Weight int    `json:"weight"`
+}
+
+func init() {
+	deployCmd.AddCommand(deployCanary)
+	deployCanary.Flags().StringP("route", "", "",
+		"The domain of the route to change the canary weight of")
+	deployCanary.Flags().IntP("weight", "", -1,
+		"Set the canary weight to this percentage")
+	deployCanary.Flags().IntP("step", "", 0,
+		"Move the canary weight by this many percent, negative to move it down")
+	deployCanary.Flags().IntP("until", "", -1,
+		"Keep moving the canary weight by step until it reaches this percentage")
+	deployCanary.Flags().DurationP("interval", "", 5*time.Minute,
+		"How long to wait between each step when until is set")
+}
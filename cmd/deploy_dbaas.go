@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uselagoon/build-deploy-tool/internal/deploy"
+	generator "github.com/uselagoon/build-deploy-tool/internal/generator"
+)
+
+var deployDBaaSVariables = &cobra.Command{
+	Use:     "dbaas-variables",
+	Aliases: []string{"dbaas", "db"},
+	Short:   "Wait for the dbaas consumers of a Lagoon build and add their variables to the lagoon-env configmap",
+	Long: `Wait for the dbaas consumers of a Lagoon build and add their variables to the lagoon-env configmap
+Each consumer is waited on until the dbaas-operator has provisioned it, then the variables for its dbaas type are read from
+the consumer and added to the lagoon-env configmap, prefixed with the uppercased service name (eg MARIADB_HOST).
+If --service is set, only the consumer for that service is waited on.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return fmt.Errorf("error reading timeout flag: %v", err)
+		}
+		service, err := cmd.Flags().GetString("service")
+		if err != nil {
+			return fmt.Errorf("error reading service flag: %v", err)
+		}
+		gen, err := generator.GenerateInput(*rootCmd, false)
+		if err != nil {
+			return err
+		}
+		namespace, err := deployCmd.PersistentFlags().GetString("namespace")
+		if err != nil {
+			return fmt.Errorf("error reading namespace flag: %v", err)
+		}
+		client, err := newDeployClient(namespace, false)
+		if err != nil {
+			return err
+		}
+		return DeployDBaaSVariables(gen, client, service, deploy.DBaaSOptions{
+			Timeout:  timeout,
+			Interval: 5 * time.Second,
+		})
+	},
+}
+
+// DeployDBaaSVariables waits for the dbaas consumers the build generates, and adds their variables to the lagoon-env configmap
+func DeployDBaaSVariables(g generator.GeneratorInput, client *deploy.Client, service string, opts deploy.DBaaSOptions) error {
+	lagoonBuild, err := newGenerator(
+		g,
+	)
+	if err != nil {
+		return err
+	}
+	result := &deployDBaaSVariablesResult{Services: []dbaasServiceVariables{}}
+	setResult(result)
+	found := false
+	for _, svc := range lagoonBuild.BuildValues.Services {
+		if !svc.IsDBaaS || (service != "" && svc.Name != service) {
+			continue
+		}
+		found = true
+		dbaasType, ok := lagoonBuild.BuildValues.DBaaSTypes[strings.TrimSuffix(svc.Type, "-dbaas")]
+		if !ok {
+			return fmt.Errorf("dbaas type %s of service %s is not known", svc.Type, svc.Name)
+		}
+		fmt.Printf("Waiting for the %s of %s\n", dbaasType.ConsumerKind, svc.Name)
+		variables, err := client.DBaaSVariables(context.TODO(), svc.Name, dbaasType, opts)
+		if err != nil {
+			return err
+		}
+		if err := client.PatchEnvironmentVariables(context.TODO(), variables); err != nil {
+			return err
+		}
+		fmt.Printf("Added %d variables for %s to lagoon-env\n", len(variables), svc.Name)
+		names := []string{}
+		for name := range variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		result.Services = append(result.Services, dbaasServiceVariables{Service: svc.Name, Variables: names})
+	}
+	if service != "" && !found {
+		return fmt.Errorf("service %s is not a dbaas service", service)
+	}
+	return nil
+}
+
+// deployDBaaSVariablesResult is the result of the deploy dbaas-variables command
+type deployDBaaSVariablesResult struct {
+	Services []dbaasServiceVariables `json:"services"`
+}
+
+// dbaasServiceVariables are the names of the variables added to the lagoon-env configmap for a dbaas service,
+// the values are credentials so they are never part of the result
+type dbaasServiceVariables struct {
+	Service   string   `json:"service"`
+	Variables []string `json:"variables"`
+}
+
+func init() {
+	deployCmd.AddCommand(deployDBaaSVariables)
+	deployDBaaSVariables.Flags().DurationP("timeout", "", 300*time.Second,
+		"How long to wait for each consumer to be provisioned")
+	deployDBaaSVariables.Flags().StringP("service", "", "",
+		"Only wait for the consumer of this service")
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uselagoon/build-deploy-tool/internal/deploy"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+var deployWait = &cobra.Command{
+	Use:     "wait",
+	Aliases: []string{"w"},
+	Short:   "Wait for the rollout of all the deployments of a Lagoon build",
+	Long: `Wait for the rollout of all the deployments of a Lagoon build
+The deployments are read from the templates within the provided paths (or the saved-templates-path if none are provided),
+the same templates that deploy apply applies. All the deployments are watched at the same time. If any rollout fails, the pods
+of the new replicaset are inspected and a summary of why each service failed is printed along with the events and logs of the failing containers.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths, err := cmd.Flags().GetStringSlice("path")
+		if err != nil {
+			return fmt.Errorf("error reading path flag: %v", err)
+		}
+		if len(paths) == 0 {
+			savedTemplates, err := rootCmd.PersistentFlags().GetString("saved-templates-path")
+			if err != nil {
+				return fmt.Errorf("error reading saved-templates-path flag: %v", err)
+			}
+			paths = []string{savedTemplates}
+		}
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return fmt.Errorf("error reading timeout flag: %v", err)
+		}
+		logLines, err := cmd.Flags().GetInt64("log-lines")
+		if err != nil {
+			return fmt.Errorf("error reading log-lines flag: %v", err)
+		}
+		namespace, err := deployCmd.PersistentFlags().GetString("namespace")
+		if err != nil {
+			return fmt.Errorf("error reading namespace flag: %v", err)
+		}
+		client, err := newDeployClient(namespace, false)
+		if err != nil {
+			return err
+		}
+		return DeployWait(paths, client, deploy.RolloutOptions{
+			Timeout:  timeout,
+			Interval: 5 * time.Second,
+			LogLines: logLines,
+		})
+	},
+}
+
+// DeployWait waits for the rollout of every deployment in the templates within the provided paths
+func DeployWait(paths []string, client *deploy.Client, opts deploy.RolloutOptions) error {
+	objects, err := deploy.ReadManifests(paths...)
+	if err != nil {
+		return err
+	}
+	names := []string{}
+	for _, obj := range objects {
+		if obj.GroupVersionKind() == appsv1.SchemeGroupVersion.WithKind("Deployment") {
+			names = append(names, obj.GetName())
+		}
+	}
+	if len(names) == 0 {
+		fmt.Println("No deployments to wait for")
+		setResult(deployWaitResult{Rollouts: []deploy.RolloutStatus{}})
+		return nil
+	}
+	fmt.Printf("Waiting for the rollout of %s\n", strings.Join(names, ", "))
+	results := client.WaitForDeployments(context.TODO(), names, opts)
+	setResult(deployWaitResult{Rollouts: results})
+	failed := []string{}
+	for _, result := range results {
+		if result.Ready {
+			fmt.Printf("Rollout for %s complete\n", result.Service)
+			continue
+		}
+		failed = append(failed, result.Service)
+		printRolloutFailure(result)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("rollout failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// deployWaitResult is the result of the deploy wait command
+type deployWaitResult struct {
+	Rollouts []deploy.RolloutStatus `json:"rollouts"`
+}
+
+func printRolloutFailure(result deploy.RolloutStatus) {
+	fmt.Println("##############################################")
+	fmt.Printf("Rollout for %s failed: %s\n", result.Service, result.Reason)
+	if result.Message != "" {
+		fmt.Printf("  %s\n", result.Message)
+	}
+	if len(result.Pods) == 0 {
+		fmt.Println("  Tried to gather information about the pods of the new replicaset, but unfortunately there were none with any issues, sorry.")
+	}
+	for _, pod := range result.Pods {
+		if pod.Container != "" {
+			fmt.Printf("  Pod %s container %s: %s %s\n", pod.Name, pod.Container, pod.Reason, pod.Message)
+		} else {
+			fmt.Printf("  Pod %s: %s %s\n", pod.Name, pod.Reason, pod.Message)
+		}
+		if len(pod.Events) > 0 {
+			fmt.Println("  Events:")
+			for _, event := range pod.Events {
+				fmt.Printf("    %s\n", event)
+			}
+		}
+		if pod.Logs != "" {
+			fmt.Printf("  Logs (%s):\n", pod.Container)
+			for _, line := range strings.Split(strings.TrimRight(pod.Logs, "\n"), "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+	fmt.Println("##############################################")
+}
+
+func init() {
+	deployCmd.AddCommand(deployWait)
+	deployWait.Flags().StringSliceP("path", "", []string{},
+		"Path to a directory or file of templates to read the deployments from, can be provided multiple times (defaults to the saved-templates-path)")
+	// default progressDeadlineSeconds is 600, doubling that here as a fallback for exceeding the progressdeadline
+	deployWait.Flags().DurationP("timeout", "", 1200*time.Second,
+		"How long to wait for all the deployments to roll out")
+	deployWait.Flags().Int64P("log-lines", "", 100,
+		"How many lines of logs to show from each failing container")
+}
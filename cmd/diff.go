@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/uselagoon/build-deploy-tool/internal/deploy"
+	generator "github.com/uselagoon/build-deploy-tool/internal/generator"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show what a build would change in the environment namespace",
+	Long: `Show what a build would change in the environment namespace
+All the templates for the build are generated and compared with what is currently in the namespace.
+Objects that would be added, changed or removed are shown, along with any fields that would change.
+The cluster is accessed using the KUBECONFIG if set, otherwise the deployer token from within the cluster.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			return fmt.Errorf("error reading namespace flag: %v", err)
+		}
+		includeVolumes, err := cmd.Flags().GetBool("include-volumes")
+		if err != nil {
+			return fmt.Errorf("error reading include-volumes flag: %v", err)
+		}
+		k8upVersion, err := cmd.Flags().GetString("version")
+		if err != nil {
+			return fmt.Errorf("error reading version flag: %v", err)
+		}
+		gen, err := generator.GenerateInput(*rootCmd, false)
+		if err != nil {
+			return err
+		}
+		gen.BackupConfiguration.K8upVersion = k8upVersion
+		images, err := rootCmd.PersistentFlags().GetString("images")
+		if err != nil {
+			return fmt.Errorf("error reading images flag: %v", err)
+		}
+		imageRefs, err := loadImagesFromFile(images)
+		if err != nil {
+			return err
+		}
+		gen.ImageReferences = imageRefs.Images
+		client, err := newDeployClient(namespace, false)
+		if err != nil {
+			return err
+		}
+		diffs, err := DiffGeneration(gen, client, includeVolumes)
+		if err != nil {
+			return err
+		}
+		setResult(diffResult{Changes: append([]deploy.ObjectDiff{}, diffs...)})
+		printDiffs(diffs)
+		return nil
+	},
+}
+
+// diffResult is the result of the diff command
+type diffResult struct {
+	Changes []deploy.ObjectDiff `json:"changes"`
+}
+
+// DiffGeneration generates all the templates for the build and compares them with what is in the namespace
+func DiffGeneration(g generator.GeneratorInput, client *deploy.Client, includeVolumes bool) ([]deploy.ObjectDiff, error) {
+	tmpDir, err := os.MkdirTemp("", "build-deploy-tool-diff")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	err = AllTemplateGeneration(g, AllTemplatesPaths{
+		Services:      filepath.Join(tmpDir, "service-deployments"),
+		Jobs:          filepath.Join(tmpDir, "jobs"),
+		Routes:        filepath.Join(tmpDir, "routes"),
+		AutogenRoutes: filepath.Join(tmpDir, "autogen-routes"),
+		DBaaS:         filepath.Join(tmpDir, "dbaas"),
+		Backups:       filepath.Join(tmpDir, "backup"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	objects, err := deploy.ReadManifests(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	return client.Diff(context.TODO(), objects, includeVolumes)
+}
+
+func printDiffs(diffs []deploy.ObjectDiff) {
+	if len(diffs) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+	for _, d := range diffs {
+		switch d.Action {
+		case deploy.DiffAdded:
+			fmt.Printf("+ %s/%s\n", d.Kind, d.Name)
+		case deploy.DiffRemoved:
+			fmt.Printf("- %s/%s\n", d.Kind, d.Name)
+		case deploy.DiffChanged:
+			fmt.Printf("~ %s/%s\n", d.Kind, d.Name)
+			for _, f := range d.Fields {
+				fmt.Printf("    %s: %s -> %s\n", f.Path, f.Live, f.New)
+			}
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringP("namespace", "n", "",
+		"The namespace of the environment, defaults to the namespace of the service account if running in a cluster")
+	diffCmd.Flags().BoolP("include-volumes", "", false,
+		"Also show persistent volume claims that would be removed by `deploy apply --prune-volumes`")
+	diffCmd.Flags().StringP("version", "", "v1", "The version of k8up used.")
+}
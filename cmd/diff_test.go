@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/uselagoon/build-deploy-tool/internal/dbaasclient"
+	"github.com/uselagoon/build-deploy-tool/internal/deploy"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+	"github.com/uselagoon/build-deploy-tool/internal/testdata"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	// changes the testing to source from root so paths to test resources must be defined from repo root
+	_ "github.com/uselagoon/build-deploy-tool/internal/testing"
+)
+
+func TestDiffGeneration(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     testdata.TestData
+		existing []runtime.Object
+		want     []deploy.ObjectDiff
+	}{
+		{
+			name: "test1 new environment with a removed service",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.yml",
+					ImageReferences: map[string]string{
+						"node": "harbor.example/example-project/main/node@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+					},
+				}, true),
+			existing: []runtime.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "redis",
+						Namespace: "example-project-main",
+						Labels: map[string]string{
+							"app.kubernetes.io/managed-by": "build-deploy-tool",
+							"lagoon.sh/environment":        "main",
+						},
+					},
+				},
+			},
+			want: []deploy.ObjectDiff{
+				{Kind: "Service", Name: "node", Action: deploy.DiffAdded},
+				{Kind: "Deployment", Name: "node", Action: deploy.DiffAdded},
+				{Kind: "Ingress", Name: "node", Action: deploy.DiffAdded},
+				{Kind: "Ingress", Name: "example.com", Action: deploy.DiffAdded},
+				{Kind: "Deployment", Name: "redis", Action: deploy.DiffRemoved},
+			},
+		},
+		{
+			name: "test2 new environment with a job",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/complex/lagoon.jobs.yml",
+					ImageReferences: map[string]string{
+						"nginx":         "harbor.example/example-project/main/nginx@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"php":           "harbor.example/example-project/main/php@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"cli":           "harbor.example/example-project/main/cli@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"migrate":       "harbor.example/example-project/main/migrate@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"wait-for-solr": "harbor.example/example-project/main/wait-for-solr@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+					},
+				}, true),
+			want: []deploy.ObjectDiff{
+				{Kind: "PersistentVolumeClaim", Name: "nginx-php", Action: deploy.DiffAdded},
+				{Kind: "Service", Name: "nginx-php", Action: deploy.DiffAdded},
+				{Kind: "Job", Name: "migrate", Action: deploy.DiffAdded},
+				{Kind: "Deployment", Name: "cli", Action: deploy.DiffAdded},
+				{Kind: "Deployment", Name: "nginx-php", Action: deploy.DiffAdded},
+				{Kind: "Ingress", Name: "nginx-php", Action: deploy.DiffAdded},
+				{Kind: "Ingress", Name: "example.com", Action: deploy.DiffAdded},
+				{Kind: "Schedule", Name: "k8up-lagoon-backup-schedule", Action: deploy.DiffAdded},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helpers.UnsetEnvVars(nil)
+			generator, err := testdata.SetupEnvironment(*rootCmd, "testoutput", tt.args)
+			if err != nil {
+				t.Errorf("%v", err)
+			}
+			ts := dbaasclient.TestDBaaSHTTPServer()
+			defer ts.Close()
+			err = os.Setenv("DBAAS_OPERATOR_HTTP", ts.URL)
+			if err != nil {
+				t.Errorf("%v", err)
+			}
+			client := &deploy.Client{
+				Kubernetes:  fake.NewClientset(tt.existing...),
+				Dynamic:     dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), deploy.CustomResourceListKinds(servicetypes.DBaaSTypes)),
+				Namespace:   "example-project-main",
+				Environment: "main",
+			}
+			got, err := DiffGeneration(generator, client, false)
+			if err != nil {
+				t.Errorf("%v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffGeneration() = %+v, want %+v", got, tt.want)
+			}
+			t.Cleanup(func() {
+				helpers.UnsetEnvVars(tt.args.BuildPodVariables)
+			})
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+)
+
+var generateSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Generate JSON Schemas",
+	Long:  `Generate JSON Schemas for the files that Lagoon builds read`,
+}
+
+var generateSchemaLagoonYml = &cobra.Command{
+	Use:   "lagoon-yml",
+	Short: "Generate the JSON Schema for the .lagoon.yml file",
+	Long: `Generate the JSON Schema for the .lagoon.yml file
+The schema is generated from the types this tool uses to read the .lagoon.yml file, and can be used by editors
+for autocompletion, or to validate a .lagoon.yml file before committing it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := LagoonYmlSchemaGeneration()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(schema))
+		return nil
+	},
+}
+
+// LagoonYmlSchemaGeneration returns the JSON Schema for the .lagoon.yml file
+func LagoonYmlSchemaGeneration() ([]byte, error) {
+	schema, err := json.MarshalIndent(lagoon.GenerateYAMLSchema(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate schema: %v", err)
+	}
+	return schema, nil
+}
+
+func init() {
+	generateCmd.AddCommand(generateSchemaCmd)
+	generateSchemaCmd.AddCommand(generateSchemaLagoonYml)
+}
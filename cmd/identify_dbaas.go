@@ -25,12 +25,13 @@ var dbaasIdentify = &cobra.Command{
 		for _, dbc := range dbaasConsumers {
 			fmt.Println(dbc)
 		}
+		setResult(dbaasConsumers)
 		return nil
 	},
 }
 
 func IdentifyDBaaSConsumers(g generator.GeneratorInput) ([]string, error) {
-	lagoonBuild, err := generator.NewGenerator(
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
@@ -61,6 +61,7 @@ func TestIdentifyDBaaSConsumers(t *testing.T) {
 					Branch:          "main",
 					LagoonYAML:      "internal/testdata/complex/lagoon.yml",
 					ProjectVariables: []lagoon.EnvironmentVariable{
+						{Name: "LAGOON_FEATURE_FLAG_DBAAS_FALLBACK_SINGLE", Value: "enabled", Scope: "build"},
 						{Name: "LAGOON_DBAAS_ENVIRONMENT_TYPES", Value: "mariadb:development2", Scope: "build"},
 					},
 				}, true),
@@ -101,6 +102,21 @@ func TestIdentifyDBaaSConsumers(t *testing.T) {
 				"mariadb2:mariadb-dbaas",
 			},
 		},
+		{
+			name: "test6 - override provider to non-existent in production with fallback disabled should fail",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/complex/lagoon.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{Name: "LAGOON_DBAAS_ENVIRONMENT_TYPES", Value: "mariadb:development2", Scope: "build"},
+					},
+				}, true),
+			templatePath: "testdata/output",
+			wantErr:      true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
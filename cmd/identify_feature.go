@@ -23,13 +23,14 @@ var featureFlagIdentify = &cobra.Command{
 			return err
 		}
 		fmt.Println(flagValue)
+		setResult(flagValue)
 		return nil
 	},
 }
 
 // IdentifyFeatureFlag checks if a feature flag of given name has been set or not in a build
 func IdentifyFeatureFlag(g generator.GeneratorInput, name string) (string, error) {
-	lagoonBuild, err := generator.NewGenerator(
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
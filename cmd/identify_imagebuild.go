@@ -26,6 +26,7 @@ var imageBuildIdentify = &cobra.Command{
 			return err
 		}
 		fmt.Println(string(bc))
+		setResult(out)
 		return nil
 	},
 }
@@ -54,7 +55,7 @@ type imageBuilds struct {
 func ImageBuildConfigurationIdentification(g generator.GeneratorInput) (imageBuild, error) {
 
 	lServices := imageBuild{}
-	lagoonBuild, err := generator.NewGenerator(
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
@@ -9,9 +9,10 @@ import (
 )
 
 type ingressIdentifyJSON struct {
-	Primary       string   `json:"primary"`
-	Secondary     []string `json:"secondary"`
-	Autogenerated []string `json:"autogenerated"`
+	Primary        string   `json:"primary"`
+	Secondary      []string `json:"secondary"`
+	Autogenerated  []string `json:"autogenerated"`
+	CanaryServices []string `json:"canaryServices,omitempty"`
 }
 
 var primaryIngressIdentify = &cobra.Command{
@@ -28,6 +29,7 @@ var primaryIngressIdentify = &cobra.Command{
 			return err
 		}
 		fmt.Println(primary)
+		setResult(primary)
 		return nil
 	},
 }
@@ -52,13 +54,14 @@ var ingressIdentify = &cobra.Command{
 		}
 		retJSON, _ := json.Marshal(ret)
 		fmt.Println(string(retJSON))
+		setResult(ret)
 		return nil
 	},
 }
 
 // IdentifyPrimaryIngress .
 func IdentifyPrimaryIngress(g generator.GeneratorInput) (string, []string, []string, error) {
-	lagoonBuild, err := generator.NewGenerator(
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
@@ -77,47 +80,135 @@ var autogenIngressIdentify = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		autogen, secondary, err := CreatedIngressIdentification(generator)
+		autogen, secondary, canaryServices, err := CreatedIngressIdentification(generator)
 		if err != nil {
 			return err
 		}
 		ret := ingressIdentifyJSON{
-			Autogenerated: autogen,
-			Secondary:     secondary,
+			Autogenerated:  autogen,
+			Secondary:      secondary,
+			CanaryServices: canaryServices,
 		}
 		retJSON, _ := json.Marshal(ret)
 		fmt.Println(string(retJSON))
+		setResult(ret)
 		return nil
 	},
 }
 
-// CreatedIngressIdentification handles identifying autogenerated ingress
-func CreatedIngressIdentification(g generator.GeneratorInput) ([]string, []string, error) {
-	lagoonBuild, err := generator.NewGenerator(
+// CreatedIngressIdentification handles identifying autogenerated ingress, and the services of canaries in other environments
+func CreatedIngressIdentification(g generator.GeneratorInput) ([]string, []string, []string, error) {
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	autogenIngress := []string{}
 	// generate the templates
 	for _, route := range lagoonBuild.AutogeneratedRoutes.Routes {
+		// routes attached to a gateway are identified as httproutes instead
+		if generator.RouteGateway(*lagoonBuild.BuildValues, route) != nil {
+			continue
+		}
 		autogenIngress = append(autogenIngress, route.LagoonService)
 	}
 
 	secondary := []string{}
+	canaryServices := []string{}
 	// generate the templates
 	for _, route := range lagoonBuild.MainRoutes.Routes {
-		secondary = append(secondary, route.IngressName)
+		names, canaryService, err := routeIngressNames(route, *lagoonBuild.BuildValues)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		secondary = append(secondary, names...)
+		if canaryService != "" {
+			canaryServices = append(canaryServices, canaryService)
+		}
+	}
+	for _, route := range lagoonBuild.ActiveStandbyRoutes.Routes {
+		names, canaryService, err := routeIngressNames(route, *lagoonBuild.BuildValues)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		secondary = append(secondary, names...)
+		if canaryService != "" {
+			canaryServices = append(canaryServices, canaryService)
+		}
+	}
+	return autogenIngress, secondary, canaryServices, nil
+}
+
+type httpRouteIdentifyJSON struct {
+	Secondary     []string `json:"secondary"`
+	Autogenerated []string `json:"autogenerated"`
+	Certificates  []string `json:"certificates"`
+}
+
+var createdHTTPRouteIdentify = &cobra.Command{
+	Use:     "created-httproute",
+	Aliases: []string{"ch"},
+	Short:   "Identify all created httproute and certificate object names for a specific environment",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		generator, err := generator.GenerateInput(*rootCmd, false)
+		if err != nil {
+			return err
+		}
+		ret, err := CreatedHTTPRouteIdentification(generator)
+		if err != nil {
+			return err
+		}
+		retJSON, _ := json.Marshal(ret)
+		fmt.Println(string(retJSON))
+		setResult(ret)
+		return nil
+	},
+}
+
+// CreatedHTTPRouteIdentification handles identifying the httproutes and certificates of the routes that are attached to a gateway
+func CreatedHTTPRouteIdentification(g generator.GeneratorInput) (httpRouteIdentifyJSON, error) {
+	ret := httpRouteIdentifyJSON{
+		Secondary:     []string{},
+		Autogenerated: []string{},
+		Certificates:  []string{},
+	}
+	lagoonBuild, err := newGenerator(
+		g,
+	)
+	if err != nil {
+		return ret, err
+	}
+	for _, route := range lagoonBuild.AutogeneratedRoutes.Routes {
+		names, certificates, err := routeHTTPRouteNames(route, *lagoonBuild.BuildValues)
+		if err != nil {
+			return ret, err
+		}
+		ret.Autogenerated = append(ret.Autogenerated, names...)
+		ret.Certificates = append(ret.Certificates, certificates...)
+	}
+	for _, route := range lagoonBuild.MainRoutes.Routes {
+		names, certificates, err := routeHTTPRouteNames(route, *lagoonBuild.BuildValues)
+		if err != nil {
+			return ret, err
+		}
+		ret.Secondary = append(ret.Secondary, names...)
+		ret.Certificates = append(ret.Certificates, certificates...)
 	}
 	for _, route := range lagoonBuild.ActiveStandbyRoutes.Routes {
-		secondary = append(secondary, route.IngressName)
+		names, certificates, err := routeHTTPRouteNames(route, *lagoonBuild.BuildValues)
+		if err != nil {
+			return ret, err
+		}
+		ret.Secondary = append(ret.Secondary, names...)
+		ret.Certificates = append(ret.Certificates, certificates...)
 	}
-	return autogenIngress, secondary, nil
+	return ret, nil
 }
 
 func init() {
+	identifyCmd.AddCommand(createdHTTPRouteIdentify)
 	identifyCmd.AddCommand(primaryIngressIdentify)
 	identifyCmd.AddCommand(ingressIdentify)
 	identifyCmd.AddCommand(dbaasIdentify)
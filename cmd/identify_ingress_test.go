@@ -339,6 +339,9 @@ func TestIdentifyRoute(t *testing.T) {
 					EnvironmentName: "main",
 					Branch:          "main",
 					LagoonYAML:      "internal/testdata/complex/lagoon.complex-2.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{Name: "LAGOON_FEATURE_FLAG_DBAAS_FALLBACK_SINGLE", Value: "enabled", Scope: "build"},
+					},
 				}, true),
 			templatePath: "testoutput",
 			want:         "https://wild.example.com",
@@ -491,12 +494,74 @@ func TestCreatedIngressIdentification(t *testing.T) {
 					EnvironmentName: "main",
 					Branch:          "main",
 					LagoonYAML:      "internal/testdata/complex/lagoon.complex-2.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{Name: "LAGOON_FEATURE_FLAG_DBAAS_FALLBACK_SINGLE", Value: "enabled", Scope: "build"},
+					},
 				}, true),
 			templatePath: "testoutput",
 			wantRemain:   []string{"wildcard-wild.example.com", "alt.example.com"},
 			wantautoGen:  []string{"nginx"},
 			wantJSON:     `{"primary":"","secondary":["wildcard-wild.example.com","alt.example.com"],"autogenerated":["nginx"]}`,
 		},
+		{
+			name: "test19 routes with redirected and rewritten paths",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.redirects.yml",
+				}, true),
+			templatePath: "testoutput",
+			wantRemain:   []string{"a.example.com", "a.example.com-path-a5b7cb63", "a.example.com-path-f6de07ca", "a.example.com-path-49954000", "b.example.com"},
+			wantautoGen:  []string{"nginx", "node"},
+			wantJSON:     `{"primary":"","secondary":["a.example.com","a.example.com-path-a5b7cb63","a.example.com-path-f6de07ca","a.example.com-path-49954000","b.example.com"],"autogenerated":["nginx","node"]}`,
+		},
+		{
+			name: "test20 routes with canaries",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.canary.yml",
+				}, true),
+			templatePath: "testoutput",
+			wantRemain:   []string{"a.example.com", "a.example.com-canary", "b.example.com", "b.example.com-canary"},
+			wantautoGen:  []string{"nginx", "node"},
+			wantJSON:     `{"primary":"","secondary":["a.example.com","a.example.com-canary","b.example.com","b.example.com-canary"],"autogenerated":["nginx","node"],"canaryServices":["canary-0a8426cb"]}`,
+		},
+		{
+			name: "test21 routes attached to a gateway",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.pathroutes.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{
+							Name:  "LAGOON_FEATURE_FLAG_ROUTE_BACKEND",
+							Value: "gateway",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_FEATURE_FLAG_GATEWAY_NAME",
+							Value: "lagoon-gateway/public",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_FEATURE_FLAG_GATEWAY_CERTIFICATE_ISSUER",
+							Value: "ClusterIssuer/lagoon-acme",
+							Scope: "build",
+						},
+					},
+				}, true),
+			templatePath: "testoutput",
+			wantRemain:   []string{},
+			wantautoGen:  []string{},
+			wantJSON:     `{"primary":"","secondary":[],"autogenerated":[]}`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -507,7 +572,7 @@ func TestCreatedIngressIdentification(t *testing.T) {
 				t.Errorf("%v", err)
 			}
 
-			autogen, remainders, err := CreatedIngressIdentification(generator)
+			autogen, remainders, canaryServices, err := CreatedIngressIdentification(generator)
 			if err != nil {
 				t.Errorf("%v", err)
 			}
@@ -521,8 +586,9 @@ func TestCreatedIngressIdentification(t *testing.T) {
 			}
 
 			ret := ingressIdentifyJSON{
-				Autogenerated: autogen,
-				Secondary:     remainders,
+				Autogenerated:  autogen,
+				Secondary:      remainders,
+				CanaryServices: canaryServices,
 			}
 			retJSON, _ := json.Marshal(ret)
 
@@ -536,3 +602,78 @@ func TestCreatedIngressIdentification(t *testing.T) {
 		})
 	}
 }
+
+func TestCreatedHTTPRouteIdentification(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         testdata.TestData
+		templatePath string
+		wantJSON     string
+	}{
+		{
+			name: "test1 routes rendered as ingress",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.pathroutes.yml",
+				}, true),
+			templatePath: "testoutput",
+			wantJSON:     `{"secondary":[],"autogenerated":[],"certificates":[]}`,
+		},
+		{
+			name: "test2 routes attached to a gateway",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.pathroutes.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{
+							Name:  "LAGOON_FEATURE_FLAG_ROUTE_BACKEND",
+							Value: "gateway",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_FEATURE_FLAG_GATEWAY_NAME",
+							Value: "lagoon-gateway/public",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_FEATURE_FLAG_GATEWAY_CERTIFICATE_ISSUER",
+							Value: "ClusterIssuer/lagoon-acme",
+							Scope: "build",
+						},
+					},
+				}, true),
+			templatePath: "testoutput",
+			wantJSON:     `{"secondary":["a.example.com","a.example.com-redirect"],"autogenerated":["nginx","node"],"certificates":["nginx-tls","node-tls","a.example.com-tls"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// set the environment variables from args
+			savedTemplates := tt.templatePath
+			generator, err := testdata.SetupEnvironment(*rootCmd, savedTemplates, tt.args)
+			if err != nil {
+				t.Errorf("%v", err)
+			}
+
+			ret, err := CreatedHTTPRouteIdentification(generator)
+			if err != nil {
+				t.Errorf("%v", err)
+			}
+			retJSON, _ := json.Marshal(ret)
+
+			if string(retJSON) != tt.wantJSON {
+				t.Errorf("returned %v doesn't match want %v", string(retJSON), tt.wantJSON)
+			}
+			t.Cleanup(func() {
+				helpers.UnsetEnvVars(nil)
+				helpers.UnsetEnvVars(tt.args.BuildPodVariables)
+			})
+		})
+	}
+}
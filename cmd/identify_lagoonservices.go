@@ -54,6 +54,7 @@ var lagoonServiceIdentify = &cobra.Command{
 			return err
 		}
 		fmt.Println(out)
+		setResult(out)
 		return nil
 	},
 }
@@ -65,7 +66,7 @@ var lagoonServiceIdentify = &cobra.Command{
 func LagoonServiceTemplateIdentification(g generator.GeneratorInput) ([]identifyServices, error) {
 
 	lServices := []identifyServices{}
-	lagoonBuild, err := generator.NewGenerator(
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
@@ -22,13 +22,14 @@ var nativeCronjobsIdentify = &cobra.Command{
 			return err
 		}
 		fmt.Println(cronjobs)
+		setResult(json.RawMessage(cronjobs))
 		return nil
 	},
 }
 
 // IdentifyNativeCronjobs .
 func IdentifyNativeCronjobs(g generator.GeneratorInput) (string, error) {
-	lagoonBuild, err := generator.NewGenerator(
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/output"
+)
+
+// commandOutput holds what is needed to write the result envelope of the command being run
+var commandOutput struct {
+	format    string
+	stdout    *os.File
+	start     time.Time
+	result    interface{}
+	templates []string
+	warnings  []generator.Warning
+}
+
+// templatesResult is the result of the template commands
+type templatesResult struct {
+	Templates []string `json:"templates"`
+}
+
+// setupOutput runs before every command. If the output is json or yaml, anything a command prints is sent to stderr
+// so that only the result envelope is written to stdout.
+func setupOutput(cmd *cobra.Command, args []string) error {
+	format, err := rootCmd.PersistentFlags().GetString("output")
+	if err != nil {
+		return err
+	}
+	if err := output.ValidateFormat(format); err != nil {
+		return err
+	}
+	commandOutput.format = format
+	commandOutput.start = time.Now()
+	commandOutput.result = nil
+	commandOutput.templates = nil
+	commandOutput.warnings = nil
+	if format != output.FormatText && commandOutput.stdout == nil {
+		commandOutput.stdout = os.Stdout
+		os.Stdout = os.Stderr
+	}
+	return nil
+}
+
+// setResult sets the result of the command that is written in the result envelope
+func setResult(result interface{}) {
+	commandOutput.result = result
+}
+
+// newGenerator runs the generator and records any warnings it raises. In text output the warnings are printed to stderr,
+// otherwise they are added to the result envelope.
+func newGenerator(g generator.GeneratorInput) (*generator.Generator, error) {
+	lagoonBuild, err := generator.NewGenerator(g)
+	warnings := []generator.Warning{}
+	var warningsErr *generator.WarningsError
+	if errors.As(err, &warningsErr) {
+		warnings = warningsErr.Warnings
+	} else if err == nil {
+		warnings = lagoonBuild.Warnings
+	}
+	for _, w := range warnings {
+		if commandOutput.stdout == nil {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+	}
+	commandOutput.warnings = append(commandOutput.warnings, warnings...)
+	return lagoonBuild, err
+}
+
+// writeTemplateFile writes the template file, and records it for the result of the template commands
+func writeTemplateFile(file string, data []byte) {
+	helpers.WriteTemplateFile(file, data)
+	commandOutput.templates = append(commandOutput.templates, file)
+}
+
+// writeOutput writes the result envelope of the command, it returns false if the output format is text
+// and the command has already written its own output
+func writeOutput(cmd *cobra.Command, cmdErr error) (bool, error) {
+	if commandOutput.stdout == nil {
+		return false, nil
+	}
+	end := time.Now()
+	envelope := output.Envelope{
+		Command: strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" "),
+		Success: cmdErr == nil,
+		Timings: output.Timings{
+			Start:           commandOutput.start,
+			End:             end,
+			DurationSeconds: end.Sub(commandOutput.start).Seconds(),
+		},
+		Result: commandOutput.result,
+	}
+	for _, w := range commandOutput.warnings {
+		envelope.Warnings = append(envelope.Warnings, output.Message{
+			Code:    w.Code,
+			Message: w.Message,
+			Service: w.Service,
+			Source:  w.Source,
+		})
+	}
+	if cmdErr != nil {
+		envelope.Errors = append(envelope.Errors, output.Message{Message: cmdErr.Error()})
+	}
+	return true, output.Write(commandOutput.stdout, commandOutput.format, envelope)
+}
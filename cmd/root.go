@@ -30,6 +30,9 @@ var rootCmd = &cobra.Command{
 	Long: `A tool to help with generating Lagoon resources for Lagoon builds
 This tool will read a .lagoon.yml file and also all the required environment variables from
 within a Lagoon build to help with generating the resources`,
+	// the error is printed by Execute, or written to the result envelope, without the usage
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 var templateCmd = &cobra.Command{
@@ -60,11 +63,33 @@ var validateCmd = &cobra.Command{
 	Long:    `Validate resources for Lagoon builds`,
 }
 
+var deployCmd = &cobra.Command{
+	Use:     "deploy",
+	Aliases: []string{"dep"},
+	Short:   "Deploy resources",
+	Long:    `Deploy the generated resources for Lagoon builds into the environment namespace`,
+}
+
+var generateCmd = &cobra.Command{
+	Use:     "generate",
+	Aliases: []string{"gen"},
+	Short:   "Generate supporting files",
+	Long:    `Generate supporting files for Lagoon builds, like schemas`,
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+	cmd, err := rootCmd.ExecuteC()
+	written, outErr := writeOutput(cmd, err)
+	if outErr != nil {
+		fmt.Fprintln(os.Stderr, outErr)
+		os.Exit(1)
+	}
+	if err != nil {
+		if !written {
+			fmt.Println(err)
+		}
 		os.Exit(1)
 	}
 }
@@ -92,6 +117,12 @@ func displayVersionInfo() {
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := setupOutput(cmd, args); err != nil {
+			return err
+		}
+		return setupServiceTypes(cmd, args)
+	}
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(templateCmd)
@@ -99,6 +130,8 @@ func init() {
 	rootCmd.AddCommand(taskCmd)
 	rootCmd.AddCommand(identifyCmd)
 	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(deployCmd)
+	rootCmd.AddCommand(generateCmd)
 
 	rootCmd.PersistentFlags().StringP("lagoon-yml", "l", ".lagoon.yml",
 		"The .lagoon.yml file to read")
@@ -153,6 +186,14 @@ func init() {
 		"Ignore missing env_file files (true by default, subject to change).")
 	rootCmd.PersistentFlags().StringP("images", "", "",
 		"JSON representation of service:image reference")
+	rootCmd.PersistentFlags().StringP("output", "o", "text",
+		"The output format, json or yaml wrap the result of the command in a versioned envelope with any warnings and errors (text, json, yaml)")
+	rootCmd.PersistentFlags().StringSlice("warnings-as-errors", []string{},
+		"The warning codes that should fail the command, or `all` to fail on any warning")
+	rootCmd.PersistentFlags().String("service-types-file", "",
+		"A yaml file of additional service types to add to the built in service types")
+	rootCmd.PersistentFlags().Bool("allow-service-type-overrides", false,
+		"Allow service types in the service types file to replace built in service types with the same name")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+)
+
+// setupServiceTypes runs before every command, it adds any service types the cluster admin defines in a file to the registry
+func setupServiceTypes(cmd *cobra.Command, args []string) error {
+	file, err := rootCmd.PersistentFlags().GetString("service-types-file")
+	if err != nil {
+		return err
+	}
+	allowOverrides, err := rootCmd.PersistentFlags().GetBool("allow-service-type-overrides")
+	if err != nil {
+		return err
+	}
+	file = helpers.GetEnv("SERVICE_TYPES_FILE", file, false)
+	allowOverrides = helpers.GetEnvBool("SERVICE_TYPES_ALLOW_OVERRIDES", allowOverrides, false)
+	return servicetypes.LoadServiceTypes(file, allowOverrides)
+}
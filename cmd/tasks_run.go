@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/uselagoon/build-deploy-tool/internal/generator"
@@ -63,14 +62,12 @@ var tasksPreRun = &cobra.Command{
 
 		taskIterator, err := iterateTaskGenerator(true, unidleThenRun, buildValues, "Pre-Rollout", true)
 		if err != nil {
-			fmt.Println("Pre-rollout Tasks Failed with the following error: ", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("Pre-rollout Tasks Failed with the following error: %v", err)
 		}
 
 		err = runTasks(taskIterator, buildValues.LagoonYAML.Tasks.Prerollout, lagoonConditionalEvaluationEnvironment)
 		if err != nil {
-			fmt.Println("Pre-rollout Tasks Failed with the following error: ", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("Pre-rollout Tasks Failed with the following error: %v", err)
 		}
 		fmt.Println("Pre-rollout Tasks Complete")
 		return nil
@@ -95,13 +92,11 @@ var tasksPostRun = &cobra.Command{
 
 		taskIterator, err := iterateTaskGenerator(false, runCleanTaskInEnvironment, buildValues, "Post-Rollout", true)
 		if err != nil {
-			fmt.Println("Pre-rollout Tasks Failed with the following error: ", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("Post-rollout Tasks Failed with the following error: %v", err)
 		}
 		err = runTasks(taskIterator, buildValues.LagoonYAML.Tasks.Postrollout, lagoonConditionalEvaluationEnvironment)
 		if err != nil {
-			fmt.Println("Post-rollout Tasks Failed with the following error: ", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("Post-rollout Tasks Failed with the following error: %v", err)
 		}
 		fmt.Println("Post-rollout Tasks Complete")
 		return nil
@@ -110,7 +105,7 @@ var tasksPostRun = &cobra.Command{
 
 func getEnvironmentInfo(g generator.GeneratorInput) (tasklib.TaskEnvironment, generator.BuildValues, error) {
 	// read the .lagoon.yml file
-	lagoonBuild, err := generator.NewGenerator(
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
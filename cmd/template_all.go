@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	generator "github.com/uselagoon/build-deploy-tool/internal/generator"
+)
+
+// AllTemplatesPaths is the directory layout that `template all` writes into
+type AllTemplatesPaths struct {
+	Services      string
+	Routes        string
+	AutogenRoutes string
+	DBaaS         string
+	Backups       string
+	Jobs          string
+}
+
+var allGeneration = &cobra.Command{
+	Use:     "all",
+	Aliases: []string{"a"},
+	Short:   "Generate all the templates for a Lagoon build in a single pass",
+	Long: `Generate all the templates for a Lagoon build in a single pass
+The generator is only run once, so the docker-compose file is only parsed once and the DBaaS operator is only queried once,
+every template is then written out using the same view of the environment.
+Relative template paths are created within the saved-templates-path.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		k8upVersion, err := cmd.Flags().GetString("version")
+		if err != nil {
+			return fmt.Errorf("error reading version flag: %v", err)
+		}
+		savedTemplates, err := rootCmd.PersistentFlags().GetString("saved-templates-path")
+		if err != nil {
+			return fmt.Errorf("error reading saved-templates-path flag: %v", err)
+		}
+		paths := AllTemplatesPaths{}
+		for flag, path := range map[string]*string{
+			"services-path":       &paths.Services,
+			"routes-path":         &paths.Routes,
+			"autogen-routes-path": &paths.AutogenRoutes,
+			"dbaas-path":          &paths.DBaaS,
+			"backups-path":        &paths.Backups,
+			"jobs-path":           &paths.Jobs,
+		} {
+			*path, err = cmd.Flags().GetString(flag)
+			if err != nil {
+				return fmt.Errorf("error reading %s flag: %v", flag, err)
+			}
+			if !filepath.IsAbs(*path) {
+				*path = filepath.Join(savedTemplates, *path)
+			}
+		}
+		gen, err := generator.GenerateInput(*rootCmd, true)
+		if err != nil {
+			return err
+		}
+		gen.BackupConfiguration.K8upVersion = k8upVersion
+		images, err := rootCmd.PersistentFlags().GetString("images")
+		if err != nil {
+			return fmt.Errorf("error reading images flag: %v", err)
+		}
+		imageRefs, err := loadImagesFromFile(images)
+		if err != nil {
+			return err
+		}
+		gen.ImageReferences = imageRefs.Images
+		if err := AllTemplateGeneration(gen, paths); err != nil {
+			return err
+		}
+		setResult(templatesResult{Templates: commandOutput.templates})
+		return nil
+	},
+}
+
+// AllTemplateGeneration runs the generator once and writes every template for the build into the provided paths
+func AllTemplateGeneration(g generator.GeneratorInput, paths AllTemplatesPaths) error {
+	lagoonBuild, err := newGenerator(
+		g,
+	)
+	if err != nil {
+		return err
+	}
+	for _, path := range []string{paths.Services, paths.Routes, paths.AutogenRoutes, paths.DBaaS, paths.Backups} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return fmt.Errorf("couldn't create directory %v: %v", path, err)
+		}
+	}
+	if err := writeLagoonServiceTemplates(lagoonBuild, paths.Services, paths.Jobs, g.Debug); err != nil {
+		return err
+	}
+	if err := writeAutogeneratedIngressTemplates(lagoonBuild, paths.AutogenRoutes, g.Debug); err != nil {
+		return err
+	}
+	if err := writeIngressTemplates(lagoonBuild, paths.Routes, g.Debug); err != nil {
+		return err
+	}
+	if err := writeDBaaSTemplates(lagoonBuild, paths.DBaaS, g.Debug); err != nil {
+		return err
+	}
+	// backups are written last, as the dbaas read replica check modifies the services in the build values
+	return writeBackupTemplates(lagoonBuild, paths.Backups)
+}
+
+func init() {
+	templateCmd.AddCommand(allGeneration)
+	allGeneration.Flags().StringP("version", "", "v1", "The version of k8up used.")
+	allGeneration.Flags().StringP("services-path", "", "service-deployments",
+		"Path to where the service templates are saved")
+	allGeneration.Flags().StringP("routes-path", "", "routes",
+		"Path to where the ingress templates are saved")
+	allGeneration.Flags().StringP("autogen-routes-path", "", "autogen-routes",
+		"Path to where the autogenerated ingress templates are saved")
+	allGeneration.Flags().StringP("dbaas-path", "", "dbaas",
+		"Path to where the dbaas consumer templates are saved")
+	allGeneration.Flags().StringP("backups-path", "", "backup",
+		"Path to where the backup schedule and prebackuppod templates are saved")
+	allGeneration.Flags().StringP("jobs-path", "", "jobs",
+		"Path to where the job templates are saved")
+}
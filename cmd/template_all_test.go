@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/uselagoon/build-deploy-tool/internal/dbaasclient"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/testdata"
+
+	// changes the testing to source from root so paths to test resources must be defined from repo root
+	_ "github.com/uselagoon/build-deploy-tool/internal/testing"
+)
+
+func TestAllTemplateGeneration(t *testing.T) {
+	tests := []struct {
+		name         string
+		description  string
+		args         testdata.TestData
+		templatePath string
+	}{
+		{
+			name:        "test1-complex",
+			description: "tests that all templates match the individual template commands for nginx-php, dbaas and backups",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/complex/lagoon.yml",
+					ImageReferences: map[string]string{
+						"cli":   "harbor.example/example-project/main/cli@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"nginx": "harbor.example/example-project/main/nginx@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"php":   "harbor.example/example-project/main/php@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"redis": "harbor.example/example-project/main/redis@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+					},
+				}, true),
+			templatePath: "testoutput",
+		},
+		{
+			name:        "test2-node",
+			description: "tests that all templates match the individual template commands for a node service with custom routes",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/node/lagoon.yml",
+					ImageReferences: map[string]string{
+						"node":       "harbor.example/example-project/main/node@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"opensearch": "harbor.example/example-project/main/opensearch@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+					},
+				}, true),
+			templatePath: "testoutput",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helpers.UnsetEnvVars(nil)
+			savedTemplates := tt.templatePath
+			generator, err := testdata.SetupEnvironment(*rootCmd, savedTemplates, tt.args)
+			if err != nil {
+				t.Errorf("%v", err)
+			}
+			defer os.RemoveAll(savedTemplates)
+
+			ts := dbaasclient.TestDBaaSHTTPServer()
+			defer ts.Close()
+			err = os.Setenv("DBAAS_OPERATOR_HTTP", ts.URL)
+			if err != nil {
+				t.Errorf("%v", err)
+			}
+
+			allPaths := AllTemplatesPaths{
+				Services:      filepath.Join(savedTemplates, "all", "service-deployments"),
+				Routes:        filepath.Join(savedTemplates, "all", "routes"),
+				AutogenRoutes: filepath.Join(savedTemplates, "all", "autogen-routes"),
+				DBaaS:         filepath.Join(savedTemplates, "all", "dbaas"),
+				Backups:       filepath.Join(savedTemplates, "all", "backup"),
+				Jobs:          filepath.Join(savedTemplates, "all", "jobs"),
+			}
+			if err := AllTemplateGeneration(generator, allPaths); err != nil {
+				t.Errorf("%v", err)
+			}
+
+			// generate the same templates using the individual template commands
+			single := map[string]func() error{
+				allPaths.Services: func() error {
+					return LagoonServiceTemplateGeneration(generator, filepath.Join(savedTemplates, "single", "jobs"))
+				},
+				allPaths.Routes:        func() error { return IngressTemplateGeneration(generator) },
+				allPaths.AutogenRoutes: func() error { return AutogeneratedIngressGeneration(generator) },
+				allPaths.DBaaS:         func() error { return DBaaSTemplateGeneration(generator) },
+				allPaths.Backups:       func() error { return BackupTemplateGeneration(generator) },
+			}
+			for allPath, gen := range single {
+				generator.SavedTemplatesPath = filepath.Join(savedTemplates, "single", filepath.Base(allPath))
+				if err := os.MkdirAll(generator.SavedTemplatesPath, 0755); err != nil {
+					t.Errorf("couldn't create directory %v: %v", generator.SavedTemplatesPath, err)
+				}
+				if err := gen(); err != nil {
+					t.Errorf("%v", err)
+				}
+				compareTemplateDirs(t, allPath, generator.SavedTemplatesPath)
+			}
+			t.Cleanup(func() {
+				helpers.UnsetEnvVars(tt.args.BuildPodVariables)
+			})
+		})
+	}
+}
+
+func compareTemplateDirs(t *testing.T, got, want string) {
+	files, err := os.ReadDir(got)
+	if err != nil {
+		t.Errorf("couldn't read directory %v: %v", got, err)
+	}
+	results, err := os.ReadDir(want)
+	if err != nil {
+		t.Errorf("couldn't read directory %v: %v", want, err)
+	}
+	if len(files) != len(results) {
+		t.Errorf("number of generated templates in %v doesn't match %v: %v/%v", got, want, len(files), len(results))
+	}
+	for _, f := range files {
+		f1, err := os.ReadFile(fmt.Sprintf("%s/%s", got, f.Name()))
+		if err != nil {
+			t.Errorf("couldn't read file %v: %v", got, err)
+		}
+		r1, err := os.ReadFile(fmt.Sprintf("%s/%s", want, f.Name()))
+		if err != nil {
+			t.Errorf("couldn't read file %v: %v", want, err)
+		}
+		if !reflect.DeepEqual(f1, r1) {
+			t.Errorf("AllTemplateGeneration() %s = \n%v", f.Name(), diff.LineDiff(string(r1), string(f1)))
+		}
+	}
+}
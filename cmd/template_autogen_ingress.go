@@ -5,8 +5,6 @@ import (
 
 	"github.com/spf13/cobra"
 	generator "github.com/uselagoon/build-deploy-tool/internal/generator"
-	"github.com/uselagoon/build-deploy-tool/internal/helpers"
-	servicestemplates "github.com/uselagoon/build-deploy-tool/internal/templating"
 )
 
 var autogenRouteGeneration = &cobra.Command{
@@ -18,34 +16,38 @@ var autogenRouteGeneration = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		return AutogeneratedIngressGeneration(generator)
+		if err := AutogeneratedIngressGeneration(generator); err != nil {
+			return err
+		}
+		setResult(templatesResult{Templates: commandOutput.templates})
+		return nil
 	},
 }
 
 // AutogeneratedIngressGeneration handles generating autogenerated ingress
 func AutogeneratedIngressGeneration(g generator.GeneratorInput) error {
-	lagoonBuild, err := generator.NewGenerator(
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
 		return err
 	}
-	savedTemplates := g.SavedTemplatesPath
+	return writeAutogeneratedIngressTemplates(lagoonBuild, g.SavedTemplatesPath, g.Debug)
+}
+
+// writeAutogeneratedIngressTemplates writes the autogenerated ingress templates for an already generated build to the saved templates path
+func writeAutogeneratedIngressTemplates(lagoonBuild *generator.Generator, savedTemplates string, debug bool) error {
 	// generate the templates
 	for _, route := range lagoonBuild.AutogeneratedRoutes.Routes {
 		// autogenerated routes use the `servicename` as the name of the ingress resource, use `IngressName` in routev2 to handle this
-		if g.Debug {
+		if debug {
 			fmt.Printf("Templating autogenerated ingress manifest for %s to %s\n", route.Domain, fmt.Sprintf("%s/%s.yaml", savedTemplates, route.LagoonService))
 		}
-		ingress, err := servicestemplates.GenerateIngressTemplate(route, *lagoonBuild.BuildValues)
+		templateYAML, err := templateRoute(route, *lagoonBuild.BuildValues)
 		if err != nil {
-			return fmt.Errorf("couldn't generate template: %v", err)
-		}
-		templateYAML, err := servicestemplates.TemplateIngress(ingress)
-		if err != nil {
-			return fmt.Errorf("couldn't generate template: %v", err)
+			return err
 		}
-		helpers.WriteTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, route.LagoonService), templateYAML)
+		writeTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, route.LagoonService), templateYAML)
 	}
 
 	return nil
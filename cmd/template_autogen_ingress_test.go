@@ -551,6 +551,32 @@ func TestAutogeneratedIngressGeneration(t *testing.T) {
 			templatePath: "testdata/output",
 			want:         "internal/testdata/basic/autogen-templates/test29-autogenerated-pathroutes",
 		},
+		{
+			name:        "test30-autogenerated-access",
+			description: "the autogenerated routes use the allowCIDRs from the .lagoon.yml and the default basic auth of the environment",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "develop",
+					Branch:          "develop",
+					EnvironmentType: "development",
+					LagoonYAML:      "internal/testdata/basic/lagoon.access.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{
+							Name:  "LAGOON_ROUTE_ALLOW_CIDRS",
+							Value: "192.0.2.0/24",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_ROUTE_BASIC_AUTH_SECRET",
+							Value: "basic-auth",
+							Scope: "build",
+						},
+					},
+				}, true),
+			templatePath: "testdata/output",
+			want:         "internal/testdata/basic/autogen-templates/test30-autogenerated-access",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
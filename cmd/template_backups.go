@@ -6,7 +6,6 @@ import (
 
 	"github.com/spf13/cobra"
 	generator "github.com/uselagoon/build-deploy-tool/internal/generator"
-	"github.com/uselagoon/build-deploy-tool/internal/helpers"
 	servicestemplates "github.com/uselagoon/build-deploy-tool/internal/templating"
 	"sigs.k8s.io/yaml"
 )
@@ -29,21 +28,28 @@ var backupGeneration = &cobra.Command{
 			return err
 		}
 		generator.BackupConfiguration.K8upVersion = k8upVersion
-		return BackupTemplateGeneration(generator)
+		if err := BackupTemplateGeneration(generator); err != nil {
+			return err
+		}
+		setResult(templatesResult{Templates: commandOutput.templates})
+		return nil
 	},
 }
 
 // BackupTemplateGeneration .
 func BackupTemplateGeneration(g generator.GeneratorInput,
 ) error {
-	lagoonBuild, err := generator.NewGenerator(
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
 		return err
 	}
-	savedTemplates := g.SavedTemplatesPath
+	return writeBackupTemplates(lagoonBuild, g.SavedTemplatesPath)
+}
 
+// writeBackupTemplates writes the backup schedule and prebackuppod templates for an already generated build to the saved templates path
+func writeBackupTemplates(lagoonBuild *generator.Generator, savedTemplates string) error {
 	// TODO: the dbaas consumers aren't known when the generator runs currently
 	// so this is a small helper function to collect this from the build stage
 	// this will eventually need to be collected directly by the generator or some other component
@@ -78,7 +84,7 @@ func BackupTemplateGeneration(g generator.GeneratorInput,
 		return fmt.Errorf("couldn't generate template: %v", err)
 	}
 	if len(templateYAML) > 0 {
-		helpers.WriteTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, "k8up-lagoon-backup-schedule"), templateYAML)
+		writeTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, "k8up-lagoon-backup-schedule"), templateYAML)
 	}
 	// generate any prebackuppod templates
 	pbps, err := servicestemplates.GeneratePreBackupPod(*lagoonBuild.BuildValues)
@@ -90,7 +96,7 @@ func BackupTemplateGeneration(g generator.GeneratorInput,
 		return fmt.Errorf("couldn't generate template: %v", err)
 	}
 	if len(templateYAML) > 0 {
-		helpers.WriteTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, "prebackuppods"), templateYAML)
+		writeTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, "prebackuppods"), templateYAML)
 	}
 	return nil
 }
@@ -5,7 +5,6 @@ import (
 
 	"github.com/spf13/cobra"
 	generator "github.com/uselagoon/build-deploy-tool/internal/generator"
-	"github.com/uselagoon/build-deploy-tool/internal/helpers"
 	servicestemplates "github.com/uselagoon/build-deploy-tool/internal/templating"
 )
 
@@ -18,21 +17,28 @@ var dbaasGeneration = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		return DBaaSTemplateGeneration(generator)
+		if err := DBaaSTemplateGeneration(generator); err != nil {
+			return err
+		}
+		setResult(templatesResult{Templates: commandOutput.templates})
+		return nil
 	},
 }
 
 // DBaaSTemplateGeneration .
 func DBaaSTemplateGeneration(g generator.GeneratorInput,
 ) error {
-	lagoonBuild, err := generator.NewGenerator(
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
 		return err
 	}
-	savedTemplates := g.SavedTemplatesPath
+	return writeDBaaSTemplates(lagoonBuild, g.SavedTemplatesPath, g.Debug)
+}
 
+// writeDBaaSTemplates writes the dbaas consumer templates for an already generated build to the saved templates path
+func writeDBaaSTemplates(lagoonBuild *generator.Generator, savedTemplates string, debug bool) error {
 	dbaas, err := servicestemplates.GenerateDBaaSTemplate(*lagoonBuild.BuildValues)
 	if err != nil {
 		return fmt.Errorf("couldn't generate template: %v", err)
@@ -42,8 +48,8 @@ func DBaaSTemplateGeneration(g generator.GeneratorInput,
 		return fmt.Errorf("couldn't generate template: %v", err)
 	}
 	if len(templateYAML) > 0 {
-		helpers.WriteTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, "dbaas"), templateYAML)
-		if g.Debug {
+		writeTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, "dbaas"), templateYAML)
+		if debug {
 			fmt.Printf("Templating dbaas consumers to %s\n", fmt.Sprintf("%s/%s.yaml", savedTemplates, "dbaas"))
 		}
 	}
@@ -101,6 +101,7 @@ func TestDBaaSTemplateGeneration(t *testing.T) {
 					Branch:          "main",
 					LagoonYAML:      "internal/testdata/complex/lagoon.services.yml",
 					ProjectVariables: []lagoon.EnvironmentVariable{
+						{Name: "LAGOON_FEATURE_FLAG_DBAAS_FALLBACK_SINGLE", Value: "enabled", Scope: "build"},
 						{Name: "LAGOON_DBAAS_ENVIRONMENT_TYPES", Value: "postgres-15:production-postgres,mongo-4:production-mongo", Scope: "build"},
 					},
 				}, true),
@@ -5,8 +5,9 @@ import (
 
 	"github.com/spf13/cobra"
 	generator "github.com/uselagoon/build-deploy-tool/internal/generator"
-	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
 	servicestemplates "github.com/uselagoon/build-deploy-tool/internal/templating"
+	networkv1 "k8s.io/api/networking/v1"
 )
 
 var routeGeneration = &cobra.Command{
@@ -18,33 +19,37 @@ var routeGeneration = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		return IngressTemplateGeneration(generator)
+		if err := IngressTemplateGeneration(generator); err != nil {
+			return err
+		}
+		setResult(templatesResult{Templates: commandOutput.templates})
+		return nil
 	},
 }
 
 // IngressTemplateGeneration .
 func IngressTemplateGeneration(g generator.GeneratorInput) error {
-	lagoonBuild, err := generator.NewGenerator(
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
 		return err
 	}
-	savedTemplates := g.SavedTemplatesPath
+	return writeIngressTemplates(lagoonBuild, g.SavedTemplatesPath, g.Debug)
+}
+
+// writeIngressTemplates writes the ingress templates for an already generated build to the saved templates path
+func writeIngressTemplates(lagoonBuild *generator.Generator, savedTemplates string, debug bool) error {
 	// generate the templates
 	for _, route := range lagoonBuild.MainRoutes.Routes {
-		if g.Debug {
+		if debug {
 			fmt.Printf("Templating ingress manifest for %s to %s\n", route.Domain, fmt.Sprintf("%s/%s.yaml", savedTemplates, route.Domain))
 		}
-		ingress, err := servicestemplates.GenerateIngressTemplate(route, *lagoonBuild.BuildValues)
+		templateYAML, err := templateRoute(route, *lagoonBuild.BuildValues)
 		if err != nil {
-			return fmt.Errorf("couldn't generate template: %v", err)
-		}
-		templateYAML, err := servicestemplates.TemplateIngress(ingress)
-		if err != nil {
-			return fmt.Errorf("couldn't generate template: %v", err)
+			return err
 		}
-		helpers.WriteTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, route.Domain), templateYAML)
+		writeTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, route.Domain), templateYAML)
 	}
 	if *lagoonBuild.ActiveEnvironment || *lagoonBuild.StandbyEnvironment {
 		// active/standby routes should not be changed by any environment defined routes.
@@ -53,23 +58,132 @@ func IngressTemplateGeneration(g generator.GeneratorInput) error {
 		// section are created correctly ensuring active/standby will work
 		// generate the templates for active/standby routes separately to normal routes
 		for _, route := range lagoonBuild.ActiveStandbyRoutes.Routes {
-			if g.Debug {
+			if debug {
 				fmt.Printf("Templating active/standby ingress manifest for %s to %s\n", route.Domain, fmt.Sprintf("%s/%s.yaml", savedTemplates, route.Domain))
 			}
-			ingress, err := servicestemplates.GenerateIngressTemplate(route, *lagoonBuild.BuildValues)
+			templateYAML, err := templateRoute(route, *lagoonBuild.BuildValues)
 			if err != nil {
-				return fmt.Errorf("couldn't generate template: %v", err)
+				return err
 			}
-			templateYAML, err := servicestemplates.TemplateIngress(ingress)
-			if err != nil {
-				return fmt.Errorf("couldn't generate template: %v", err)
-			}
-			helpers.WriteTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, route.Domain), templateYAML)
+			writeTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, route.Domain), templateYAML)
 		}
 	}
 	return nil
 }
 
+// templateRoute renders a route as an ingress, or as HTTPRoutes if the route is attached to a gateway
+func templateRoute(route lagoon.RouteV2, buildValues generator.BuildValues) ([]byte, error) {
+	if gateway := generator.RouteGateway(buildValues, route); gateway != nil {
+		httpRoutes, certificate, err := servicestemplates.GenerateHTTPRouteTemplate(route, *gateway, buildValues)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't generate template: %v", err)
+		}
+		templateYAML, err := servicestemplates.TemplateHTTPRoute(httpRoutes, certificate)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't generate template: %v", err)
+		}
+		return templateYAML, nil
+	}
+	ingress, err := servicestemplates.GenerateIngressTemplate(route, buildValues)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate template: %v", err)
+	}
+	extraIngresses, err := servicestemplates.GenerateIngressPathTemplates(route, buildValues, ingress)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate template: %v", err)
+	}
+	configMap, err := servicestemplates.GenerateRouteHeadersTemplate(route, buildValues)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate template: %v", err)
+	}
+	canaryIngress, canaryService, err := servicestemplates.GenerateIngressCanaryTemplate(route, buildValues, ingress)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate template: %v", err)
+	}
+	if canaryIngress != nil {
+		extraIngresses = append(extraIngresses, canaryIngress)
+	}
+	var templateYAML []byte
+	if configMap != nil {
+		cBytes, err := servicestemplates.TemplateConfigMap(configMap)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't generate template: %v", err)
+		}
+		templateYAML = append(templateYAML, cBytes...)
+	}
+	if canaryService != nil {
+		sBytes, err := servicestemplates.TemplateService(*canaryService)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't generate template: %v", err)
+		}
+		templateYAML = append(templateYAML, sBytes...)
+	}
+	for _, i := range append([]*networkv1.Ingress{ingress}, extraIngresses...) {
+		iBytes, err := servicestemplates.TemplateIngress(i)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't generate template: %v", err)
+		}
+		templateYAML = append(templateYAML, iBytes...)
+	}
+	return templateYAML, nil
+}
+
+// routeIngressNames returns the names of the ingresses a route is templated as, the main ingress of the route
+// and the additional ingresses for its redirected and rewritten paths and its canary. These are all kept by the route cleanup of a build.
+// The name of the service for a canary in another environment is returned too, if the route has one.
+// A route that is attached to a gateway has no ingresses
+func routeIngressNames(route lagoon.RouteV2, buildValues generator.BuildValues) ([]string, string, error) {
+	if gateway := generator.RouteGateway(buildValues, route); gateway != nil {
+		return []string{}, "", nil
+	}
+	names := []string{route.IngressName}
+	ingress, err := servicestemplates.GenerateIngressTemplate(route, buildValues)
+	if err != nil {
+		return nil, "", fmt.Errorf("couldn't generate template: %v", err)
+	}
+	pathIngresses, err := servicestemplates.GenerateIngressPathTemplates(route, buildValues, ingress)
+	if err != nil {
+		return nil, "", fmt.Errorf("couldn't generate template: %v", err)
+	}
+	for _, i := range pathIngresses {
+		names = append(names, i.ObjectMeta.Name)
+	}
+	canaryIngress, canaryService, err := servicestemplates.GenerateIngressCanaryTemplate(route, buildValues, ingress)
+	if err != nil {
+		return nil, "", fmt.Errorf("couldn't generate template: %v", err)
+	}
+	if canaryIngress != nil {
+		names = append(names, canaryIngress.ObjectMeta.Name)
+	}
+	serviceName := ""
+	if canaryService != nil {
+		serviceName = canaryService.ObjectMeta.Name
+	}
+	return names, serviceName, nil
+}
+
+// routeHTTPRouteNames returns the names of the HTTPRoutes and the certificate a route that is attached to a gateway is templated as.
+// A route that is templated as an ingress has neither
+func routeHTTPRouteNames(route lagoon.RouteV2, buildValues generator.BuildValues) ([]string, []string, error) {
+	names := []string{}
+	certificates := []string{}
+	gateway := generator.RouteGateway(buildValues, route)
+	if gateway == nil {
+		return names, certificates, nil
+	}
+	httpRoutes, certificate, err := servicestemplates.GenerateHTTPRouteTemplate(route, *gateway, buildValues)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't generate template: %v", err)
+	}
+	for _, r := range httpRoutes {
+		names = append(names, r.ObjectMeta.Name)
+	}
+	if certificate != nil {
+		certificates = append(certificates, certificate.ObjectMeta.Name)
+	}
+	return names, certificates, nil
+}
+
 func init() {
 	templateCmd.AddCommand(routeGeneration)
 }
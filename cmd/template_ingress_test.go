@@ -12,6 +12,8 @@ import (
 	"github.com/uselagoon/build-deploy-tool/internal/helpers"
 	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
 	"github.com/uselagoon/build-deploy-tool/internal/testdata"
+	networkv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/yaml"
 
 	// changes the testing to source from root so paths to test resources must be defined from repo root
 	_ "github.com/uselagoon/build-deploy-tool/internal/testing"
@@ -160,6 +162,9 @@ func TestTemplateRoutes(t *testing.T) {
 					EnvironmentName: "production",
 					Branch:          "production",
 					LagoonYAML:      "internal/testdata/complex/lagoon.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{Name: "LAGOON_FEATURE_FLAG_DBAAS_FALLBACK_SINGLE", Value: "enabled", Scope: "build"},
+					},
 				}, true),
 			templatePath: "testoutput",
 			want:         "internal/testdata/complex/ingress-templates/ingress-1",
@@ -413,6 +418,247 @@ func TestTemplateRoutes(t *testing.T) {
 			templatePath: "testdata/output",
 			want:         "internal/testdata/basic/ingress-templates/test25-pathroutes",
 		},
+		{
+			name: "test26 gateway pathroutes with certificate",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.pathroutes.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{
+							Name:  "LAGOON_FEATURE_FLAG_ROUTE_BACKEND",
+							Value: "gateway",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_FEATURE_FLAG_GATEWAY_NAME",
+							Value: "lagoon-gateway/public",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_FEATURE_FLAG_GATEWAY_CERTIFICATE_ISSUER",
+							Value: "ClusterIssuer/lagoon-acme",
+							Scope: "build",
+						},
+					},
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/basic/ingress-templates/test26-pathroutes-gateway",
+		},
+		{
+			name: "test27 gateway hsts advanced",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "hsts2",
+					Branch:          "hsts2",
+					LagoonYAML:      "internal/testdata/node/lagoon.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{
+							Name:  "LAGOON_FEATURE_FLAG_ROUTE_BACKEND",
+							Value: "gateway",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_FEATURE_FLAG_GATEWAY_NAME",
+							Value: "lagoon-gateway/public",
+							Scope: "build",
+						},
+					},
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/node/ingress-templates/ingress-24",
+		},
+		{
+			name: "test28 gateway from ingress class mapping",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "ingressclass",
+					Branch:          "ingressclass",
+					IngressClass:    "nginx",
+					LagoonYAML:      "internal/testdata/node/lagoon.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{
+							Name:  "LAGOON_FEATURE_FLAG_GATEWAY_INGRESS_CLASSES",
+							Value: "custom-ingress=lagoon-gateway/internal",
+							Scope: "build",
+						},
+					},
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/node/ingress-templates/ingress-25",
+		},
+		{
+			name: "test29 gateway route backend without a gateway",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/node/lagoon.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{
+							Name:  "LAGOON_FEATURE_FLAG_ROUTE_BACKEND",
+							Value: "gateway",
+							Scope: "build",
+						},
+					},
+				}, true),
+			templatePath: "testoutput",
+			wantErr:      true,
+			wantErrMsg:   "the route backend is gateway, but no gateway has been provided",
+		},
+		{
+			name: "test30 response headers, redirects and rewrites",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.redirects.yml",
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/basic/ingress-templates/test30-redirects",
+		},
+		{
+			name: "test31 gateway response headers, redirects and rewrites",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.redirects.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{
+							Name:  "LAGOON_FEATURE_FLAG_ROUTE_BACKEND",
+							Value: "gateway",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_FEATURE_FLAG_GATEWAY_NAME",
+							Value: "lagoon-gateway/public",
+							Scope: "build",
+						},
+					},
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/basic/ingress-templates/test31-redirects-gateway",
+		},
+		{
+			name: "test32 access restrictions with environment defaults",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "develop",
+					Branch:          "develop",
+					EnvironmentType: "development",
+					LagoonYAML:      "internal/testdata/basic/lagoon.access.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{
+							Name:  "LAGOON_ROUTE_ALLOW_CIDRS",
+							Value: "192.0.2.0/24",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_ROUTE_BASIC_AUTH_SECRET",
+							Value: "basic-auth",
+							Scope: "build",
+						},
+					},
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/basic/ingress-templates/test32-access",
+		},
+		{
+			name: "test33 canary",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.canary.yml",
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/basic/ingress-templates/test33-canary",
+		},
+		{
+			name: "test34 gateway canary",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.canary-gateway.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{
+							Name:  "LAGOON_FEATURE_FLAG_ROUTE_BACKEND",
+							Value: "gateway",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_FEATURE_FLAG_GATEWAY_NAME",
+							Value: "lagoon-gateway/public",
+							Scope: "build",
+						},
+					},
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/basic/ingress-templates/test34-canary-gateway",
+		},
+		{
+			name: "test35 canary in a development environment",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "develop",
+					Branch:          "develop",
+					EnvironmentType: "development",
+					LagoonYAML:      "internal/testdata/basic/lagoon.canary.yml",
+				}, true),
+			templatePath: "testoutput",
+			wantErr:      true,
+			wantErrMsg:   "couldn't generate and merge routes: couldn't generate and merge routes: route c.example.com has a canary, this can only be used in production environments",
+		},
+		{
+			name: "test36 certificates",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.certificates.yml",
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/basic/ingress-templates/test36-certificates",
+		},
+		{
+			name: "test37 gateway canary in another environment",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.canary.yml",
+					ProjectVariables: []lagoon.EnvironmentVariable{
+						{
+							Name:  "LAGOON_FEATURE_FLAG_ROUTE_BACKEND",
+							Value: "gateway",
+							Scope: "build",
+						},
+						{
+							Name:  "LAGOON_FEATURE_FLAG_GATEWAY_NAME",
+							Value: "lagoon-gateway/public",
+							Scope: "build",
+						},
+					},
+				}, true),
+			templatePath: "testoutput",
+			wantErr:      true,
+			wantErrMsg:   "couldn't generate template: route b.example.com has a canary in the release environment, this isn't supported by gateway api routes",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -497,3 +743,97 @@ func TestTemplateRoutes(t *testing.T) {
 		})
 	}
 }
+
+// TestTemplateRoutesCertificateCleanup checks that the certificates of routes survive the post-deploy step of the legacy build,
+// which removes the certificate in the tls secret of every ingress that has tls-acme set to false
+func TestTemplateRoutesCertificateCleanup(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         testdata.TestData
+		templatePath string
+	}{
+		{
+			name: "test1 redirects and rewrites",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.redirects.yml",
+				}, true),
+			templatePath: "testoutput",
+		},
+		{
+			name: "test2 canary",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/basic/lagoon.canary.yml",
+				}, true),
+			templatePath: "testoutput",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helpers.UnsetEnvVars(nil) //unset variables before running tests
+			savedTemplates := tt.templatePath
+			generator, err := testdata.SetupEnvironment(*rootCmd, savedTemplates, tt.args)
+			if err != nil {
+				t.Errorf("%v", err)
+			}
+			err = os.MkdirAll(savedTemplates, 0755)
+			if err != nil {
+				t.Errorf("couldn't create directory %v: %v", savedTemplates, err)
+			}
+			defer os.RemoveAll(savedTemplates)
+
+			if err := IngressTemplateGeneration(generator); err != nil {
+				t.Fatalf("IngressTemplateGeneration() error = %v", err)
+			}
+			files, err := os.ReadDir(savedTemplates)
+			if err != nil {
+				t.Fatalf("couldn't read directory %v: %v", savedTemplates, err)
+			}
+			// the secrets of the ingresses that request a certificate, and the secrets the legacy build removes
+			certificates := map[string]string{}
+			removed := map[string]string{}
+			for _, f := range files {
+				f1, err := os.ReadFile(fmt.Sprintf("%s/%s", savedTemplates, f.Name()))
+				if err != nil {
+					t.Fatalf("couldn't read file %v: %v", savedTemplates, err)
+				}
+				for _, doc := range strings.Split(string(f1), "---\n") {
+					ingress := networkv1.Ingress{}
+					if err := yaml.Unmarshal([]byte(doc), &ingress); err != nil {
+						t.Fatalf("couldn't read template %v: %v", f.Name(), err)
+					}
+					if ingress.Kind != "Ingress" {
+						continue
+					}
+					for _, tls := range ingress.Spec.TLS {
+						switch ingress.Annotations["kubernetes.io/tls-acme"] {
+						case "true":
+							certificates[tls.SecretName] = ingress.Name
+						case "false":
+							removed[tls.SecretName] = ingress.Name
+						}
+					}
+				}
+			}
+			if len(certificates) == 0 {
+				t.Fatalf("no ingress requested a certificate")
+			}
+			for secret, name := range certificates {
+				if by, ok := removed[secret]; ok {
+					t.Errorf("the certificate %s of ingress %s is removed because of ingress %s", secret, name, by)
+				}
+			}
+			t.Cleanup(func() {
+				helpers.UnsetEnvVars(nil)
+				helpers.UnsetEnvVars(tt.args.BuildPodVariables)
+			})
+		})
+	}
+}
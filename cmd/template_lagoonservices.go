@@ -3,10 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	generator "github.com/uselagoon/build-deploy-tool/internal/generator"
-	"github.com/uselagoon/build-deploy-tool/internal/helpers"
 	servicestemplates "github.com/uselagoon/build-deploy-tool/internal/templating"
 	"sigs.k8s.io/yaml"
 )
@@ -33,7 +33,18 @@ var lagoonServiceGeneration = &cobra.Command{
 			return err
 		}
 		gen.ImageReferences = imageRefs.Images
-		return LagoonServiceTemplateGeneration(gen)
+		jobsPath, err := cmd.Flags().GetString("jobs-path")
+		if err != nil {
+			return fmt.Errorf("error reading jobs-path flag: %v", err)
+		}
+		if !filepath.IsAbs(jobsPath) {
+			jobsPath = filepath.Join(gen.SavedTemplatesPath, jobsPath)
+		}
+		if err := LagoonServiceTemplateGeneration(gen, jobsPath); err != nil {
+			return err
+		}
+		setResult(templatesResult{Templates: commandOutput.templates})
+		return nil
 	},
 }
 
@@ -49,16 +60,21 @@ func loadImagesFromFile(file string) (*ImageReferences, error) {
 	return imageRefs, nil
 }
 
-// LagoonServiceTemplateGeneration .
-func LagoonServiceTemplateGeneration(g generator.GeneratorInput) error {
-	lagoonBuild, err := generator.NewGenerator(
+// LagoonServiceTemplateGeneration writes the service templates to the saved templates path, and any job templates to the jobs path
+func LagoonServiceTemplateGeneration(g generator.GeneratorInput, jobsPath string) error {
+	lagoonBuild, err := newGenerator(
 		g,
 	)
 	if err != nil {
 		return err
 	}
-	savedTemplates := g.SavedTemplatesPath
+	return writeLagoonServiceTemplates(lagoonBuild, g.SavedTemplatesPath, jobsPath, g.Debug)
+}
 
+// writeLagoonServiceTemplates writes the service templates for an already generated build to the saved templates path.
+// Jobs are written to their own path, as the template of a job can't be changed once it is created, so they can't be
+// applied with the rest of the service templates and are run by deploy apply instead
+func writeLagoonServiceTemplates(lagoonBuild *generator.Generator, savedTemplates, jobsPath string, debug bool) error {
 	// generate the templates
 	secrets, err := servicestemplates.GenerateRegistrySecretTemplate(*lagoonBuild.BuildValues)
 	if err != nil {
@@ -69,10 +85,10 @@ func LagoonServiceTemplateGeneration(g generator.GeneratorInput) error {
 		if err != nil {
 			return fmt.Errorf("couldn't generate template: %v", err)
 		}
-		if g.Debug {
+		if debug {
 			fmt.Printf("Templating registry secret manifests %s\n", fmt.Sprintf("%s/%s.yaml", savedTemplates, secret.Name))
 		}
-		helpers.WriteTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, secret.Name), templateBytes)
+		writeTemplateFile(fmt.Sprintf("%s/%s.yaml", savedTemplates, secret.Name), templateBytes)
 	}
 	services, err := servicestemplates.GenerateServiceTemplate(*lagoonBuild.BuildValues)
 	if err != nil {
@@ -83,10 +99,10 @@ func LagoonServiceTemplateGeneration(g generator.GeneratorInput) error {
 		if err != nil {
 			return fmt.Errorf("couldn't generate template: %v", err)
 		}
-		if g.Debug {
+		if debug {
 			fmt.Printf("Templating service manifests %s\n", fmt.Sprintf("%s/service-%s.yaml", savedTemplates, d.Name))
 		}
-		helpers.WriteTemplateFile(fmt.Sprintf("%s/service-%s.yaml", savedTemplates, d.Name), templateBytes)
+		writeTemplateFile(fmt.Sprintf("%s/service-%s.yaml", savedTemplates, d.Name), templateBytes)
 	}
 	pvcs, err := servicestemplates.GeneratePVCTemplate(*lagoonBuild.BuildValues)
 	if err != nil {
@@ -97,10 +113,10 @@ func LagoonServiceTemplateGeneration(g generator.GeneratorInput) error {
 		if err != nil {
 			return fmt.Errorf("couldn't generate template: %v", err)
 		}
-		if g.Debug {
+		if debug {
 			fmt.Printf("Templating pvc manifests %s\n", fmt.Sprintf("%s/pvc-%s.yaml", savedTemplates, d.Name))
 		}
-		helpers.WriteTemplateFile(fmt.Sprintf("%s/pvc-%s.yaml", savedTemplates, d.Name), templateBytes)
+		writeTemplateFile(fmt.Sprintf("%s/pvc-%s.yaml", savedTemplates, d.Name), templateBytes)
 	}
 	deployments, err := servicestemplates.GenerateDeploymentTemplate(*lagoonBuild.BuildValues)
 	if err != nil {
@@ -111,10 +127,38 @@ func LagoonServiceTemplateGeneration(g generator.GeneratorInput) error {
 		if err != nil {
 			return fmt.Errorf("couldn't generate template: %v", err)
 		}
-		if g.Debug {
+		if debug {
 			fmt.Printf("Templating deployment manifests %s\n", fmt.Sprintf("%s/deployment-%s.yaml", savedTemplates, d.Name))
 		}
-		helpers.WriteTemplateFile(fmt.Sprintf("%s/deployment-%s.yaml", savedTemplates, d.Name), templateBytes)
+		writeTemplateFile(fmt.Sprintf("%s/deployment-%s.yaml", savedTemplates, d.Name), templateBytes)
+	}
+	hpas, err := servicestemplates.GenerateHPATemplate(*lagoonBuild.BuildValues)
+	if err != nil {
+		return fmt.Errorf("couldn't generate template: %v", err)
+	}
+	for _, d := range hpas {
+		templateBytes, err := servicestemplates.TemplateHPA(d)
+		if err != nil {
+			return fmt.Errorf("couldn't generate template: %v", err)
+		}
+		if debug {
+			fmt.Printf("Templating horizontal pod autoscaler manifests %s\n", fmt.Sprintf("%s/hpa-%s.yaml", savedTemplates, d.Name))
+		}
+		writeTemplateFile(fmt.Sprintf("%s/hpa-%s.yaml", savedTemplates, d.Name), templateBytes)
+	}
+	pdbs, err := servicestemplates.GeneratePDBTemplate(*lagoonBuild.BuildValues)
+	if err != nil {
+		return fmt.Errorf("couldn't generate template: %v", err)
+	}
+	for _, d := range pdbs {
+		templateBytes, err := servicestemplates.TemplatePDB(d)
+		if err != nil {
+			return fmt.Errorf("couldn't generate template: %v", err)
+		}
+		if debug {
+			fmt.Printf("Templating pod disruption budget manifests %s\n", fmt.Sprintf("%s/pdb-%s.yaml", savedTemplates, d.Name))
+		}
+		writeTemplateFile(fmt.Sprintf("%s/pdb-%s.yaml", savedTemplates, d.Name), templateBytes)
 	}
 	cronjobs, err := servicestemplates.GenerateCronjobTemplate(*lagoonBuild.BuildValues)
 	if err != nil {
@@ -125,10 +169,29 @@ func LagoonServiceTemplateGeneration(g generator.GeneratorInput) error {
 		if err != nil {
 			return fmt.Errorf("couldn't generate template: %v", err)
 		}
-		if g.Debug {
+		if debug {
 			fmt.Printf("Templating cronjob manifests %s\n", fmt.Sprintf("%s/cronjob-%s.yaml", savedTemplates, d.Name))
 		}
-		helpers.WriteTemplateFile(fmt.Sprintf("%s/cronjob-%s.yaml", savedTemplates, d.Name), templateBytes)
+		writeTemplateFile(fmt.Sprintf("%s/cronjob-%s.yaml", savedTemplates, d.Name), templateBytes)
+	}
+	jobs, err := servicestemplates.GenerateJobTemplate(*lagoonBuild.BuildValues)
+	if err != nil {
+		return fmt.Errorf("couldn't generate template: %v", err)
+	}
+	if len(jobs) > 0 {
+		if err := os.MkdirAll(jobsPath, 0755); err != nil {
+			return fmt.Errorf("couldn't create directory %v: %v", jobsPath, err)
+		}
+	}
+	for _, d := range jobs {
+		templateBytes, err := servicestemplates.TemplateJob(d)
+		if err != nil {
+			return fmt.Errorf("couldn't generate template: %v", err)
+		}
+		if debug {
+			fmt.Printf("Templating job manifests %s\n", fmt.Sprintf("%s/job-%s.yaml", jobsPath, d.Name))
+		}
+		writeTemplateFile(fmt.Sprintf("%s/job-%s.yaml", jobsPath, d.Name), templateBytes)
 	}
 	if lagoonBuild.BuildValues.IsolationNetworkPolicy {
 		// if isolation network policies are enabled, template that here
@@ -140,14 +203,16 @@ func LagoonServiceTemplateGeneration(g generator.GeneratorInput) error {
 		if err != nil {
 			return fmt.Errorf("couldn't generate template: %v", err)
 		}
-		if g.Debug {
+		if debug {
 			fmt.Printf("Templating networkpolicy manifest %s\n", fmt.Sprintf("%s/isolation-network-policy.yaml", savedTemplates))
 		}
-		helpers.WriteTemplateFile(fmt.Sprintf("%s/isolation-network-policy.yaml", savedTemplates), templateBytes)
+		writeTemplateFile(fmt.Sprintf("%s/isolation-network-policy.yaml", savedTemplates), templateBytes)
 	}
 	return nil
 }
 
 func init() {
 	templateCmd.AddCommand(lagoonServiceGeneration)
+	lagoonServiceGeneration.Flags().StringP("jobs-path", "", "jobs",
+		"Path to where the job templates are saved, relative paths are created within the saved-templates-path")
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/uselagoon/build-deploy-tool/internal/dbaasclient"
 	"github.com/uselagoon/build-deploy-tool/internal/helpers"
 	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
 	"github.com/uselagoon/build-deploy-tool/internal/testdata"
 
 	// changes the testing to source from root so paths to test resources must be defined from repo root
@@ -23,8 +24,12 @@ func TestTemplateLagoonServices(t *testing.T) {
 		args         testdata.TestData
 		templatePath string
 		want         string
-		imageData    string
-		vars         []helpers.EnvironmentVariable
+		// wantJobs is where the job templates are compared from, if the build has jobs
+		wantJobs  string
+		imageData string
+		vars      []helpers.EnvironmentVariable
+		// serviceTypesFile is loaded into the service types registry before the templates are generated
+		serviceTypesFile string
 	}{
 		{
 			name:        "test1-basic-deployment",
@@ -364,6 +369,7 @@ func TestTemplateLagoonServices(t *testing.T) {
 						"mongo-4":       "harbor.example/example-project/main/mongo-4@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
 					},
 					ProjectVariables: []lagoon.EnvironmentVariable{
+						{Name: "LAGOON_FEATURE_FLAG_DBAAS_FALLBACK_SINGLE", Value: "enabled", Scope: "build"},
 						{
 							Name:  "LAGOON_DBAAS_ENVIRONMENT_TYPES",
 							Value: "postgres-15:production-postgres,mongo-4:production-mongo,mariadb-10-11:production-mariadb",
@@ -601,6 +607,93 @@ func TestTemplateLagoonServices(t *testing.T) {
 			templatePath: "testoutput",
 			want:         "internal/testdata/complex/service-templates/test16-nginx-php-resources",
 		},
+		{
+			name:        "test17-nginx-php-autoscaling",
+			description: "tests an nginx-php deployment with autoscaling capped by the cluster",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/complex/lagoon.autoscaling.yml",
+					ImageReferences: map[string]string{
+						"nginx": "harbor.example/example-project/main/nginx@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"php":   "harbor.example/example-project/main/php@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"cli":   "harbor.example/example-project/main/cli@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+					},
+					BuildPodVariables: []helpers.EnvironmentVariable{
+						{
+							Name:  "ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING",
+							Value: "enabled",
+						},
+						{
+							Name:  "ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING_MAX_REPLICAS",
+							Value: "8",
+						},
+					},
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/complex/service-templates/test17-nginx-php-autoscaling",
+		},
+		{
+			name:        "test18-custom-service-types",
+			description: "tests deployments of service types loaded from a file",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/servicetypes/lagoon.yml",
+					ImageReferences: map[string]string{
+						"api":       "harbor.example/example-project/main/api@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"memcached": "harbor.example/example-project/main/memcached@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+					},
+				}, true),
+			serviceTypesFile: "internal/testdata/servicetypes/service-types.yml",
+			templatePath:     "testoutput",
+			want:             "internal/testdata/servicetypes/service-templates/test18-custom-service-types",
+		},
+		{
+			name:        "test19-nginx-php-sidecars",
+			description: "tests an nginx-php deployment with sidecars of both services",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/complex/lagoon.sidecars.yml",
+					ImageReferences: map[string]string{
+						"nginx":          "harbor.example/example-project/main/nginx@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"php":            "harbor.example/example-project/main/php@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"cli":            "harbor.example/example-project/main/cli@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"log-shipper":    "harbor.example/example-project/main/log-shipper@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"cloudsql-proxy": "harbor.example/example-project/main/cloudsql-proxy@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+					},
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/complex/service-templates/test19-nginx-php-sidecars",
+		},
+		{
+			name:        "test20-nginx-php-jobs",
+			description: "tests an nginx-php deployment with an init container, and a job",
+			args: testdata.GetSeedData(
+				testdata.TestData{
+					ProjectName:     "example-project",
+					EnvironmentName: "main",
+					Branch:          "main",
+					LagoonYAML:      "internal/testdata/complex/lagoon.jobs.yml",
+					ImageReferences: map[string]string{
+						"nginx":         "harbor.example/example-project/main/nginx@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"php":           "harbor.example/example-project/main/php@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"cli":           "harbor.example/example-project/main/cli@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"migrate":       "harbor.example/example-project/main/migrate@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+						"wait-for-solr": "harbor.example/example-project/main/wait-for-solr@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+					},
+				}, true),
+			templatePath: "testoutput",
+			want:         "internal/testdata/complex/service-templates/test20-nginx-php-jobs",
+			wantJobs:     "internal/testdata/complex/job-templates/test20-nginx-php-jobs",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -611,6 +704,11 @@ func TestTemplateLagoonServices(t *testing.T) {
 					t.Errorf("%v", err)
 				}
 			}
+			err := servicetypes.LoadServiceTypes(tt.serviceTypesFile, false)
+			if err != nil {
+				t.Errorf("%v", err)
+			}
+			defer servicetypes.LoadServiceTypes("", false)
 			// set the environment variables from args
 			savedTemplates := tt.templatePath
 			generator, err := testdata.SetupEnvironment(*rootCmd, savedTemplates, tt.args)
@@ -639,10 +737,17 @@ func TestTemplateLagoonServices(t *testing.T) {
 				}
 				generator.ImageReferences = imageRefs.Images
 			}
-			err = LagoonServiceTemplateGeneration(generator)
+			jobsPath := fmt.Sprintf("%s-jobs", savedTemplates)
+			defer os.RemoveAll(jobsPath)
+			err = LagoonServiceTemplateGeneration(generator, jobsPath)
 			if err != nil {
 				t.Errorf("%v", err)
 			}
+			if tt.wantJobs != "" {
+				compareTemplateDirs(t, jobsPath, tt.wantJobs)
+			} else if _, err := os.Stat(jobsPath); err == nil {
+				t.Errorf("job templates were generated in %v", jobsPath)
+			}
 
 			files, err := os.ReadDir(savedTemplates)
 			if err != nil {
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
@@ -12,30 +11,23 @@ var validateDockerCompose = &cobra.Command{
 	Use:     "docker-compose",
 	Aliases: []string{"compose", "dc"},
 	Short:   "Verify docker-compose file for compatability with this tool",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// @TODO: ignoreNonStringKeyErrors is `true` by default because Lagoon doesn't enforce
 		// docker-compose compliance yet
 		ignoreMissingEnvFiles, err := rootCmd.PersistentFlags().GetBool("ignore-missing-env-files")
 		if err != nil {
-			fmt.Println(fmt.Errorf("error reading ignore-missing-env-files flag: %v", err))
-			os.Exit(1)
+			return fmt.Errorf("error reading ignore-missing-env-files flag: %v", err)
 		}
 		ignoreNonStringKeyErrors, err := rootCmd.PersistentFlags().GetBool("ignore-non-string-key-errors")
 		if err != nil {
-			fmt.Println(fmt.Errorf("error reading ignore-non-string-key-errors flag: %v", err))
-			os.Exit(1)
+			return fmt.Errorf("error reading ignore-non-string-key-errors flag: %v", err)
 		}
 		dockerComposeFile, err := cmd.Flags().GetString("docker-compose")
 		if err != nil {
-			fmt.Println(fmt.Errorf("error reading docker-compose flag: %v", err))
-			os.Exit(1)
+			return fmt.Errorf("error reading docker-compose flag: %v", err)
 		}
 
-		err = ValidateDockerCompose(dockerComposeFile, ignoreNonStringKeyErrors, ignoreMissingEnvFiles)
-		if err != nil {
-			fmt.Println(err.Error())
-			os.Exit(1)
-		}
+		return ValidateDockerCompose(dockerComposeFile, ignoreNonStringKeyErrors, ignoreMissingEnvFiles)
 	},
 }
 
@@ -43,18 +35,13 @@ var validateDockerComposeWithErrors = &cobra.Command{
 	Use:     "docker-compose-with-errors",
 	Aliases: []string{"dcwe"},
 	Short:   "Verify docker-compose file for compatability with this tool with next versions of compose-go library",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		dockerComposeFile, err := cmd.Flags().GetString("docker-compose")
 		if err != nil {
-			fmt.Println(fmt.Errorf("error reading docker-compose flag: %v", err))
-			os.Exit(1)
+			return fmt.Errorf("error reading docker-compose flag: %v", err)
 		}
 
-		err = validateDockerComposeWithError(dockerComposeFile)
-		if err != nil {
-			fmt.Println(err.Error())
-			os.Exit(1)
-		}
+		return validateDockerComposeWithError(dockerComposeFile)
 	},
 }
 
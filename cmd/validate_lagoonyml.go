@@ -14,46 +14,62 @@ import (
 var validateLagoonYml = &cobra.Command{
 	Use:   "lagoon-yml",
 	Short: "Verify .lagoon.yml and environment for compatability with this tool",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		lagoonYAML, err := rootCmd.PersistentFlags().GetString("lagoon-yml")
 		if err != nil {
-			fmt.Println(fmt.Errorf("error reading lagoon-yml flag: %v", err))
-			os.Exit(1)
+			return fmt.Errorf("error reading lagoon-yml flag: %v", err)
 		}
 		lagoonYAMLOverride, err := rootCmd.PersistentFlags().GetString("lagoon-yml-override")
 		if err != nil {
-			fmt.Println(fmt.Errorf("error reading lagoon-yml-override flag: %v", err))
-			os.Exit(1)
+			return fmt.Errorf("error reading lagoon-yml-override flag: %v", err)
 		}
 		projectName, err := rootCmd.PersistentFlags().GetString("project-name")
 		if err != nil {
-			fmt.Println(fmt.Errorf("error reading project-name flag: %v", err))
-			os.Exit(1)
+			return fmt.Errorf("error reading project-name flag: %v", err)
 		}
 		printOutput, err := cmd.Flags().GetBool("print-resulting-lagoonyml")
 		if err != nil {
-			fmt.Println(fmt.Errorf("error reading print-resulting-lagoonyml flag: %v", err))
-			os.Exit(1)
+			return fmt.Errorf("error reading print-resulting-lagoonyml flag: %v", err)
+		}
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			return fmt.Errorf("error reading strict flag: %v", err)
+		}
+
+		problems, err := CheckLagoonYml(lagoonYAML, lagoonYAMLOverride, strict)
+		setResult(validateLagoonYmlResult{Problems: problems})
+		if err != nil {
+			return fmt.Errorf("Could not validate your .lagoon.yml - %v", err)
 		}
 
 		lYAML := &lagoon.YAML{}
 		err = ValidateLagoonYml(lagoonYAML, lagoonYAMLOverride, "LAGOON_YAML_OVERRIDE", lYAML, projectName, false)
 		if err != nil {
-			fmt.Println("Could not validate your .lagoon.yml -", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("Could not validate your .lagoon.yml - %v", err)
 		}
 
 		if printOutput {
 			resultingBS, err := yaml.Marshal(lYAML)
 			if err != nil {
-				fmt.Println("Unable to unmarshal resulting yml for printing: ", err)
-				os.Exit(1)
+				return fmt.Errorf("Unable to unmarshal resulting yml for printing: %v", err)
 			}
 			fmt.Println(string(resultingBS))
 		}
+		return nil
 	},
 }
 
+// validateLagoonYmlResult is the result of the validate lagoon-yml command
+type validateLagoonYmlResult struct {
+	Problems []LagoonYmlProblem `json:"problems"`
+}
+
+// LagoonYmlProblem is an unknown key, or boolean defined as a string, in a .lagoon.yml or override file
+type LagoonYmlProblem struct {
+	File string `json:"file"`
+	lagoon.YAMLProblem
+}
+
 func ValidateLagoonYml(lagoonYml string, lagoonYmlOverride string, lagoonYmlEnvVar string, lYAML *lagoon.YAML, projectName string, debug bool) error {
 	if err := generator.LoadAndUnmarshalLagoonYml(lagoonYml, lagoonYmlOverride, lagoonYmlEnvVar, lYAML, projectName, debug); err != nil {
 		return err
@@ -76,9 +92,43 @@ func ValidateLagoonYml(lagoonYml string, lagoonYmlOverride string, lagoonYmlEnvV
 	return nil
 }
 
+// CheckLagoonYml prints and returns any unknown keys, or booleans defined as strings, in the .lagoon.yml and override files.
+// These are warnings, unless strict is set in which case an error is returned if there are any.
+func CheckLagoonYml(lagoonYml string, lagoonYmlOverride string, strict bool) ([]LagoonYmlProblem, error) {
+	files := []string{lagoonYml}
+	if _, err := os.Stat(lagoonYmlOverride); err == nil {
+		files = append(files, lagoonYmlOverride)
+	}
+	level := "warning"
+	if strict {
+		level = "error"
+	}
+	found := []LagoonYmlProblem{}
+	for _, file := range files {
+		rawYAML, err := os.ReadFile(file)
+		if err != nil {
+			return found, fmt.Errorf("couldn't read %v: %v", file, err)
+		}
+		problems, err := lagoon.CheckLagoonYAML(rawYAML)
+		if err != nil {
+			return found, fmt.Errorf("couldn't check %v: %v", file, err)
+		}
+		for _, problem := range problems {
+			fmt.Printf("%s: %s %s\n", level, file, problem)
+			found = append(found, LagoonYmlProblem{File: file, YAMLProblem: problem})
+		}
+	}
+	if strict && len(found) > 0 {
+		return found, fmt.Errorf("found %d unknown or mistyped keys", len(found))
+	}
+	return found, nil
+}
+
 func init() {
 	validateCmd.PersistentFlags().BoolP("print-resulting-lagoonyml", "", false,
 		"Display the resulting, post merging, lagoon.yml file.")
+	validateLagoonYml.Flags().BoolP("strict", "", false,
+		"Return an error for unknown keys and booleans defined as strings, instead of a warning.")
 	validateCmd.AddCommand(validateLagoonYml)
 }
 
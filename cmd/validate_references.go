@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+)
+
+var validateReferences = &cobra.Command{
+	Use:     "references",
+	Aliases: []string{"refs"},
+	Short:   "Verify the services referenced in the .lagoon.yml exist in the docker-compose file",
+	Long: `Verify the services referenced in the .lagoon.yml exist in the docker-compose file
+This checks environment types and overrides, cronjobs, tasks, routes and path routes for every environment in the .lagoon.yml,
+and reports any that refer to a service or container that doesn't exist.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gen, err := generator.GenerateInput(*rootCmd, false)
+		if err != nil {
+			return err
+		}
+		dangling, err := ValidateReferences(gen)
+		if err != nil {
+			return fmt.Errorf("Could not validate the service references - %v", err)
+		}
+		setResult(validateReferencesResult{References: dangling})
+		if len(dangling) > 0 {
+			for _, ref := range dangling {
+				fmt.Printf("error: %s\n", ref)
+			}
+			return fmt.Errorf("found %d references to services that don't exist", len(dangling))
+		}
+		return nil
+	},
+}
+
+// validateReferencesResult is the result of the validate references command
+type validateReferencesResult struct {
+	References []generator.DanglingReference `json:"references"`
+}
+
+// ValidateReferences loads the .lagoon.yml and docker-compose file the same way a build does, and returns any references
+// in the .lagoon.yml to services that don't exist
+func ValidateReferences(g generator.GeneratorInput) ([]generator.DanglingReference, error) {
+	projectName := helpers.GetEnv("PROJECT", g.ProjectName, g.Debug)
+	lYAML := &lagoon.YAML{}
+	if err := generator.LoadAndUnmarshalLagoonYml(g.LagoonYAML, g.LagoonYAMLOverride, "LAGOON_YAML_OVERRIDE", lYAML, projectName, g.Debug); err != nil {
+		return nil, err
+	}
+	// take lagoon envvars and create new map for being unmarshalled against the docker-compose file
+	projectVars := []lagoon.EnvironmentVariable{}
+	envVars := []lagoon.EnvironmentVariable{}
+	json.Unmarshal([]byte(helpers.GetEnv("LAGOON_PROJECT_VARIABLES", g.ProjectVariables, g.Debug)), &projectVars)
+	json.Unmarshal([]byte(helpers.GetEnv("LAGOON_ENVIRONMENT_VARIABLES", g.EnvironmentVariables, g.Debug)), &envVars)
+	composeVars := make(map[string]string)
+	for _, envvar := range lagoon.MergeVariables(projectVars, envVars) {
+		composeVars[envvar.Name] = envvar.Value
+	}
+	lCompose, _, _, err := lagoon.UnmarshaDockerComposeYAML(lYAML.DockerComposeYAML, g.IgnoreNonStringKeyErrors, g.IgnoreMissingEnvFiles, composeVars)
+	if err != nil {
+		return nil, err
+	}
+	return generator.CheckServiceReferences(*lYAML, lCompose), nil
+}
+
+func init() {
+	validateCmd.AddCommand(validateReferences)
+}
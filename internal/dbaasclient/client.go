@@ -2,10 +2,10 @@ package dbaasclient
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
-	"net/http/httptest"
 	"strings"
+	"sync"
 	"time"
 
 	retryablehttp "github.com/hashicorp/go-retryablehttp"
@@ -17,6 +17,19 @@ type Client struct {
 	RetryWaitMin time.Duration
 	RetryWaitMax time.Duration
 	Timeout      time.Duration
+	cache        *cache
+}
+
+// cache holds the responses from the dbaas-operator, so each endpoint is only checked once for the life of the client
+type cache struct {
+	mu     sync.Mutex
+	health map[string]error
+	checks map[string]checkResult
+}
+
+type checkResult struct {
+	exists bool
+	err    error
 }
 
 type providerResponse struct {
@@ -60,24 +73,64 @@ func NewClient(c Client) *Client {
 	// disable the retryablehttp client logger
 	httpClient.Logger = nil
 	c.HTTPClient = httpClient
+	c.cache = &cache{
+		health: map[string]error{},
+		checks: map[string]checkResult{},
+	}
 	return &c
 }
 
+// CheckHealth checks the dbaas-operator is healthy, any response other than a 2xx is unhealthy
 func (c *Client) CheckHealth(dbaasEndpoint string) error {
 	// curl --write-out "%{http_code}\n" --silent --output /dev/null "http://dbaas/healthz"
 	dbaasEndpoint = addProtocol(dbaasEndpoint)
+	if c.cache != nil {
+		c.cache.mu.Lock()
+		defer c.cache.mu.Unlock()
+		if err, ok := c.cache.health[dbaasEndpoint]; ok {
+			return err
+		}
+	}
+	err := c.checkHealth(dbaasEndpoint)
+	if c.cache != nil {
+		c.cache.health[dbaasEndpoint] = err
+	}
+	return err
+}
+
+func (c *Client) checkHealth(dbaasEndpoint string) error {
 	resp, err := c.HTTPClient.Get(fmt.Sprintf("%s/healthz", dbaasEndpoint))
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("dbaas operator responded with status %d", resp.StatusCode)
+	}
 	return nil
 }
 
 // check the dbaas provider exists, will return true or false without error if it can talk to the dbaas-operator
-// will return error if there an issue with the dbaas-operator or the specified endpoint
+// will return error if there an issue with the dbaas-operator or the specified endpoint.
+// Each dbaas environment of a type is only checked once for the life of the client.
 func (c *Client) CheckProvider(dbaasEndpoint, dbaasType, dbaasEnvironment string) (bool, error) {
 	dbaasEndpoint = addProtocol(dbaasEndpoint)
+	key := fmt.Sprintf("%s/%s/%s", dbaasEndpoint, dbaasType, dbaasEnvironment)
+	if c.cache != nil {
+		c.cache.mu.Lock()
+		defer c.cache.mu.Unlock()
+		if result, ok := c.cache.checks[key]; ok {
+			return result.exists, result.err
+		}
+	}
+	exists, err := c.checkProvider(dbaasEndpoint, dbaasType, dbaasEnvironment)
+	if c.cache != nil {
+		c.cache.checks[key] = checkResult{exists: exists, err: err}
+	}
+	return exists, err
+}
+
+func (c *Client) checkProvider(dbaasEndpoint, dbaasType, dbaasEnvironment string) (bool, error) {
 	// curl --silent "http://dbaas/type/env"
 	resp, err := c.HTTPClient.Get(fmt.Sprintf("%s/%s/%s", dbaasEndpoint, dbaasType, dbaasEnvironment))
 	if err != nil {
@@ -90,47 +143,10 @@ func (c *Client) CheckProvider(dbaasEndpoint, dbaasType, dbaasEnvironment string
 		return false, fmt.Errorf("dbaas operator responded, but response is not a valid JSON payload")
 	}
 	if response.Error != "" {
-		return false, fmt.Errorf(response.Error)
+		return false, errors.New(response.Error)
 	}
 	if response.Result.Found {
 		return true, nil
 	}
 	return false, nil
 }
-
-// TestDBaaSHTTPServer is a test server used to test dbaas-responses
-func TestDBaaSHTTPServer() *httptest.Server {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/mariadb/production", func(res http.ResponseWriter, req *http.Request) {
-		res.Write([]byte(`{"result":{"found":true}}`))
-	})
-	mux.HandleFunc("/mariadb/development", func(res http.ResponseWriter, req *http.Request) {
-		res.Write([]byte(`{"result":{"found":true}}`))
-	})
-	mux.HandleFunc("/mariadb/development2", func(res http.ResponseWriter, req *http.Request) {
-		res.Write([]byte(`{"result":{"found":false},"error":"no providers for dbaas environment development2"}`))
-	})
-	mux.HandleFunc("/postgres/production", func(res http.ResponseWriter, req *http.Request) {
-		res.Write([]byte(`{"result":{"found":true}}`))
-	})
-	mux.HandleFunc("/postgres/development", func(res http.ResponseWriter, req *http.Request) {
-		res.Write([]byte(`{"result":{"found":true}}`))
-	})
-	mux.HandleFunc("/postgres/development2", func(res http.ResponseWriter, req *http.Request) {
-		res.Write([]byte(`{"result":{"found":false},"error":"no providers for dbaas environment development2"}`))
-	})
-	mux.HandleFunc("/mongodb/production", func(res http.ResponseWriter, req *http.Request) {
-		res.Write([]byte(`{"result":{"found":true}}`))
-	})
-	mux.HandleFunc("/mongodb/development", func(res http.ResponseWriter, req *http.Request) {
-		res.Write([]byte(`{"result":{"found":true}}`))
-	})
-	mux.HandleFunc("/mongodb/development2", func(res http.ResponseWriter, req *http.Request) {
-		res.Write([]byte(`{"result":{"found":false},"error":"no providers for dbaas environment development2"}`))
-	})
-	mux.HandleFunc("/healthz", func(res http.ResponseWriter, req *http.Request) {
-		res.Write([]byte("{}"))
-	})
-	ts := httptest.NewServer(mux)
-	return ts
-}
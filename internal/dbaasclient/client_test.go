@@ -1,6 +1,9 @@
 package dbaasclient
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -159,3 +162,82 @@ func Test_addProtocol(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckHealth(t *testing.T) {
+	unhealthy := false
+	tests := []struct {
+		name    string
+		config  FakeOperatorConfig
+		wantErr bool
+	}{
+		{
+			name:   "test1 - healthy",
+			config: testFakeOperatorConfig,
+		},
+		{
+			name:    "test2 - unhealthy responds with a 503",
+			config:  FakeOperatorConfig{Healthy: &unhealthy},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(NewFakeOperator(tt.config))
+			defer ts.Close()
+			d := NewClient(Client{
+				RetryMax:     1,
+				RetryWaitMin: time.Duration(10) * time.Millisecond,
+				RetryWaitMax: time.Duration(50) * time.Millisecond,
+			})
+			if err := d.CheckHealth(ts.URL); (err != nil) != tt.wantErr {
+				t.Errorf("CheckHealth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckProviderCache(t *testing.T) {
+	requests := map[string]int{}
+	fake := NewFakeOperator(testFakeOperatorConfig)
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests[req.URL.Path]++
+		fake.ServeHTTP(res, req)
+	}))
+	defer ts.Close()
+	d := NewClient(Client{
+		RetryMax:     1,
+		RetryWaitMin: time.Duration(10) * time.Millisecond,
+		RetryWaitMax: time.Duration(50) * time.Millisecond,
+	})
+	// each environment is only checked once
+	for _, env := range []string{"production", "development", "production"} {
+		got, err := d.CheckProvider(ts.URL, "mariadb", env)
+		if err != nil || !got {
+			t.Errorf("CheckProvider() = %v, error = %v", got, err)
+		}
+	}
+	wantRequests := map[string]int{"/mariadb/production": 1, "/mariadb/development": 1}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("CheckProvider() requests = %v, want %v", requests, wantRequests)
+	}
+}
+
+func TestReadFakeOperatorConfig(t *testing.T) {
+	got, err := ReadFakeOperatorConfig("../testdata/dbaas/fake-operator.yml")
+	if err != nil {
+		t.Fatalf("ReadFakeOperatorConfig() error = %v", err)
+	}
+	ts := httptest.NewServer(NewFakeOperator(*got))
+	defer ts.Close()
+	d := NewClient(Client{
+		RetryMax:     1,
+		RetryWaitMin: time.Duration(10) * time.Millisecond,
+		RetryWaitMax: time.Duration(50) * time.Millisecond,
+	})
+	if exists, err := d.CheckProvider(ts.URL, "postgres", "development"); exists || err == nil {
+		t.Errorf("CheckProvider() = %v, error = %v, want no postgres development provider", exists, err)
+	}
+	if exists, err := d.CheckProvider(ts.URL, "mariadb", "development"); !exists || err != nil {
+		t.Errorf("CheckProvider() = %v, error = %v, want a mariadb development provider", exists, err)
+	}
+}
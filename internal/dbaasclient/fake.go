@@ -0,0 +1,98 @@
+package dbaasclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// FakeOperatorConfig is the configuration of a fake dbaas-operator, it can be used to test the
+// dbaas or single decision in builds without a real dbaas-operator
+type FakeOperatorConfig struct {
+	// Healthy is if the health check responds with a 200, or a 503. Defaults to true
+	Healthy *bool `json:"healthy,omitempty"`
+	// Providers are the providers for each dbaas type, eg `mariadb`
+	Providers map[string][]Provider `json:"providers"`
+}
+
+// Provider is a dbaas provider of a fake dbaas-operator, and the dbaas environment it provides
+type Provider struct {
+	Name        string `json:"name"`
+	Environment string `json:"environment"`
+}
+
+// testFakeOperatorConfig is the configuration used by TestDBaaSHTTPServer
+var testFakeOperatorConfig = FakeOperatorConfig{
+	Providers: map[string][]Provider{
+		"mariadb": {
+			{Name: "production", Environment: "production"},
+			{Name: "development", Environment: "development"},
+		},
+		"postgres": {
+			{Name: "production", Environment: "production"},
+			{Name: "development", Environment: "development"},
+		},
+		"mongodb": {
+			{Name: "production", Environment: "production"},
+			{Name: "development", Environment: "development"},
+		},
+		"valkey": {
+			{Name: "production", Environment: "production"},
+		},
+	},
+}
+
+// ReadFakeOperatorConfig reads the configuration for a fake dbaas-operator from a yaml or json file
+func ReadFakeOperatorConfig(file string) (*FakeOperatorConfig, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read %v: %v", file, err)
+	}
+	config := &FakeOperatorConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal %v: %v", file, err)
+	}
+	return config, nil
+}
+
+// NewFakeOperator returns a handler that responds like the http endpoints of the dbaas-operator
+//   - /healthz responds with a 200, or a 503 if the operator is configured as unhealthy
+//   - /{type}/{environment} responds with if there is a provider for the environment
+func NewFakeOperator(config FakeOperatorConfig) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(res http.ResponseWriter, req *http.Request) {
+		if config.Healthy != nil && !*config.Healthy {
+			res.WriteHeader(http.StatusServiceUnavailable)
+		}
+		res.Write([]byte("{}"))
+	})
+	mux.HandleFunc("/", func(res http.ResponseWriter, req *http.Request) {
+		path := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+		providers, ok := config.Providers[path[0]]
+		if !ok || len(path) != 2 {
+			http.NotFound(res, req)
+			return
+		}
+		response := providerResponse{}
+		for _, provider := range providers {
+			if provider.Environment == path[1] {
+				response.Result.Found = true
+			}
+		}
+		if !response.Result.Found {
+			response.Error = fmt.Sprintf("no providers for dbaas environment %s", path[1])
+		}
+		json.NewEncoder(res).Encode(response)
+	})
+	return mux
+}
+
+// TestDBaaSHTTPServer is a test server used to test dbaas-responses
+func TestDBaaSHTTPServer() *httptest.Server {
+	return httptest.NewServer(NewFakeOperator(testFakeOperatorConfig))
+}
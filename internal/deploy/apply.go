@@ -0,0 +1,563 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	k8upv1 "github.com/k8up-io/k8up/v2/api/v1"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+	k8upv1alpha1 "github.com/vshn/k8up/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// typedResource is how a built in kind is applied, listed and deleted using the typed kubernetes client
+type typedResource struct {
+	apply  func(context.Context, *Client, []byte, metav1.ApplyOptions) error
+	get    func(context.Context, *Client, string) (runtime.Object, error)
+	list   func(context.Context, *Client, metav1.ListOptions) ([]string, error)
+	delete func(context.Context, *Client, string, metav1.DeleteOptions) error
+}
+
+// typedResources are all the built in kinds that the templating package generates
+var typedResources = map[schema.GroupVersionKind]typedResource{
+	corev1.SchemeGroupVersion.WithKind("Secret"): {
+		apply: func(ctx context.Context, c *Client, data []byte, opts metav1.ApplyOptions) error {
+			return applyTyped(ctx, data, c.Kubernetes.CoreV1().Secrets(c.Namespace).Apply, opts)
+		},
+		get: func(ctx context.Context, c *Client, name string) (runtime.Object, error) {
+			return c.Kubernetes.CoreV1().Secrets(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, c *Client, opts metav1.ListOptions) ([]string, error) {
+			l, err := c.Kubernetes.CoreV1().Secrets(c.Namespace).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return itemNames(l.Items), nil
+		},
+		delete: func(ctx context.Context, c *Client, name string, opts metav1.DeleteOptions) error {
+			return c.Kubernetes.CoreV1().Secrets(c.Namespace).Delete(ctx, name, opts)
+		},
+	},
+	corev1.SchemeGroupVersion.WithKind("ConfigMap"): {
+		apply: func(ctx context.Context, c *Client, data []byte, opts metav1.ApplyOptions) error {
+			return applyTyped(ctx, data, c.Kubernetes.CoreV1().ConfigMaps(c.Namespace).Apply, opts)
+		},
+		get: func(ctx context.Context, c *Client, name string) (runtime.Object, error) {
+			return c.Kubernetes.CoreV1().ConfigMaps(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, c *Client, opts metav1.ListOptions) ([]string, error) {
+			l, err := c.Kubernetes.CoreV1().ConfigMaps(c.Namespace).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return itemNames(l.Items), nil
+		},
+		delete: func(ctx context.Context, c *Client, name string, opts metav1.DeleteOptions) error {
+			return c.Kubernetes.CoreV1().ConfigMaps(c.Namespace).Delete(ctx, name, opts)
+		},
+	},
+	corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"): {
+		apply: func(ctx context.Context, c *Client, data []byte, opts metav1.ApplyOptions) error {
+			return applyTyped(ctx, data, c.Kubernetes.CoreV1().PersistentVolumeClaims(c.Namespace).Apply, opts)
+		},
+		get: func(ctx context.Context, c *Client, name string) (runtime.Object, error) {
+			return c.Kubernetes.CoreV1().PersistentVolumeClaims(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, c *Client, opts metav1.ListOptions) ([]string, error) {
+			l, err := c.Kubernetes.CoreV1().PersistentVolumeClaims(c.Namespace).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return itemNames(l.Items), nil
+		},
+		delete: func(ctx context.Context, c *Client, name string, opts metav1.DeleteOptions) error {
+			return c.Kubernetes.CoreV1().PersistentVolumeClaims(c.Namespace).Delete(ctx, name, opts)
+		},
+	},
+	corev1.SchemeGroupVersion.WithKind("Service"): {
+		apply: func(ctx context.Context, c *Client, data []byte, opts metav1.ApplyOptions) error {
+			return applyTyped(ctx, data, c.Kubernetes.CoreV1().Services(c.Namespace).Apply, opts)
+		},
+		get: func(ctx context.Context, c *Client, name string) (runtime.Object, error) {
+			return c.Kubernetes.CoreV1().Services(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, c *Client, opts metav1.ListOptions) ([]string, error) {
+			l, err := c.Kubernetes.CoreV1().Services(c.Namespace).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return itemNames(l.Items), nil
+		},
+		delete: func(ctx context.Context, c *Client, name string, opts metav1.DeleteOptions) error {
+			return c.Kubernetes.CoreV1().Services(c.Namespace).Delete(ctx, name, opts)
+		},
+	},
+	networkv1.SchemeGroupVersion.WithKind("NetworkPolicy"): {
+		apply: func(ctx context.Context, c *Client, data []byte, opts metav1.ApplyOptions) error {
+			return applyTyped(ctx, data, c.Kubernetes.NetworkingV1().NetworkPolicies(c.Namespace).Apply, opts)
+		},
+		get: func(ctx context.Context, c *Client, name string) (runtime.Object, error) {
+			return c.Kubernetes.NetworkingV1().NetworkPolicies(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, c *Client, opts metav1.ListOptions) ([]string, error) {
+			l, err := c.Kubernetes.NetworkingV1().NetworkPolicies(c.Namespace).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return itemNames(l.Items), nil
+		},
+		delete: func(ctx context.Context, c *Client, name string, opts metav1.DeleteOptions) error {
+			return c.Kubernetes.NetworkingV1().NetworkPolicies(c.Namespace).Delete(ctx, name, opts)
+		},
+	},
+	appsv1.SchemeGroupVersion.WithKind("Deployment"): {
+		apply: func(ctx context.Context, c *Client, data []byte, opts metav1.ApplyOptions) error {
+			return applyTyped(ctx, data, c.Kubernetes.AppsV1().Deployments(c.Namespace).Apply, opts)
+		},
+		get: func(ctx context.Context, c *Client, name string) (runtime.Object, error) {
+			return c.Kubernetes.AppsV1().Deployments(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, c *Client, opts metav1.ListOptions) ([]string, error) {
+			l, err := c.Kubernetes.AppsV1().Deployments(c.Namespace).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return itemNames(l.Items), nil
+		},
+		delete: func(ctx context.Context, c *Client, name string, opts metav1.DeleteOptions) error {
+			return c.Kubernetes.AppsV1().Deployments(c.Namespace).Delete(ctx, name, opts)
+		},
+	},
+	autoscalingv2.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler"): {
+		apply: func(ctx context.Context, c *Client, data []byte, opts metav1.ApplyOptions) error {
+			return applyTyped(ctx, data, c.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(c.Namespace).Apply, opts)
+		},
+		get: func(ctx context.Context, c *Client, name string) (runtime.Object, error) {
+			return c.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, c *Client, opts metav1.ListOptions) ([]string, error) {
+			l, err := c.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(c.Namespace).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return itemNames(l.Items), nil
+		},
+		delete: func(ctx context.Context, c *Client, name string, opts metav1.DeleteOptions) error {
+			return c.Kubernetes.AutoscalingV2().HorizontalPodAutoscalers(c.Namespace).Delete(ctx, name, opts)
+		},
+	},
+	policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget"): {
+		apply: func(ctx context.Context, c *Client, data []byte, opts metav1.ApplyOptions) error {
+			return applyTyped(ctx, data, c.Kubernetes.PolicyV1().PodDisruptionBudgets(c.Namespace).Apply, opts)
+		},
+		get: func(ctx context.Context, c *Client, name string) (runtime.Object, error) {
+			return c.Kubernetes.PolicyV1().PodDisruptionBudgets(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, c *Client, opts metav1.ListOptions) ([]string, error) {
+			l, err := c.Kubernetes.PolicyV1().PodDisruptionBudgets(c.Namespace).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return itemNames(l.Items), nil
+		},
+		delete: func(ctx context.Context, c *Client, name string, opts metav1.DeleteOptions) error {
+			return c.Kubernetes.PolicyV1().PodDisruptionBudgets(c.Namespace).Delete(ctx, name, opts)
+		},
+	},
+	batchv1.SchemeGroupVersion.WithKind("CronJob"): {
+		apply: func(ctx context.Context, c *Client, data []byte, opts metav1.ApplyOptions) error {
+			return applyTyped(ctx, data, c.Kubernetes.BatchV1().CronJobs(c.Namespace).Apply, opts)
+		},
+		get: func(ctx context.Context, c *Client, name string) (runtime.Object, error) {
+			return c.Kubernetes.BatchV1().CronJobs(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, c *Client, opts metav1.ListOptions) ([]string, error) {
+			l, err := c.Kubernetes.BatchV1().CronJobs(c.Namespace).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return itemNames(l.Items), nil
+		},
+		delete: func(ctx context.Context, c *Client, name string, opts metav1.DeleteOptions) error {
+			return c.Kubernetes.BatchV1().CronJobs(c.Namespace).Delete(ctx, name, opts)
+		},
+	},
+	batchv1.SchemeGroupVersion.WithKind("Job"): {
+		apply: func(ctx context.Context, c *Client, data []byte, opts metav1.ApplyOptions) error {
+			return applyTyped(ctx, data, c.Kubernetes.BatchV1().Jobs(c.Namespace).Apply, opts)
+		},
+		get: func(ctx context.Context, c *Client, name string) (runtime.Object, error) {
+			return c.Kubernetes.BatchV1().Jobs(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, c *Client, opts metav1.ListOptions) ([]string, error) {
+			l, err := c.Kubernetes.BatchV1().Jobs(c.Namespace).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return itemNames(l.Items), nil
+		},
+		delete: func(ctx context.Context, c *Client, name string, opts metav1.DeleteOptions) error {
+			return c.Kubernetes.BatchV1().Jobs(c.Namespace).Delete(ctx, name, opts)
+		},
+	},
+	networkv1.SchemeGroupVersion.WithKind("Ingress"): {
+		apply: func(ctx context.Context, c *Client, data []byte, opts metav1.ApplyOptions) error {
+			return applyTyped(ctx, data, c.Kubernetes.NetworkingV1().Ingresses(c.Namespace).Apply, opts)
+		},
+		get: func(ctx context.Context, c *Client, name string) (runtime.Object, error) {
+			return c.Kubernetes.NetworkingV1().Ingresses(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		list: func(ctx context.Context, c *Client, opts metav1.ListOptions) ([]string, error) {
+			l, err := c.Kubernetes.NetworkingV1().Ingresses(c.Namespace).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			return itemNames(l.Items), nil
+		},
+		delete: func(ctx context.Context, c *Client, name string, opts metav1.DeleteOptions) error {
+			return c.Kubernetes.NetworkingV1().Ingresses(c.Namespace).Delete(ctx, name, opts)
+		},
+	},
+}
+
+// customResources are the custom resources other than dbaas consumers that the templating package generates,
+// these are applied and pruned with the dynamic client
+var customResources = []schema.GroupVersionKind{
+	k8upv1.GroupVersion.WithKind("Schedule"),
+	k8upv1.GroupVersion.WithKind("PreBackupPod"),
+	k8upv1alpha1.GroupVersion.WithKind("Schedule"),
+	k8upv1alpha1.GroupVersion.WithKind("PreBackupPod"),
+	gatewayv1.SchemeGroupVersion.WithKind("HTTPRoute"),
+	{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"},
+}
+
+// customResourceKinds returns the custom resources that are pruned, the consumer kind of each of the provided dbaas types
+// and the other custom resources
+func customResourceKinds(dbaasTypes map[string]servicetypes.DBaaSType) []schema.GroupVersionKind {
+	kinds := []schema.GroupVersionKind{}
+	for _, name := range sortedKeys(dbaasTypes) {
+		dbaasType := dbaasTypes[name]
+		gvk := schema.FromAPIVersionAndKind(dbaasType.ConsumerAPIVersion, dbaasType.ConsumerKind)
+		if !containsGVK(kinds, gvk) {
+			kinds = append(kinds, gvk)
+		}
+	}
+	return append(kinds, customResources...)
+}
+
+// CustomResourceListKinds returns the list kind of each custom resource that is pruned with the provided dbaas types,
+// this is required to create a fake dynamic client
+func CustomResourceListKinds(dbaasTypes map[string]servicetypes.DBaaSType) map[schema.GroupVersionResource]string {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, gvk := range customResourceKinds(dbaasTypes) {
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+		listKinds[gvr] = gvk.Kind + "List"
+	}
+	return listKinds
+}
+
+// dbaasConsumerOrder is where dbaas consumers of any kind are in the apply order
+const dbaasConsumerOrder = "DBaaSConsumer"
+
+// applyOrder is the order kinds are applied in, anything not in this list is applied last.
+// dependencies like secrets, volumes and dbaas consumers are applied before the workloads that use them,
+// and jobs are run before the deployments are rolled out
+var applyOrder = []string{
+	"Secret",
+	"ConfigMap",
+	"PersistentVolumeClaim",
+	"Service",
+	"NetworkPolicy",
+	dbaasConsumerOrder,
+	"Job",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"PodDisruptionBudget",
+	"CronJob",
+	"Ingress",
+	"Certificate",
+	"HTTPRoute",
+	"Schedule",
+	"PreBackupPod",
+}
+
+// ReadManifests reads all the yaml manifests in the provided files or directories, directories are read recursively.
+// Files can contain multiple documents separated by `---`
+func ReadManifests(paths ...string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || (filepath.Ext(file) != ".yaml" && filepath.Ext(file) != ".yml") {
+				return nil
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("couldn't read manifest %s: %v", file, err)
+			}
+			decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+			for {
+				obj := &unstructured.Unstructured{}
+				if err := decoder.Decode(&obj.Object); err != nil {
+					if errors.Is(err, io.EOF) {
+						break
+					}
+					return fmt.Errorf("couldn't decode manifest %s: %v", file, err)
+				}
+				if len(obj.Object) == 0 {
+					// empty documents are skipped
+					continue
+				}
+				if obj.GetKind() == "" || obj.GetName() == "" {
+					return fmt.Errorf("manifest %s contains a resource without a kind or name", file)
+				}
+				objects = append(objects, obj)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
+// Apply server-side applies the provided objects into the namespace using the build-deploy-tool field manager.
+// It returns the `Kind/name` of each applied object, in the order they were applied
+func (c *Client) Apply(ctx context.Context, objects []*unstructured.Unstructured) ([]string, error) {
+	var applied []string
+	opts := metav1.ApplyOptions{FieldManager: FieldManager, Force: true}
+	for _, obj := range sortForApply(objects) {
+		obj = c.prepare(obj)
+		gvk := obj.GroupVersionKind()
+		if c.Debug {
+			fmt.Printf("Applying %s/%s\n", gvk.Kind, obj.GetName())
+		}
+		if tr, ok := typedResources[gvk]; ok {
+			data, err := json.Marshal(obj.Object)
+			if err != nil {
+				return applied, err
+			}
+			if err := tr.apply(ctx, c, data, opts); err != nil {
+				return applied, fmt.Errorf("couldn't apply %s/%s: %v", gvk.Kind, obj.GetName(), err)
+			}
+		} else {
+			gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+			_, err := c.Dynamic.Resource(gvr).Namespace(c.Namespace).Apply(ctx, obj.GetName(), obj, opts)
+			if err != nil {
+				return applied, fmt.Errorf("couldn't apply %s/%s: %v", gvk.Kind, obj.GetName(), err)
+			}
+		}
+		applied = append(applied, fmt.Sprintf("%s/%s", gvk.Kind, obj.GetName()))
+	}
+	return applied, nil
+}
+
+// Prune removes any resources in the namespace that are managed by build-deploy-tool for this environment,
+// but are not in the provided objects. PersistentVolumeClaims are only removed if pruneVolumes is true, as removing them
+// will remove any data stored in them.
+// It returns the `Kind/name` of each removed object
+func (c *Client) Prune(ctx context.Context, objects []*unstructured.Unstructured, pruneVolumes bool) ([]string, error) {
+	stale, err := c.staleResources(ctx, objects, pruneVolumes)
+	if err != nil {
+		return nil, err
+	}
+	propagation := metav1.DeletePropagationBackground
+	deleteOpts := metav1.DeleteOptions{PropagationPolicy: &propagation}
+	var pruned []string
+	for _, s := range stale {
+		if c.Debug {
+			fmt.Printf("Pruning %s/%s\n", s.gvk.Kind, s.name)
+		}
+		var err error
+		if tr, ok := typedResources[s.gvk]; ok {
+			err = tr.delete(ctx, c, s.name, deleteOpts)
+		} else {
+			gvr, _ := meta.UnsafeGuessKindToResource(s.gvk)
+			err = c.Dynamic.Resource(gvr).Namespace(c.Namespace).Delete(ctx, s.name, deleteOpts)
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			return pruned, fmt.Errorf("couldn't prune %s/%s: %v", s.gvk.Kind, s.name, err)
+		}
+		pruned = append(pruned, fmt.Sprintf("%s/%s", s.gvk.Kind, s.name))
+	}
+	return pruned, nil
+}
+
+// staleResource is a resource in the namespace that is managed by build-deploy-tool, but is no longer generated
+type staleResource struct {
+	gvk  schema.GroupVersionKind
+	name string
+}
+
+// staleResources returns the resources managed by build-deploy-tool for this environment that are not in the provided objects.
+// The built in kinds are returned in reverse apply order, so workloads are removed before the things they depend on
+func (c *Client) staleResources(ctx context.Context, objects []*unstructured.Unstructured, includeVolumes bool) ([]staleResource, error) {
+	if c.Environment == "" {
+		return nil, fmt.Errorf("unable to find stale resources, the environment name is not set")
+	}
+	generated := map[schema.GroupKind]sets.Set[string]{}
+	// the consumers of dbaas types loaded from a file are pruned the same as the built in ones
+	dbaasTypes := c.DBaaSTypes
+	if dbaasTypes == nil {
+		dbaasTypes = servicetypes.DBaaSTypes
+	}
+	customKinds := customResourceKinds(dbaasTypes)
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		if _, ok := generated[gvk.GroupKind()]; !ok {
+			generated[gvk.GroupKind()] = sets.New[string]()
+		}
+		generated[gvk.GroupKind()].Insert(obj.GetName())
+		if _, ok := typedResources[gvk]; !ok && !containsGVK(customKinds, gvk) {
+			customKinds = append(customKinds, gvk)
+		}
+	}
+	listOpts := metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			"app.kubernetes.io/managed-by": ManagedBy,
+			"lagoon.sh/environment":        c.Environment,
+		}).String(),
+	}
+
+	var stale []staleResource
+	typedKinds := make([]schema.GroupVersionKind, 0, len(typedResources))
+	for gvk := range typedResources {
+		typedKinds = append(typedKinds, gvk)
+	}
+	sort.SliceStable(typedKinds, func(i, j int) bool {
+		return kindOrder(typedKinds[i].Kind) > kindOrder(typedKinds[j].Kind)
+	})
+	for _, gvk := range typedKinds {
+		if gvk.Kind == "PersistentVolumeClaim" && !includeVolumes {
+			continue
+		}
+		names, err := typedResources[gvk].list(ctx, c, listOpts)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't list %s resources: %v", gvk.Kind, err)
+		}
+		for _, name := range names {
+			if !generated[gvk.GroupKind()].Has(name) {
+				stale = append(stale, staleResource{gvk: gvk, name: name})
+			}
+		}
+	}
+	for _, gvk := range customKinds {
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+		l, err := c.Dynamic.Resource(gvr).Namespace(c.Namespace).List(ctx, listOpts)
+		if err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				// the custom resource isn't installed in this cluster, so there is nothing to prune
+				continue
+			}
+			return nil, fmt.Errorf("couldn't list %s resources: %v", gvk.Kind, err)
+		}
+		for _, item := range l.Items {
+			if !generated[gvk.GroupKind()].Has(item.GetName()) {
+				stale = append(stale, staleResource{gvk: gvk, name: item.GetName()})
+			}
+		}
+	}
+	return stale, nil
+}
+
+// prepare returns a copy of a generated object ready to be applied into the namespace
+func (c *Client) prepare(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	obj = obj.DeepCopy()
+	obj.SetNamespace(c.Namespace)
+	// the templates include empty status and creation timestamps, these are never applied
+	unstructured.RemoveNestedField(obj.Object, "status")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	return obj
+}
+
+// applyTyped decodes the object into the apply configuration used by the typed client apply function
+func applyTyped[T any, R any](ctx context.Context, data []byte, apply func(context.Context, *T, metav1.ApplyOptions) (R, error), opts metav1.ApplyOptions) error {
+	ac := new(T)
+	if err := json.Unmarshal(data, ac); err != nil {
+		return err
+	}
+	_, err := apply(ctx, ac, opts)
+	return err
+}
+
+// itemNames returns the names of the items in a typed list
+func itemNames[T any, PT interface {
+	*T
+	GetName() string
+}](items []T) []string {
+	names := []string{}
+	for idx := range items {
+		names = append(names, PT(&items[idx]).GetName())
+	}
+	return names
+}
+
+func sortForApply(objects []*unstructured.Unstructured) []*unstructured.Unstructured {
+	sorted := append([]*unstructured.Unstructured{}, objects...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return objectOrder(sorted[i]) < objectOrder(sorted[j])
+	})
+	return sorted
+}
+
+// objectOrder returns where an object is in the apply order, dbaas consumers are identified by their service type label
+// as the consumer kinds depend on the dbaas types the remote supports
+func objectOrder(obj *unstructured.Unstructured) int {
+	if strings.HasSuffix(obj.GetLabels()["lagoon.sh/service-type"], "-dbaas") {
+		return kindOrder(dbaasConsumerOrder)
+	}
+	return kindOrder(obj.GetKind())
+}
+
+func kindOrder(kind string) int {
+	for idx, k := range applyOrder {
+		if strings.EqualFold(k, kind) {
+			return idx
+		}
+	}
+	return len(applyOrder)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsGVK(gvks []schema.GroupVersionKind, gvk schema.GroupVersionKind) bool {
+	for _, g := range gvks {
+		if g == gvk {
+			return true
+		}
+	}
+	return false
+}
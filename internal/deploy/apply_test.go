@@ -0,0 +1,199 @@
+package deploy
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	// changes the testing to source from root so paths to test resources must be defined from repo root
+	_ "github.com/uselagoon/build-deploy-tool/internal/testing"
+)
+
+func managedLabels(environment string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/managed-by": "build-deploy-tool",
+		"lagoon.sh/environment":        environment,
+	}
+}
+
+func newDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), CustomResourceListKinds(servicetypes.DBaaSTypes), objects...)
+}
+
+func TestApplyAndPrune(t *testing.T) {
+	tests := []struct {
+		name           string
+		paths          []string
+		pruneVolumes   bool
+		dbaasTypesFile string
+		existing       []runtime.Object
+		existingCustom []runtime.Object
+		wantApplied    []string
+		wantPruned     []string
+		wantRemaining  []string
+	}{
+		{
+			name:  "test1 apply into empty namespace",
+			paths: []string{"internal/testdata/basic/service-templates/test1-basic-deployment"},
+			wantApplied: []string{
+				"Secret/lagoon-private-registry-dockerhub",
+				"Secret/lagoon-private-registry-my-custom-registry",
+				"Secret/lagoon-private-registry-my-hardcode-registry",
+				"Secret/lagoon-private-registry-my-other-registry",
+				"Service/node",
+				"Deployment/node",
+			},
+			wantRemaining: []string{"Deployment/node"},
+		},
+		{
+			name:  "test2 prune resources no longer generated",
+			paths: []string{"internal/testdata/basic/service-templates/test1-basic-deployment"},
+			existing: []runtime.Object{
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "node", Namespace: "example-project-main", Labels: managedLabels("main")}},
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "example-project-main", Labels: managedLabels("main")}},
+				&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "example-project-main", Labels: managedLabels("main")}},
+				// not managed by the build-deploy-tool
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "custom", Namespace: "example-project-main"}},
+				// managed, but for a different environment
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "example-project-main", Labels: managedLabels("other")}},
+				// volumes are not pruned by default
+				&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "example-project-main", Labels: managedLabels("main")}},
+			},
+			existingCustom: []runtime.Object{
+				&unstructured.Unstructured{Object: map[string]interface{}{
+					"apiVersion": "mariadb.amazee.io/v1",
+					"kind":       "MariaDBConsumer",
+					"metadata": map[string]interface{}{
+						"name":      "mariadb",
+						"namespace": "example-project-main",
+						"labels": map[string]interface{}{
+							"app.kubernetes.io/managed-by": "build-deploy-tool",
+							"lagoon.sh/environment":        "main",
+						},
+					},
+				}},
+			},
+			wantApplied: []string{
+				"Secret/lagoon-private-registry-dockerhub",
+				"Secret/lagoon-private-registry-my-custom-registry",
+				"Secret/lagoon-private-registry-my-hardcode-registry",
+				"Secret/lagoon-private-registry-my-other-registry",
+				"Service/node",
+				"Deployment/node",
+			},
+			wantPruned: []string{
+				"Deployment/redis",
+				"MariaDBConsumer/mariadb",
+				"Service/redis",
+			},
+			wantRemaining: []string{"Deployment/custom", "Deployment/node", "Deployment/other", "PersistentVolumeClaim/redis"},
+		},
+		{
+			name:         "test3 prune volumes",
+			paths:        []string{"internal/testdata/basic/ingress-templates/test25-pathroutes"},
+			pruneVolumes: true,
+			existing: []runtime.Object{
+				&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "example-project-main", Labels: managedLabels("main")}},
+			},
+			wantApplied: []string{"Ingress/a.example.com"},
+			wantPruned:  []string{"PersistentVolumeClaim/redis"},
+		},
+		{
+			name:           "test4 prune consumers of dbaas types from a file",
+			paths:          []string{"internal/testdata/basic/ingress-templates/test25-pathroutes"},
+			dbaasTypesFile: "internal/testdata/dbaas/dbaas-types.yml",
+			existingCustom: []runtime.Object{
+				&unstructured.Unstructured{Object: map[string]interface{}{
+					"apiVersion": "valkey.example.com/v1",
+					"kind":       "ValkeyConsumer",
+					"metadata": map[string]interface{}{
+						"name":      "valkey",
+						"namespace": "example-project-main",
+						"labels": map[string]interface{}{
+							"app.kubernetes.io/managed-by": "build-deploy-tool",
+							"lagoon.sh/environment":        "main",
+						},
+					},
+				}},
+			},
+			wantApplied: []string{"Ingress/a.example.com"},
+			wantPruned:  []string{"ValkeyConsumer/valkey"},
+		},
+		{
+			name:  "test5 prune the service of a canary that was removed",
+			paths: []string{"internal/testdata/basic/ingress-templates/test33-canary"},
+			existing: []runtime.Object{
+				&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "canary-0a8426cb", Namespace: "example-project-main", Labels: managedLabels("main")}},
+				&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "canary-5d1e2c8f", Namespace: "example-project-main", Labels: managedLabels("main")}},
+			},
+			wantApplied: []string{
+				"Service/canary-0a8426cb",
+				"Ingress/a.example.com",
+				"Ingress/a.example.com-canary",
+				"Ingress/b.example.com",
+				"Ingress/b.example.com-canary",
+			},
+			wantPruned: []string{"Service/canary-5d1e2c8f"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbaasTypes, err := servicetypes.GetDBaaSTypes(tt.dbaasTypesFile, false)
+			if err != nil {
+				t.Fatalf("GetDBaaSTypes() error = %v", err)
+			}
+			c := &Client{
+				Kubernetes:  fake.NewClientset(tt.existing...),
+				Dynamic:     dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), CustomResourceListKinds(dbaasTypes), tt.existingCustom...),
+				Namespace:   "example-project-main",
+				Environment: "main",
+				DBaaSTypes:  dbaasTypes,
+			}
+			objects, err := ReadManifests(tt.paths...)
+			if err != nil {
+				t.Fatalf("ReadManifests() error = %v", err)
+			}
+			applied, err := c.Apply(context.TODO(), objects)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if !reflect.DeepEqual(applied, tt.wantApplied) {
+				t.Errorf("Apply() = %v, want %v", applied, tt.wantApplied)
+			}
+			pruned, err := c.Prune(context.TODO(), objects, tt.pruneVolumes)
+			if err != nil {
+				t.Fatalf("Prune() error = %v", err)
+			}
+			sort.Strings(pruned)
+			if len(pruned) != len(tt.wantPruned) || (len(pruned) > 0 && !reflect.DeepEqual(pruned, tt.wantPruned)) {
+				t.Errorf("Prune() = %v, want %v", pruned, tt.wantPruned)
+			}
+			remaining := []string{}
+			deployments, _ := c.Kubernetes.AppsV1().Deployments(c.Namespace).List(context.TODO(), metav1.ListOptions{})
+			for _, d := range deployments.Items {
+				remaining = append(remaining, "Deployment/"+d.Name)
+				if d.Name == "node" && d.ManagedFields[0].Manager != FieldManager {
+					t.Errorf("Apply() field manager = %v, want %v", d.ManagedFields[0].Manager, FieldManager)
+				}
+			}
+			pvcs, _ := c.Kubernetes.CoreV1().PersistentVolumeClaims(c.Namespace).List(context.TODO(), metav1.ListOptions{})
+			for _, p := range pvcs.Items {
+				remaining = append(remaining, "PersistentVolumeClaim/"+p.Name)
+			}
+			sort.Strings(remaining)
+			if len(remaining) != len(tt.wantRemaining) || (len(remaining) > 0 && !reflect.DeepEqual(remaining, tt.wantRemaining)) {
+				t.Errorf("remaining resources = %v, want %v", remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	networkv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// CanaryLabel is on the ingress-nginx canary ingress, or the HTTPRoute, of a route that has a canary
+const CanaryLabel = "lagoon.sh/canary"
+
+const canaryWeightAnnotation = "nginx.ingress.kubernetes.io/canary-weight"
+
+var httpRouteResource = gatewayv1.SchemeGroupVersion.WithResource("httproutes")
+
+// CanaryWeight returns the percentage of requests for the route with the provided domain that are sent to its canary
+func (c *Client) CanaryWeight(ctx context.Context, domain string) (int, error) {
+	ingress, err := c.canaryIngress(ctx, domain)
+	if err != nil {
+		return 0, err
+	}
+	if ingress != nil {
+		weight, err := strconv.Atoi(ingress.Annotations[canaryWeightAnnotation])
+		if err != nil {
+			return 0, fmt.Errorf("couldn't read the canary weight of ingress %s: %v", ingress.Name, err)
+		}
+		return weight, nil
+	}
+	route, err := c.canaryHTTPRoute(ctx, domain)
+	if err != nil {
+		return 0, err
+	}
+	rules, idx, err := canaryRule(route)
+	if err != nil {
+		return 0, err
+	}
+	backendRefs := rules[idx].(map[string]interface{})["backendRefs"].([]interface{})
+	weight, _, err := unstructured.NestedInt64(backendRefs[1].(map[string]interface{}), "weight")
+	if err != nil {
+		return 0, fmt.Errorf("couldn't read the canary weight of httproute %s: %v", route.GetName(), err)
+	}
+	return int(weight), nil
+}
+
+// SetCanaryWeight changes the percentage of requests for the route with the provided domain that are sent to its canary.
+// Server-side applying the templates of a build sets the weight back to the one in the .lagoon.yml, but a client-side
+// kubectl apply only changes it when the weight in the .lagoon.yml changes, as the last applied weight is the same
+func (c *Client) SetCanaryWeight(ctx context.Context, domain string, weight int) error {
+	if weight < 0 || weight > 100 {
+		return fmt.Errorf("canary weight %d is not valid, it must be from 0 to 100", weight)
+	}
+	ingress, err := c.canaryIngress(ctx, domain)
+	if err != nil {
+		return err
+	}
+	if ingress != nil {
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]string{
+					canaryWeightAnnotation: strconv.Itoa(weight),
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = c.Kubernetes.NetworkingV1().Ingresses(c.Namespace).Patch(ctx, ingress.Name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+		if err != nil {
+			return fmt.Errorf("couldn't patch ingress %s: %v", ingress.Name, err)
+		}
+		return nil
+	}
+	route, err := c.canaryHTTPRoute(ctx, domain)
+	if err != nil {
+		return err
+	}
+	rules, idx, err := canaryRule(route)
+	if err != nil {
+		return err
+	}
+	backendRefs := rules[idx].(map[string]interface{})["backendRefs"].([]interface{})
+	backendRefs[0].(map[string]interface{})["weight"] = int64(100 - weight)
+	backendRefs[1].(map[string]interface{})["weight"] = int64(weight)
+	if err := unstructured.SetNestedSlice(route.Object, rules, "spec", "rules"); err != nil {
+		return err
+	}
+	_, err = c.Dynamic.Resource(httpRouteResource).Namespace(c.Namespace).Update(ctx, route, metav1.UpdateOptions{FieldManager: FieldManager})
+	if err != nil {
+		return fmt.Errorf("couldn't update httproute %s: %v", route.GetName(), err)
+	}
+	return nil
+}
+
+// canaryIngress returns the canary ingress for the domain, or nil if there isn't one
+func (c *Client) canaryIngress(ctx context.Context, domain string) (*networkv1.Ingress, error) {
+	ingresses, err := c.Kubernetes.NetworkingV1().Ingresses(c.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=true", CanaryLabel),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list ingresses: %v", err)
+	}
+	for idx, ingress := range ingresses.Items {
+		for _, rule := range ingress.Spec.Rules {
+			if rule.Host == domain {
+				return &ingresses.Items[idx], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// canaryHTTPRoute returns the HTTPRoute with a canary for the domain
+func (c *Client) canaryHTTPRoute(ctx context.Context, domain string) (*unstructured.Unstructured, error) {
+	routes, err := c.Dynamic.Resource(httpRouteResource).Namespace(c.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=true", CanaryLabel),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find a canary for %s: %v", domain, err)
+	}
+	for idx, route := range routes.Items {
+		hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+		for _, hostname := range hostnames {
+			if hostname == domain {
+				return &routes.Items[idx], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("couldn't find a canary for %s", domain)
+}
+
+// canaryRule returns the rules of the HTTPRoute, and the index of the rule that splits the requests between the service and the canary
+func canaryRule(route *unstructured.Unstructured) ([]interface{}, int, error) {
+	rules, _, err := unstructured.NestedSlice(route.Object, "spec", "rules")
+	if err != nil {
+		return nil, 0, fmt.Errorf("couldn't read the rules of httproute %s: %v", route.GetName(), err)
+	}
+	for idx, rule := range rules {
+		backendRefs, _, _ := unstructured.NestedSlice(rule.(map[string]interface{}), "backendRefs")
+		if len(backendRefs) == 2 {
+			return rules, idx, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("httproute %s doesn't have a canary", route.GetName())
+}
@@ -0,0 +1,123 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+
+	networkv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func canaryHTTPRoute(domain string, weight int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata": map[string]interface{}{
+			"name":      domain,
+			"namespace": "example-project-main",
+			"labels": map[string]interface{}{
+				CanaryLabel: "true",
+			},
+		},
+		"spec": map[string]interface{}{
+			"hostnames": []interface{}{domain},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"backendRefs": []interface{}{
+						map[string]interface{}{"name": "nginx", "port": int64(8080), "weight": 100 - weight},
+						map[string]interface{}{"name": "nginx", "namespace": "example-project-release", "port": int64(8080), "weight": weight},
+					},
+				},
+				map[string]interface{}{
+					"backendRefs": []interface{}{
+						map[string]interface{}{"name": "node", "port": int64(3000)},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestCanaryWeight(t *testing.T) {
+	tests := []struct {
+		name           string
+		domain         string
+		existing       []runtime.Object
+		existingCustom []runtime.Object
+		setWeight      int
+		wantWeight     int
+		wantErr        bool
+	}{
+		{
+			name:   "test1 ingress-nginx canary",
+			domain: "www.example.com",
+			existing: []runtime.Object{
+				&networkv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "www.example.com-canary",
+						Namespace: "example-project-main",
+						Labels:    map[string]string{CanaryLabel: "true"},
+						Annotations: map[string]string{
+							"nginx.ingress.kubernetes.io/canary":        "true",
+							"nginx.ingress.kubernetes.io/canary-weight": "10",
+						},
+					},
+					Spec: networkv1.IngressSpec{
+						Rules: []networkv1.IngressRule{{Host: "www.example.com"}},
+					},
+				},
+			},
+			setWeight:  30,
+			wantWeight: 10,
+		},
+		{
+			name:           "test2 gateway api canary",
+			domain:         "www.example.com",
+			existingCustom: []runtime.Object{canaryHTTPRoute("www.example.com", 25)},
+			setWeight:      50,
+			wantWeight:     25,
+		},
+		{
+			name:           "test3 no canary for the route",
+			domain:         "other.example.com",
+			existingCustom: []runtime.Object{canaryHTTPRoute("www.example.com", 25)},
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				Kubernetes:  fake.NewClientset(tt.existing...),
+				Dynamic:     newDynamicClient(tt.existingCustom...),
+				Namespace:   "example-project-main",
+				Environment: "main",
+			}
+			got, err := c.CanaryWeight(context.TODO(), tt.domain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CanaryWeight() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.wantWeight {
+				t.Errorf("CanaryWeight() = %v, want %v", got, tt.wantWeight)
+			}
+			if err := c.SetCanaryWeight(context.TODO(), tt.domain, tt.setWeight); err != nil {
+				t.Fatalf("SetCanaryWeight() error = %v", err)
+			}
+			got, err = c.CanaryWeight(context.TODO(), tt.domain)
+			if err != nil {
+				t.Fatalf("CanaryWeight() error = %v", err)
+			}
+			if got != tt.setWeight {
+				t.Errorf("CanaryWeight() after SetCanaryWeight() = %v, want %v", got, tt.setWeight)
+			}
+			if err := c.SetCanaryWeight(context.TODO(), tt.domain, 101); err == nil {
+				t.Errorf("SetCanaryWeight() with a weight of 101 should fail")
+			}
+		})
+	}
+}
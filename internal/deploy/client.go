@@ -0,0 +1,62 @@
+package deploy
+
+import (
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// FieldManager is the field manager used when server-side applying resources
+const FieldManager = "build-deploy-tool"
+
+// ManagedBy is the value of the `app.kubernetes.io/managed-by` label on every resource the templating package generates
+const ManagedBy = "build-deploy-tool"
+
+// Client holds the kubernetes clients used to deploy the resources for an environment.
+// Kubernetes handles the built in types, and Dynamic handles any custom resources (dbaas consumers, k8up schedules etc)
+type Client struct {
+	Kubernetes  kubernetes.Interface
+	Dynamic     dynamic.Interface
+	Namespace   string
+	Environment string
+	// DBaaSTypes are the dbaas types whose consumers are pruned, the built in types are used if this isn't set
+	DBaaSTypes map[string]servicetypes.DBaaSType
+	Debug      bool
+}
+
+// NewClient returns a client using the KUBECONFIG if set, or the deployer token from within the cluster
+func NewClient(namespace, environment string, debug bool) (*Client, error) {
+	restCfg, err := lagoon.GetK8sConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Kubernetes:  clientset,
+		Dynamic:     dynamicClient,
+		Namespace:   namespace,
+		Environment: environment,
+		Debug:       debug,
+	}, nil
+}
+
+// isTransientError returns true if an error from the api server is worth retrying, like a timeout or the api server
+// being briefly unavailable. These are retried while polling instead of failing the build
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	return apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) || apierrors.IsUnexpectedServerError(err) ||
+		utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+}
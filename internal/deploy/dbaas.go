@@ -0,0 +1,105 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// dbaasFailedAnnotation is set on a consumer by the dbaas-operator if it is unable to provision it
+const dbaasFailedAnnotation = "dbaas.amazee.io/failed"
+
+// DBaaSOptions are the options for waiting on dbaas consumers
+type DBaaSOptions struct {
+	// Timeout is how long to wait for the consumer to be provisioned
+	Timeout time.Duration
+	// Interval is how often the consumer is checked
+	Interval time.Duration
+}
+
+// DBaaSVariables waits for the consumer of a dbaas service to be provisioned, then returns the variables for the service
+// from the consumer. The variables are prefixed with the uppercased service name, eg `MARIADB_HOST`
+func (c *Client) DBaaSVariables(ctx context.Context, service string, dbaasType servicetypes.DBaaSType, opts DBaaSOptions) (map[string]string, error) {
+	gvk := schema.FromAPIVersionAndKind(dbaasType.ConsumerAPIVersion, dbaasType.ConsumerKind)
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	var consumer *unstructured.Unstructured
+	err := wait.PollUntilContextTimeout(ctx, opts.Interval, opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		u, err := c.Dynamic.Resource(gvr).Namespace(c.Namespace).Get(ctx, service, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if u.GetAnnotations()[dbaasFailedAnnotation] == "true" {
+			return false, fmt.Errorf("failed to provision a database for %s. Contact your support team to investigate", service)
+		}
+		consumer = u
+		for _, envVar := range dbaasType.EnvVars {
+			if _, ok := consumerValue(u, envVar.Path); !ok && !envVar.Optional {
+				if c.Debug {
+					fmt.Printf("Service for %s not available yet, waiting for %s\n", service, envVar.Path)
+				}
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if wait.Interrupted(err) {
+		return nil, fmt.Errorf("timeout of %s for %s creation reached", opts.Timeout, service)
+	}
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.ToUpper(strings.ReplaceAll(service, "-", "_"))
+	variables := map[string]string{}
+	for _, envVar := range dbaasType.EnvVars {
+		if value, ok := consumerValue(consumer, envVar.Path); ok {
+			variables[fmt.Sprintf("%s_%s", prefix, envVar.Name)] = value
+		}
+	}
+	return variables, nil
+}
+
+// PatchEnvironmentVariables adds the provided variables to the lagoon-env configmap
+func (c *Client) PatchEnvironmentVariables(ctx context.Context, variables map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{"data": variables})
+	if err != nil {
+		return err
+	}
+	_, err = c.Kubernetes.CoreV1().ConfigMaps(c.Namespace).Patch(ctx, "lagoon-env", types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	if err != nil {
+		return fmt.Errorf("couldn't patch configmap lagoon-env: %v", err)
+	}
+	return nil
+}
+
+// consumerValue returns the value at the dot separated path in the consumer as a string.
+// Lists are comma separated, and empty values are treated as not set
+func consumerValue(consumer *unstructured.Unstructured, path string) (string, bool) {
+	value, found, err := unstructured.NestedFieldNoCopy(consumer.Object, strings.Split(path, ".")...)
+	if err != nil || !found || value == nil {
+		return "", false
+	}
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []interface{}:
+		values := []string{}
+		for _, i := range v {
+			values = append(values, fmt.Sprint(i))
+		}
+		s = strings.Join(values, ",")
+	default:
+		s = fmt.Sprint(v)
+	}
+	return s, s != ""
+}
@@ -0,0 +1,113 @@
+package deploy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func mariadbConsumer(annotations map[string]interface{}, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "mariadb.amazee.io/v1",
+		"kind":       "MariaDBConsumer",
+		"metadata": map[string]interface{}{
+			"name":        "mariadb",
+			"namespace":   "example-project-main",
+			"annotations": annotations,
+		},
+		"spec": spec,
+	}}
+}
+
+func TestDBaaSVariables(t *testing.T) {
+	provisioned := map[string]interface{}{
+		"consumer": map[string]interface{}{
+			"database": "example-main-abc",
+			"username": "example-main-def",
+			"password": "secret",
+			"services": map[string]interface{}{
+				"primary":  "mariadb-abc.example-project-main.svc",
+				"replicas": []interface{}{"replica-1.svc", "replica-2.svc"},
+			},
+		},
+		"provider": map[string]interface{}{
+			"port": int64(3306),
+		},
+	}
+	tests := []struct {
+		name     string
+		consumer *unstructured.Unstructured
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "test1 provisioned consumer",
+			consumer: mariadbConsumer(nil, provisioned),
+			want: map[string]string{
+				"MARIADB_HOST":              "mariadb-abc.example-project-main.svc",
+				"MARIADB_USERNAME":          "example-main-def",
+				"MARIADB_PASSWORD":          "secret",
+				"MARIADB_DATABASE":          "example-main-abc",
+				"MARIADB_PORT":              "3306",
+				"MARIADB_READREPLICA_HOSTS": "replica-1.svc,replica-2.svc",
+			},
+		},
+		{
+			name:     "test2 consumer not provisioned before the timeout",
+			consumer: mariadbConsumer(nil, map[string]interface{}{"consumer": map[string]interface{}{}}),
+			wantErr:  true,
+		},
+		{
+			name:     "test3 consumer failed",
+			consumer: mariadbConsumer(map[string]interface{}{"dbaas.amazee.io/failed": "true"}, map[string]interface{}{}),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{
+				Kubernetes: fake.NewSimpleClientset(),
+				Dynamic:    newDynamicClient(tt.consumer),
+				Namespace:  "example-project-main",
+			}
+			got, err := client.DBaaSVariables(context.TODO(), "mariadb", servicetypes.DBaaSTypes["mariadb"], DBaaSOptions{
+				Timeout:  50 * time.Millisecond,
+				Interval: 10 * time.Millisecond,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DBaaSVariables() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DBaaSVariables() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchEnvironmentVariables(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "lagoon-env", Namespace: "example-project-main"},
+		Data:       map[string]string{"LAGOON_ENVIRONMENT": "main"},
+	}
+	client := &Client{
+		Kubernetes: fake.NewSimpleClientset([]runtime.Object{configMap}...),
+		Namespace:  "example-project-main",
+	}
+	if err := client.PatchEnvironmentVariables(context.TODO(), map[string]string{"MARIADB_HOST": "mariadb"}); err != nil {
+		t.Fatalf("PatchEnvironmentVariables() error = %v", err)
+	}
+	got, _ := client.Kubernetes.CoreV1().ConfigMaps("example-project-main").Get(context.TODO(), "lagoon-env", metav1.GetOptions{})
+	want := map[string]string{"LAGOON_ENVIRONMENT": "main", "MARIADB_HOST": "mariadb"}
+	if !reflect.DeepEqual(got.Data, want) {
+		t.Errorf("PatchEnvironmentVariables() = %v, want %v", got.Data, want)
+	}
+}
@@ -0,0 +1,215 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// DiffAdded is an object that is generated, but does not exist in the namespace
+	DiffAdded = "added"
+	// DiffChanged is an object that exists in the namespace, but has fields that differ from what is generated
+	DiffChanged = "changed"
+	// DiffRemoved is an object managed by build-deploy-tool in the namespace that is no longer generated
+	DiffRemoved = "removed"
+)
+
+// ObjectDiff is the difference between a generated object and what is in the namespace
+type ObjectDiff struct {
+	Kind   string      `json:"kind"`
+	Name   string      `json:"name"`
+	Action string      `json:"action"`
+	Fields []FieldDiff `json:"fields,omitempty"`
+}
+
+// FieldDiff is a single field that differs between a generated object and what is in the namespace
+type FieldDiff struct {
+	Path string `json:"path"`
+	Live string `json:"live"`
+	New  string `json:"new"`
+}
+
+// Diff compares the provided objects with what is currently in the namespace.
+// Only the fields that are set in the generated objects are compared, as these are the fields that an apply will change.
+// Any objects managed by build-deploy-tool for this environment that are no longer generated are returned as removed,
+// persistent volume claims are only included if includeVolumes is true
+func (c *Client) Diff(ctx context.Context, objects []*unstructured.Unstructured, includeVolumes bool) ([]ObjectDiff, error) {
+	var diffs []ObjectDiff
+	for _, obj := range sortForApply(objects) {
+		obj = c.prepare(obj)
+		gvk := obj.GroupVersionKind()
+		var live map[string]interface{}
+		if tr, ok := typedResources[gvk]; ok {
+			liveObj, err := tr.get(ctx, c, obj.GetName())
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("couldn't get %s/%s: %v", gvk.Kind, obj.GetName(), err)
+			}
+			if err == nil {
+				live, err = runtime.DefaultUnstructuredConverter.ToUnstructured(liveObj)
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+			liveObj, err := c.Dynamic.Resource(gvr).Namespace(c.Namespace).Get(ctx, obj.GetName(), metav1.GetOptions{})
+			if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+				return nil, fmt.Errorf("couldn't get %s/%s: %v", gvk.Kind, obj.GetName(), err)
+			}
+			if err == nil {
+				live = liveObj.Object
+			}
+		}
+		if live == nil {
+			diffs = append(diffs, ObjectDiff{Kind: gvk.Kind, Name: obj.GetName(), Action: DiffAdded})
+			continue
+		}
+		fields := diffFields("", obj.Object, live)
+		if gvk.Kind == "Secret" {
+			// never show the values of secrets
+			for idx := range fields {
+				if strings.HasPrefix(fields[idx].Path, "data") || strings.HasPrefix(fields[idx].Path, "stringData") {
+					fields[idx].Live = "(redacted)"
+					fields[idx].New = "(redacted)"
+				}
+			}
+		}
+		if len(fields) > 0 {
+			diffs = append(diffs, ObjectDiff{Kind: gvk.Kind, Name: obj.GetName(), Action: DiffChanged, Fields: fields})
+		}
+	}
+	stale, err := c.staleResources(ctx, objects, includeVolumes)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stale {
+		diffs = append(diffs, ObjectDiff{Kind: s.gvk.Kind, Name: s.name, Action: DiffRemoved})
+	}
+	return diffs, nil
+}
+
+// diffFields walks the generated object and returns any fields where the live object differs
+func diffFields(path string, desired, live interface{}) []FieldDiff {
+	desired = normalize(desired)
+	live = normalize(live)
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			if len(d) == 0 {
+				return nil
+			}
+			return []FieldDiff{{Path: path, Live: stringify(live), New: stringify(desired)}}
+		}
+		var fields []FieldDiff
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fields = append(fields, diffFields(joinPath(path, k), d[k], l[k])...)
+		}
+		return fields
+	case []interface{}:
+		l, _ := live.([]interface{})
+		if named(d) && named(l) && len(d) == len(l) {
+			// lists of named items (containers, ports, volumes, env vars) are compared by name
+			var fields []FieldDiff
+			for _, item := range d {
+				name := item.(map[string]interface{})["name"]
+				var liveItem interface{}
+				for _, li := range l {
+					if li.(map[string]interface{})["name"] == name {
+						liveItem = li
+					}
+				}
+				if liveItem == nil {
+					return []FieldDiff{{Path: path, Live: stringify(live), New: stringify(desired)}}
+				}
+				fields = append(fields, diffFields(fmt.Sprintf("%s[name=%v]", path, name), item, liveItem)...)
+			}
+			return fields
+		}
+		if len(d) == 0 && len(l) == 0 {
+			return nil
+		}
+		if len(d) != len(l) {
+			return []FieldDiff{{Path: path, Live: stringify(live), New: stringify(desired)}}
+		}
+		var fields []FieldDiff
+		for idx := range d {
+			fields = append(fields, diffFields(fmt.Sprintf("%s[%d]", path, idx), d[idx], l[idx])...)
+		}
+		return fields
+	case nil:
+		// fields that are null in the generated object are not set by an apply
+		return nil
+	default:
+		if !reflect.DeepEqual(desired, live) {
+			return []FieldDiff{{Path: path, Live: stringify(live), New: stringify(desired)}}
+		}
+	}
+	return nil
+}
+
+// normalize converts whole floats to integers, as the generated templates decode all numbers as floats
+func normalize(v interface{}) interface{} {
+	if f, ok := v.(float64); ok && f == math.Trunc(f) {
+		return int64(f)
+	}
+	return v
+}
+
+// named checks if every item in a list is an object with a name
+func named(items []interface{}) bool {
+	if len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := m["name"]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func joinPath(path, key string) string {
+	if strings.ContainsAny(key, "./") {
+		key = fmt.Sprintf("[%s]", key)
+		return path + key
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func stringify(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,120 @@
+package deploy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// liveObject converts a generated object into the typed object that would exist in the namespace, and allows it to be modified
+func liveObject(t *testing.T, objects []*unstructured.Unstructured, kind string, into runtime.Object, modify func()) runtime.Object {
+	for _, obj := range objects {
+		if obj.GetKind() == kind {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, into); err != nil {
+				t.Fatalf("couldn't convert %s: %v", kind, err)
+			}
+			into.(metav1.Object).SetNamespace("example-project-main")
+			if modify != nil {
+				modify()
+			}
+			return into
+		}
+	}
+	t.Fatalf("no %s in generated objects", kind)
+	return nil
+}
+
+func TestDiff(t *testing.T) {
+	objects, err := ReadManifests("internal/testdata/basic/service-templates/test1-basic-deployment")
+	if err != nil {
+		t.Fatalf("ReadManifests() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		existing func() []runtime.Object
+		want     []ObjectDiff
+	}{
+		{
+			name: "test1 nothing deployed",
+			existing: func() []runtime.Object {
+				return nil
+			},
+			want: []ObjectDiff{
+				{Kind: "Secret", Name: "lagoon-private-registry-dockerhub", Action: DiffAdded},
+				{Kind: "Secret", Name: "lagoon-private-registry-my-custom-registry", Action: DiffAdded},
+				{Kind: "Secret", Name: "lagoon-private-registry-my-hardcode-registry", Action: DiffAdded},
+				{Kind: "Secret", Name: "lagoon-private-registry-my-other-registry", Action: DiffAdded},
+				{Kind: "Service", Name: "node", Action: DiffAdded},
+				{Kind: "Deployment", Name: "node", Action: DiffAdded},
+			},
+		},
+		{
+			name: "test2 changed image, replicas and configmap sha",
+			existing: func() []runtime.Object {
+				deployment := &appsv1.Deployment{}
+				secret := &corev1.Secret{}
+				return []runtime.Object{
+					liveObject(t, objects, "Service", &corev1.Service{}, nil),
+					liveObject(t, objects, "Secret", secret, func() {
+						secret.Data[".dockerconfigjson"] = []byte("old")
+					}),
+					liveObject(t, objects, "Deployment", deployment, func() {
+						deployment.Spec.Replicas = nil
+						deployment.Spec.Template.Annotations["lagoon.sh/configMapSha"] = "oldsha"
+						deployment.Spec.Template.Spec.Containers[0].Image = "harbor.example/example-project/main/node@sha256:old"
+						// defaulted by the cluster, not in the generated template
+						deployment.Spec.RevisionHistoryLimit = new(int32)
+					}),
+					&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{
+						Name:      "cronjob-node-removed",
+						Namespace: "example-project-main",
+						Labels:    managedLabels("main"),
+					}},
+				}
+			},
+			want: []ObjectDiff{
+				{Kind: "Secret", Name: "lagoon-private-registry-dockerhub", Action: DiffChanged, Fields: []FieldDiff{
+					{Path: "data[.dockerconfigjson]", Live: "(redacted)", New: "(redacted)"},
+				}},
+				{Kind: "Secret", Name: "lagoon-private-registry-my-custom-registry", Action: DiffAdded},
+				{Kind: "Secret", Name: "lagoon-private-registry-my-hardcode-registry", Action: DiffAdded},
+				{Kind: "Secret", Name: "lagoon-private-registry-my-other-registry", Action: DiffAdded},
+				{Kind: "Deployment", Name: "node", Action: DiffChanged, Fields: []FieldDiff{
+					{Path: "spec.replicas", Live: "<none>", New: "1"},
+					{Path: "spec.template.metadata.annotations[lagoon.sh/configMapSha]", Live: "oldsha", New: "abcdefg1234567890"},
+					{
+						Path: "spec.template.spec.containers[name=basic].image",
+						Live: "harbor.example/example-project/main/node@sha256:old",
+						New:  "harbor.example/example-project/main/node@sha256:b2001babafaa8128fe89aa8fd11832cade59931d14c3de5b3ca32e2a010fbaa8",
+					},
+				}},
+				{Kind: "CronJob", Name: "cronjob-node-removed", Action: DiffRemoved},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				Kubernetes:  fake.NewClientset(tt.existing()...),
+				Dynamic:     newDynamicClient(),
+				Namespace:   "example-project-main",
+				Environment: "main",
+			}
+			got, err := c.Diff(context.TODO(), objects, false)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// JobStatus is the result of running a single job
+type JobStatus struct {
+	Job       string `json:"job"`
+	Succeeded bool   `json:"succeeded"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	ExitCode  *int32 `json:"exitCode,omitempty"`
+	Logs      string `json:"logs,omitempty"`
+}
+
+// JobOptions are the options for running jobs
+type JobOptions struct {
+	// Timeout is how long to wait for each job to complete
+	Timeout time.Duration
+	// Interval is how often the job status is checked
+	Interval time.Duration
+	// LogLines is how many lines of logs to collect from the job
+	LogLines int64
+}
+
+// SplitJobs splits the objects into the objects that are applied before any jobs are run, the jobs, and the objects
+// that are applied once the jobs have completed
+func SplitJobs(objects []*unstructured.Unstructured) (before, jobs, after []*unstructured.Unstructured) {
+	jobOrder := kindOrder("Job")
+	for _, obj := range objects {
+		switch order := objectOrder(obj); {
+		case order < jobOrder:
+			before = append(before, obj)
+		case order == jobOrder:
+			jobs = append(jobs, obj)
+		default:
+			after = append(after, obj)
+		}
+	}
+	return before, jobs, after
+}
+
+// RunJob runs a job and waits for it to complete. The template of a job can't be changed once it is created,
+// so any job from a previous build with the same name is removed before it is created again
+func (c *Client) RunJob(ctx context.Context, obj *unstructured.Unstructured, opts JobOptions) JobStatus {
+	obj = c.prepare(obj)
+	status := JobStatus{Job: obj.GetName()}
+	jobs := c.Kubernetes.BatchV1().Jobs(c.Namespace)
+	propagation := metav1.DeletePropagationForeground
+	err := jobs.Delete(ctx, obj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !apierrors.IsNotFound(err) {
+		status.Reason = "Error"
+		status.Message = fmt.Sprintf("couldn't remove the previous job: %v", err)
+		return status
+	}
+	if err == nil {
+		err = wait.PollUntilContextTimeout(ctx, opts.Interval, opts.Timeout, true, func(ctx context.Context) (bool, error) {
+			_, err := jobs.Get(ctx, obj.GetName(), metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			if isTransientError(err) {
+				return false, nil
+			}
+			return false, err
+		})
+		if err != nil {
+			status.Reason = "Error"
+			status.Message = fmt.Sprintf("couldn't remove the previous job: %v", err)
+			return status
+		}
+	}
+	data, err := json.Marshal(obj.Object)
+	if err == nil {
+		err = typedResources[batchv1.SchemeGroupVersion.WithKind("Job")].apply(ctx, c, data, metav1.ApplyOptions{FieldManager: FieldManager, Force: true})
+	}
+	if err != nil {
+		status.Reason = "Error"
+		status.Message = fmt.Sprintf("couldn't apply the job: %v", err)
+		return status
+	}
+	opts.Timeout = jobTimeout(obj, opts)
+	return c.waitForJob(ctx, obj.GetName(), opts)
+}
+
+// jobDeadlineMargin is how much longer than its activeDeadlineSeconds a job is waited for, to give the job controller
+// time to fail the job once its deadline has passed
+const jobDeadlineMargin = 5 * time.Minute
+
+// jobTimeout returns how long to wait for a job, which is the activeDeadlineSeconds of the job plus a margin.
+// The timeout of the options is the upper bound, and is used for a job without an activeDeadlineSeconds
+func jobTimeout(obj *unstructured.Unstructured, opts JobOptions) time.Duration {
+	var deadline int64
+	// the number is an int64 or a float64 depending on how the job was decoded
+	if spec, ok := obj.Object["spec"].(map[string]interface{}); ok {
+		switch seconds := spec["activeDeadlineSeconds"].(type) {
+		case int64:
+			deadline = seconds
+		case float64:
+			deadline = int64(seconds)
+		}
+	}
+	if deadline <= 0 {
+		return opts.Timeout
+	}
+	timeout := time.Duration(deadline)*time.Second + jobDeadlineMargin
+	if timeout > opts.Timeout {
+		return opts.Timeout
+	}
+	return timeout
+}
+
+// waitForJob waits for a job to complete or fail, then collects the exit code and logs of its most recent pod
+func (c *Client) waitForJob(ctx context.Context, name string, opts JobOptions) JobStatus {
+	status := JobStatus{Job: name}
+	err := wait.PollUntilContextTimeout(ctx, opts.Interval, opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		job, err := c.Kubernetes.BatchV1().Jobs(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if isTransientError(err) {
+			if c.Debug {
+				fmt.Printf("Waiting for job %s: %v\n", name, err)
+			}
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		for _, cond := range job.Status.Conditions {
+			if cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch cond.Type {
+			case batchv1.JobComplete:
+				status.Succeeded = true
+				return true, nil
+			case batchv1.JobFailed:
+				status.Reason = cond.Reason
+				status.Message = cond.Message
+				return true, nil
+			}
+		}
+		if c.Debug {
+			fmt.Printf("Waiting for job %s: %d active, %d failed\n", name, job.Status.Active, job.Status.Failed)
+		}
+		return false, nil
+	})
+	if err != nil {
+		status.Reason = "Timeout"
+		status.Message = fmt.Sprintf("job did not complete within %s", opts.Timeout)
+		if !wait.Interrupted(err) {
+			status.Reason = "Error"
+			status.Message = err.Error()
+		}
+	}
+	// use a fresh context, the one used for waiting may have expired
+	if err := c.inspectJobPod(context.Background(), &status, opts.LogLines); err != nil {
+		status.Message = fmt.Sprintf("%s, unable to inspect pods: %v", status.Message, err)
+	}
+	return status
+}
+
+// inspectJobPod collects the exit code and logs from the most recent pod of a job
+func (c *Client) inspectJobPod(ctx context.Context, status *JobStatus, logLines int64) error {
+	pods, err := c.Kubernetes.CoreV1().Pods(c.Namespace).List(ctx, metav1.ListOptions{LabelSelector: fmt.Sprintf("job-name=%s", status.Job)})
+	if err != nil {
+		return err
+	}
+	if len(pods.Items) == 0 {
+		return nil
+	}
+	sort.SliceStable(pods.Items, func(i, j int) bool {
+		return pods.Items[j].CreationTimestamp.Before(&pods.Items[i].CreationTimestamp)
+	})
+	pod := pods.Items[0]
+	status.Pod = pod.Name
+	if len(pod.Spec.Containers) == 0 {
+		return nil
+	}
+	container := pod.Spec.Containers[0].Name
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == container && cs.State.Terminated != nil {
+			exitCode := cs.State.Terminated.ExitCode
+			status.ExitCode = &exitCode
+		}
+	}
+	if logLines > 0 {
+		logs, err := c.Kubernetes.CoreV1().Pods(c.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container: container,
+			TailLines: &logLines,
+		}).DoRaw(ctx)
+		if err == nil {
+			status.Logs = string(logs)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package deploy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+// jobObjects creates a job with the provided conditions, and a pod for the job if a container state is provided
+func jobObjects(name string, conditions []batchv1.JobCondition, state *corev1.ContainerState) []runtime.Object {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "example-project-main"},
+		Status:     batchv1.JobStatus{Conditions: conditions},
+	}
+	objects := []runtime.Object{job}
+	if state == nil {
+		return objects
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-xyz",
+			Namespace: "example-project-main",
+			Labels:    map[string]string{"job-name": name},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: name}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: name, State: *state}},
+		},
+	}
+	return append(objects, pod)
+}
+
+func TestWaitForJob(t *testing.T) {
+	tests := []struct {
+		name          string
+		job           string
+		objects       []runtime.Object
+		wantSucceeded bool
+		wantReason    string
+		wantExitCode  *int32
+		wantLogs      bool
+	}{
+		{
+			name: "test1 job complete",
+			job:  "migrate",
+			objects: jobObjects("migrate", []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+			}, &corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}}),
+			wantSucceeded: true,
+			wantExitCode:  ptr.To[int32](0),
+			wantLogs:      true,
+		},
+		{
+			name: "test2 job failed",
+			job:  "migrate",
+			objects: jobObjects("migrate", []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit"},
+			}, &corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2, Reason: "Error"}}),
+			wantReason:   "BackoffLimitExceeded",
+			wantExitCode: ptr.To[int32](2),
+			wantLogs:     true,
+		},
+		{
+			name:       "test3 job still running",
+			job:        "migrate",
+			objects:    jobObjects("migrate", nil, &corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}),
+			wantReason: "Timeout",
+			wantLogs:   true,
+		},
+		{
+			name:       "test4 missing job",
+			job:        "missing",
+			wantReason: "Error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				Kubernetes: fake.NewClientset(tt.objects...),
+				Namespace:  "example-project-main",
+			}
+			result := c.waitForJob(context.TODO(), tt.job, JobOptions{
+				Timeout:  50 * time.Millisecond,
+				Interval: 10 * time.Millisecond,
+				LogLines: 10,
+			})
+			if result.Succeeded != tt.wantSucceeded {
+				t.Errorf("waitForJob() succeeded = %v, want %v", result.Succeeded, tt.wantSucceeded)
+			}
+			if result.Reason != tt.wantReason {
+				t.Errorf("waitForJob() reason = %v, want %v", result.Reason, tt.wantReason)
+			}
+			if !reflect.DeepEqual(result.ExitCode, tt.wantExitCode) {
+				t.Errorf("waitForJob() exit code = %v, want %v", result.ExitCode, tt.wantExitCode)
+			}
+			if (result.Logs != "") != tt.wantLogs {
+				t.Errorf("waitForJob() logs = %v, want logs %v", result.Logs, tt.wantLogs)
+			}
+		})
+	}
+}
+
+func TestRunJob(t *testing.T) {
+	previous := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "migrate",
+			Namespace: "example-project-main",
+			Labels:    map[string]string{"lagoon.sh/buildType": "previous"},
+		},
+		Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}},
+	}
+	c := &Client{
+		Kubernetes: fake.NewClientset(previous),
+		Namespace:  "example-project-main",
+	}
+	job := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":   "migrate",
+			"labels": map[string]interface{}{"lagoon.sh/buildType": "branch"},
+		},
+	}}
+	// the new job never starts, so this times out
+	result := c.RunJob(context.TODO(), job, JobOptions{
+		Timeout:  50 * time.Millisecond,
+		Interval: 10 * time.Millisecond,
+	})
+	if result.Reason != "Timeout" {
+		t.Errorf("RunJob() reason = %v, want Timeout", result.Reason)
+	}
+	got, err := c.Kubernetes.BatchV1().Jobs("example-project-main").Get(context.TODO(), "migrate", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if got.Labels["lagoon.sh/buildType"] != "branch" {
+		t.Errorf("RunJob() didn't replace the previous job, labels = %v", got.Labels)
+	}
+}
+
+func TestSplitJobs(t *testing.T) {
+	objects, err := ReadManifests("internal/testdata/complex/service-templates/test20-nginx-php-jobs", "internal/testdata/complex/job-templates/test20-nginx-php-jobs")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	before, jobs, after := SplitJobs(objects)
+	kinds := func(objects []*unstructured.Unstructured) []string {
+		k := []string{}
+		for _, obj := range sortForApply(objects) {
+			k = append(k, obj.GetKind()+"/"+obj.GetName())
+		}
+		return k
+	}
+	if want := []string{"PersistentVolumeClaim/nginx-php", "Service/nginx-php"}; !reflect.DeepEqual(kinds(before), want) {
+		t.Errorf("SplitJobs() before = %v, want %v", kinds(before), want)
+	}
+	if want := []string{"Job/migrate"}; !reflect.DeepEqual(kinds(jobs), want) {
+		t.Errorf("SplitJobs() jobs = %v, want %v", kinds(jobs), want)
+	}
+	if want := []string{"Deployment/cli", "Deployment/nginx-php"}; !reflect.DeepEqual(kinds(after), want) {
+		t.Errorf("SplitJobs() after = %v, want %v", kinds(after), want)
+	}
+}
+
+func TestWaitForJobRetries(t *testing.T) {
+	c := &Client{
+		Kubernetes: fake.NewClientset(jobObjects("migrate", []batchv1.JobCondition{
+			{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+		}, nil)...),
+		Namespace: "example-project-main",
+	}
+	// the api server is unavailable for the first couple of checks
+	failures := 2
+	c.Kubernetes.(*fake.Clientset).PrependReactor("get", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if failures == 0 {
+			return false, nil, nil
+		}
+		failures--
+		return true, nil, apierrors.NewServiceUnavailable("the server is currently unable to handle the request")
+	})
+	result := c.waitForJob(context.TODO(), "migrate", JobOptions{
+		Timeout:  time.Second,
+		Interval: 10 * time.Millisecond,
+	})
+	if !result.Succeeded {
+		t.Errorf("waitForJob() = %+v, want succeeded", result)
+	}
+	// other errors still fail the job straight away
+	c.Kubernetes.(*fake.Clientset).PrependReactor("get", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "batch", Resource: "jobs"}, "migrate", nil)
+	})
+	result = c.waitForJob(context.TODO(), "migrate", JobOptions{
+		Timeout:  time.Second,
+		Interval: 10 * time.Millisecond,
+	})
+	if result.Reason != "Error" {
+		t.Errorf("waitForJob() reason = %v, want Error", result.Reason)
+	}
+}
+
+func TestJobTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    map[string]interface{}
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{
+			name:    "test1 job deadline plus the margin",
+			spec:    map[string]interface{}{"activeDeadlineSeconds": int64(1800)},
+			timeout: 24 * time.Hour,
+			want:    35 * time.Minute,
+		},
+		{
+			name:    "test2 job deadline longer than the timeout",
+			spec:    map[string]interface{}{"activeDeadlineSeconds": float64(7200)},
+			timeout: time.Hour,
+			want:    time.Hour,
+		},
+		{
+			name:    "test3 job deadline longer than an hour",
+			spec:    map[string]interface{}{"activeDeadlineSeconds": float64(7200)},
+			timeout: 24 * time.Hour,
+			want:    125 * time.Minute,
+		},
+		{
+			name:    "test4 job without a deadline",
+			spec:    map[string]interface{}{},
+			timeout: time.Hour,
+			want:    time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"spec":       tt.spec,
+			}}
+			if got := jobTimeout(job, JobOptions{Timeout: tt.timeout}); got != tt.want {
+				t.Errorf("jobTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
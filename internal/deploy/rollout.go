@@ -0,0 +1,279 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+)
+
+// waitingFailureReasons are container waiting reasons that mean a container will not start without intervention
+var waitingFailureReasons = []string{
+	"CrashLoopBackOff",
+	"ImagePullBackOff",
+	"ErrImagePull",
+	"InvalidImageName",
+	"CreateContainerConfigError",
+	"CreateContainerError",
+	"RunContainerError",
+}
+
+// RolloutStatus is the result of waiting for the rollout of a single deployment
+type RolloutStatus struct {
+	Service string       `json:"service"`
+	Ready   bool         `json:"ready"`
+	Reason  string       `json:"reason,omitempty"`
+	Message string       `json:"message,omitempty"`
+	Pods    []PodFailure `json:"pods,omitempty"`
+}
+
+// PodFailure contains the information collected from a pod in the new replicaset of a failed rollout
+type PodFailure struct {
+	Name      string   `json:"name"`
+	Container string   `json:"container,omitempty"`
+	Reason    string   `json:"reason"`
+	Message   string   `json:"message,omitempty"`
+	Events    []string `json:"events,omitempty"`
+	Logs      string   `json:"logs,omitempty"`
+}
+
+// RolloutOptions are the options for waiting on deployment rollouts
+type RolloutOptions struct {
+	// Timeout is how long to wait for all the rollouts to complete
+	Timeout time.Duration
+	// Interval is how often the deployment status is checked
+	Interval time.Duration
+	// LogLines is how many lines of logs to collect from each failed container
+	LogLines int64
+}
+
+// WaitForDeployments waits for the rollout of all the provided deployments at the same time.
+// When a rollout fails, or does not complete within the timeout, the pods of the new replicaset are inspected to determine why
+func (c *Client) WaitForDeployments(ctx context.Context, deployments []string, opts RolloutOptions) []RolloutStatus {
+	results := make([]RolloutStatus, len(deployments))
+	var wg sync.WaitGroup
+	for idx, name := range deployments {
+		wg.Add(1)
+		go func(idx int, name string) {
+			defer wg.Done()
+			results[idx] = c.waitForDeployment(ctx, name, opts)
+		}(idx, name)
+	}
+	wg.Wait()
+	return results
+}
+
+func (c *Client) waitForDeployment(ctx context.Context, name string, opts RolloutOptions) RolloutStatus {
+	status := RolloutStatus{Service: name}
+	var deployment *appsv1.Deployment
+	err := wait.PollUntilContextTimeout(ctx, opts.Interval, opts.Timeout, true, func(ctx context.Context) (bool, error) {
+		d, err := c.Kubernetes.AppsV1().Deployments(c.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if isTransientError(err) {
+			if c.Debug {
+				fmt.Printf("Waiting for rollout of %s: %v\n", name, err)
+			}
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		deployment = d
+		done, err := deploymentRolledOut(d)
+		if c.Debug && !done && err == nil {
+			fmt.Printf("Waiting for rollout of %s: %d of %d updated replicas are available\n", name, d.Status.AvailableReplicas, ptr.Deref(d.Spec.Replicas, 1))
+		}
+		return done, err
+	})
+	if err == nil {
+		status.Ready = true
+		return status
+	}
+	status.Reason = "Timeout"
+	status.Message = fmt.Sprintf("rollout did not complete within %s", opts.Timeout)
+	var deadlineErr *progressDeadlineError
+	if errors.As(err, &deadlineErr) {
+		status.Reason = "ProgressDeadlineExceeded"
+		status.Message = deadlineErr.message
+	} else if !wait.Interrupted(err) {
+		status.Reason = "Error"
+		status.Message = err.Error()
+	}
+	if deployment == nil {
+		return status
+	}
+	// use a fresh context, the one used for waiting may have expired
+	pods, err := c.inspectNewReplicaSet(context.Background(), deployment, opts.LogLines)
+	if err != nil {
+		status.Message = fmt.Sprintf("%s, unable to inspect pods: %v", status.Message, err)
+		return status
+	}
+	status.Pods = pods
+	// the most useful reason is whatever is wrong with the pods
+	if len(pods) > 0 {
+		status.Reason = pods[0].Reason
+	}
+	return status
+}
+
+type progressDeadlineError struct {
+	message string
+}
+
+func (e *progressDeadlineError) Error() string {
+	return e.message
+}
+
+// deploymentRolledOut uses the same checks as `kubectl rollout status`
+func deploymentRolledOut(d *appsv1.Deployment) (bool, error) {
+	if d.Generation > d.Status.ObservedGeneration {
+		return false, nil
+	}
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == "ProgressDeadlineExceeded" {
+			return false, &progressDeadlineError{message: cond.Message}
+		}
+	}
+	replicas := ptr.Deref(d.Spec.Replicas, 1)
+	if d.Status.UpdatedReplicas < replicas {
+		return false, nil
+	}
+	if d.Status.Replicas > d.Status.UpdatedReplicas {
+		// old replicas are still terminating
+		return false, nil
+	}
+	if d.Status.AvailableReplicas < d.Status.UpdatedReplicas {
+		return false, nil
+	}
+	return true, nil
+}
+
+// inspectNewReplicaSet collects the failure reasons, events and logs from the pods of the newest replicaset of a deployment
+func (c *Client) inspectNewReplicaSet(ctx context.Context, d *appsv1.Deployment, logLines int64) ([]PodFailure, error) {
+	selector, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	replicaSets, err := c.Kubernetes.AppsV1().ReplicaSets(c.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	revision := d.Annotations["deployment.kubernetes.io/revision"]
+	var newRS *appsv1.ReplicaSet
+	for idx, rs := range replicaSets.Items {
+		if !metav1.IsControlledBy(&replicaSets.Items[idx], d) {
+			continue
+		}
+		if rs.Annotations["deployment.kubernetes.io/revision"] == revision {
+			newRS = &replicaSets.Items[idx]
+			break
+		}
+	}
+	podSelector := selector
+	if newRS != nil {
+		podSelector = labels.SelectorFromSet(newRS.Spec.Selector.MatchLabels)
+	}
+	pods, err := c.Kubernetes.CoreV1().Pods(c.Namespace).List(ctx, metav1.ListOptions{LabelSelector: podSelector.String()})
+	if err != nil {
+		return nil, err
+	}
+	events, err := c.Kubernetes.CoreV1().Events(c.Namespace).List(ctx, metav1.ListOptions{FieldSelector: "involvedObject.kind=Pod"})
+	if err != nil {
+		return nil, err
+	}
+	var failures []PodFailure
+	for _, pod := range pods.Items {
+		if newRS != nil && !metav1.IsControlledBy(&pod, newRS) {
+			continue
+		}
+		failure := podFailure(pod, events.Items)
+		if failure == nil {
+			continue
+		}
+		failure.Events = podEvents(pod, events.Items)
+		if failure.Container != "" && logLines > 0 {
+			logs, err := c.Kubernetes.CoreV1().Pods(c.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+				Container: failure.Container,
+				TailLines: &logLines,
+			}).DoRaw(ctx)
+			if err == nil {
+				failure.Logs = string(logs)
+			}
+		}
+		failures = append(failures, *failure)
+	}
+	return failures, nil
+}
+
+// podFailure works out why a pod is not ready
+func podFailure(pod corev1.Pod, events []corev1.Event) *PodFailure {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+			return &PodFailure{Name: pod.Name, Reason: cond.Reason, Message: cond.Message}
+		}
+	}
+	statuses := append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if cs.State.Waiting != nil {
+			for _, reason := range waitingFailureReasons {
+				if cs.State.Waiting.Reason == reason {
+					failure := &PodFailure{Name: pod.Name, Container: cs.Name, Reason: reason, Message: cs.State.Waiting.Message}
+					if reason == "CrashLoopBackOff" && cs.LastTerminationState.Terminated != nil {
+						failure.Message = fmt.Sprintf("last exit code %d (%s)", cs.LastTerminationState.Terminated.ExitCode, cs.LastTerminationState.Terminated.Reason)
+					}
+					return failure
+				}
+			}
+		}
+		if cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0 {
+			return &PodFailure{
+				Name:      pod.Name,
+				Container: cs.Name,
+				Reason:    cs.State.Terminated.Reason,
+				Message:   fmt.Sprintf("exit code %d", cs.State.Terminated.ExitCode),
+			}
+		}
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Ready || cs.State.Running == nil {
+			continue
+		}
+		// the container is running, but not ready. check the events for any probe failures
+		for _, event := range events {
+			if event.InvolvedObject.Name == pod.Name && event.Reason == "Unhealthy" &&
+				(event.InvolvedObject.FieldPath == "" || strings.Contains(event.InvolvedObject.FieldPath, cs.Name)) {
+				return &PodFailure{Name: pod.Name, Container: cs.Name, Reason: "ProbeFailure", Message: event.Message}
+			}
+		}
+		return &PodFailure{Name: pod.Name, Container: cs.Name, Reason: "NotReady", Message: "container is running but not ready"}
+	}
+	return nil
+}
+
+// podEvents returns the events for a pod, oldest first
+func podEvents(pod corev1.Pod, events []corev1.Event) []string {
+	var podEvents []corev1.Event
+	for _, event := range events {
+		if event.InvolvedObject.Kind == "Pod" && event.InvolvedObject.Name == pod.Name {
+			podEvents = append(podEvents, event)
+		}
+	}
+	sort.SliceStable(podEvents, func(i, j int) bool {
+		return podEvents[i].LastTimestamp.Before(&podEvents[j].LastTimestamp)
+	})
+	var result []string
+	for _, event := range podEvents {
+		result = append(result, fmt.Sprintf("%s %s: %s", event.Type, event.Reason, event.Message))
+	}
+	return result
+}
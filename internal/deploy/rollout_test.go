@@ -0,0 +1,270 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+// rolloutObjects creates a deployment, its new replicaset and a pod with the provided status
+func rolloutObjects(name string, status appsv1.DeploymentStatus, podStatus *corev1.PodStatus) []runtime.Object {
+	labels := map[string]string{"app.kubernetes.io/instance": name}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "example-project-main",
+			UID:         types.UID(name + "-uid"),
+			Generation:  2,
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": "2"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](1),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+		},
+		Status: status,
+	}
+	objects := []runtime.Object{deployment}
+	if podStatus == nil {
+		return objects
+	}
+	rsLabels := map[string]string{"app.kubernetes.io/instance": name, "pod-template-hash": "abc123"}
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name + "-abc123",
+			Namespace:       "example-project-main",
+			UID:             types.UID(name + "-rs-uid"),
+			Labels:          rsLabels,
+			Annotations:     map[string]string{"deployment.kubernetes.io/revision": "2"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: rsLabels},
+		},
+	}
+	// a pod from the previous replicaset that should be ignored
+	oldPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-old000-xyz",
+			Namespace: "example-project-main",
+			Labels:    map[string]string{"app.kubernetes.io/instance": name, "pod-template-hash": "old000"},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name + "-abc123-xyz",
+			Namespace:       "example-project-main",
+			Labels:          rsLabels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(rs, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))},
+		},
+		Status: *podStatus,
+	}
+	return append(objects, rs, oldPod, pod)
+}
+
+func TestWaitForDeployments(t *testing.T) {
+	deadlineExceeded := appsv1.DeploymentStatus{
+		ObservedGeneration: 2,
+		Replicas:           2,
+		UpdatedReplicas:    1,
+		Conditions: []appsv1.DeploymentCondition{
+			{
+				Type:    appsv1.DeploymentProgressing,
+				Reason:  "ProgressDeadlineExceeded",
+				Message: "ReplicaSet has timed out progressing.",
+			},
+		},
+	}
+	tests := []struct {
+		name        string
+		deployment  string
+		objects     []runtime.Object
+		wantReady   bool
+		wantReason  string
+		wantPods    int
+		wantEvents  int
+		wantLogs    bool
+		wantMessage string
+	}{
+		{
+			name:       "test1 rollout complete",
+			deployment: "nginx",
+			objects: rolloutObjects("nginx", appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				Replicas:           1,
+				UpdatedReplicas:    1,
+				AvailableReplicas:  1,
+			}, nil),
+			wantReady: true,
+		},
+		{
+			name:       "test2 crashloopbackoff",
+			deployment: "php",
+			objects: rolloutObjects("php", deadlineExceeded, &corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:  "php",
+						State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+						LastTerminationState: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"},
+						},
+					},
+				},
+			}),
+			wantReason:  "CrashLoopBackOff",
+			wantPods:    1,
+			wantLogs:    true,
+			wantMessage: "ReplicaSet has timed out progressing.",
+		},
+		{
+			name:       "test3 imagepullbackoff",
+			deployment: "cli",
+			objects: rolloutObjects("cli", deadlineExceeded, &corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:  "cli",
+						State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+					},
+				},
+			}),
+			wantReason:  "ImagePullBackOff",
+			wantPods:    1,
+			wantLogs:    true,
+			wantMessage: "ReplicaSet has timed out progressing.",
+		},
+		{
+			name:       "test4 probe failure",
+			deployment: "node",
+			objects: append(rolloutObjects("node", deadlineExceeded, &corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:  "node",
+						State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+					},
+				},
+			}), &corev1.Event{
+				ObjectMeta:     metav1.ObjectMeta{Name: "node-abc123-xyz.1", Namespace: "example-project-main"},
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "node-abc123-xyz", FieldPath: "spec.containers{node}"},
+				Type:           "Warning",
+				Reason:         "Unhealthy",
+				Message:        "Readiness probe failed: dial tcp 10.0.0.1:3000: connect: connection refused",
+			}),
+			wantReason:  "ProbeFailure",
+			wantPods:    1,
+			wantEvents:  1,
+			wantLogs:    true,
+			wantMessage: "ReplicaSet has timed out progressing.",
+		},
+		{
+			name:       "test5 timeout while unschedulable",
+			deployment: "solr",
+			objects: rolloutObjects("solr", appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				Replicas:           1,
+				UpdatedReplicas:    1,
+			}, &corev1.PodStatus{
+				Conditions: []corev1.PodCondition{
+					{
+						Type:    corev1.PodScheduled,
+						Status:  corev1.ConditionFalse,
+						Reason:  "Unschedulable",
+						Message: "0/3 nodes are available: 3 Too many pods.",
+					},
+				},
+			}),
+			wantReason:  "Unschedulable",
+			wantPods:    1,
+			wantMessage: "rollout did not complete within 50ms",
+		},
+		{
+			name:        "test6 missing deployment",
+			deployment:  "missing",
+			wantReason:  "Error",
+			wantMessage: `deployments.apps "missing" not found`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{
+				Kubernetes: fake.NewClientset(tt.objects...),
+				Namespace:  "example-project-main",
+			}
+			results := c.WaitForDeployments(context.TODO(), []string{tt.deployment}, RolloutOptions{
+				Timeout:  50 * time.Millisecond,
+				Interval: 10 * time.Millisecond,
+				LogLines: 10,
+			})
+			if len(results) != 1 {
+				t.Fatalf("WaitForDeployments() returned %d results, want 1", len(results))
+			}
+			result := results[0]
+			if result.Ready != tt.wantReady {
+				t.Errorf("WaitForDeployments() ready = %v, want %v", result.Ready, tt.wantReady)
+			}
+			if result.Reason != tt.wantReason {
+				t.Errorf("WaitForDeployments() reason = %v, want %v", result.Reason, tt.wantReason)
+			}
+			if result.Message != tt.wantMessage {
+				t.Errorf("WaitForDeployments() message = %v, want %v", result.Message, tt.wantMessage)
+			}
+			if len(result.Pods) != tt.wantPods {
+				t.Fatalf("WaitForDeployments() pods = %v, want %v", result.Pods, tt.wantPods)
+			}
+			for _, pod := range result.Pods {
+				if len(pod.Events) != tt.wantEvents {
+					t.Errorf("WaitForDeployments() events = %v, want %v", pod.Events, tt.wantEvents)
+				}
+				if (pod.Logs != "") != tt.wantLogs {
+					t.Errorf("WaitForDeployments() logs = %v, want logs %v", pod.Logs, tt.wantLogs)
+				}
+			}
+		})
+	}
+}
+
+func TestWaitForDeploymentsRetries(t *testing.T) {
+	c := &Client{
+		Kubernetes: fake.NewClientset(rolloutObjects("nginx", appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           1,
+			UpdatedReplicas:    1,
+			AvailableReplicas:  1,
+		}, nil)...),
+		Namespace: "example-project-main",
+	}
+	// the api server is unavailable for the first couple of checks
+	failures := 2
+	c.Kubernetes.(*fake.Clientset).PrependReactor("get", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if failures == 0 {
+			return false, nil, nil
+		}
+		failures--
+		return true, nil, apierrors.NewServiceUnavailable("the server is currently unable to handle the request")
+	})
+	opts := RolloutOptions{
+		Timeout:  time.Second,
+		Interval: 10 * time.Millisecond,
+	}
+	results := c.WaitForDeployments(context.TODO(), []string{"nginx"}, opts)
+	if !results[0].Ready {
+		t.Errorf("WaitForDeployments() = %+v, want ready", results[0])
+	}
+	// other errors still fail the rollout straight away
+	c.Kubernetes.(*fake.Clientset).PrependReactor("get", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "nginx", nil)
+	})
+	results = c.WaitForDeployments(context.TODO(), []string{"nginx"}, opts)
+	if results[0].Reason != "Error" {
+		t.Errorf("WaitForDeployments() reason = %v, want Error", results[0].Reason)
+	}
+}
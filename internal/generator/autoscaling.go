@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// defaultAutoscalingMaxReplicas is the cluster wide cap on replicas if ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING_MAX_REPLICAS isn't set
+	defaultAutoscalingMaxReplicas = 10
+	// defaultAutoscalingCPU is the target average cpu utilization if the `lagoon.autoscaling.cpu` label isn't set
+	defaultAutoscalingCPU = 80
+)
+
+// Autoscaling is the horizontal pod autoscaler configuration for a service
+type Autoscaling struct {
+	MinReplicas int32 `json:"minReplicas"`
+	MaxReplicas int32 `json:"maxReplicas"`
+	// TargetCPUUtilization is the average cpu utilization percentage of the requested cpu to scale at
+	TargetCPUUtilization int32 `json:"targetCPUUtilization"`
+}
+
+// autoscalingLabel returns the value of an autoscaling label, a branch override label
+// (example: lagoon.autoscaling.override-branch.main.max) is used over the base label (example: lagoon.autoscaling.max)
+func autoscalingLabel(buildValues *BuildValues, labels composetypes.Labels, suffix string) (string, string) {
+	branchOverrideLabel := fmt.Sprintf("lagoon.autoscaling.override-branch.%s.%s", buildValues.Branch, suffix)
+	if value := lagoon.CheckDockerComposeLagoonLabel(labels, branchOverrideLabel); value != "" {
+		return branchOverrideLabel, value
+	}
+	baseLabel := fmt.Sprintf("lagoon.autoscaling.%s", suffix)
+	return baseLabel, lagoon.CheckDockerComposeLagoonLabel(labels, baseLabel)
+}
+
+// generateAutoscaling returns the autoscaling configuration for a service from its labels, or nil if the service doesn't autoscale.
+// Autoscaling must be enabled by the ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING admin feature flag, and the replicas are capped
+// by ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING_MAX_REPLICAS
+func generateAutoscaling(buildValues *BuildValues, composeService, lagoonType string, labels composetypes.Labels, debug bool) (*Autoscaling, error) {
+	values := map[string]int32{}
+	for _, suffix := range []string{"min", "max", "cpu"} {
+		label, value := autoscalingLabel(buildValues, labels, suffix)
+		if value == "" {
+			continue
+		}
+		i, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || i < 1 {
+			return nil, fmt.Errorf("the value of the autoscaling label %s for %s must be a positive number, not %s", label, composeService, value)
+		}
+		values[suffix] = int32(i)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	if CheckAdminFeatureFlag("AUTOSCALING", debug) != "enabled" {
+		buildValues.addWarning(WarningAutoscalingDisabled, composeService, buildValues.LagoonYAML.DockerComposeYAML,
+			"autoscaling labels are defined, but autoscaling is not enabled on this cluster, the service will not autoscale")
+		return nil, nil
+	}
+	serviceType, ok := servicetypes.ServiceTypes[lagoonType]
+	if !ok {
+		return nil, fmt.Errorf("autoscaling is not supported for %s, the service type %s has no deployment", composeService, lagoonType)
+	}
+	if serviceType.ProvidesPersistentVolume &&
+		(serviceType.Volumes.PersistentVolumeType == corev1.ReadWriteOnce || buildValues.RWX2RWO) {
+		return nil, fmt.Errorf("autoscaling is not supported for %s, the service type %s uses a ReadWriteOnce volume", composeService, lagoonType)
+	}
+	autoscaling := &Autoscaling{
+		MinReplicas:          1,
+		TargetCPUUtilization: defaultAutoscalingCPU,
+	}
+	if minValue, ok := values["min"]; ok {
+		autoscaling.MinReplicas = minValue
+	}
+	maxValue, ok := values["max"]
+	if !ok {
+		return nil, fmt.Errorf("the autoscaling label lagoon.autoscaling.max is required for %s", composeService)
+	}
+	autoscaling.MaxReplicas = maxValue
+	if cpu, ok := values["cpu"]; ok {
+		if cpu > 100 {
+			return nil, fmt.Errorf("the autoscaling cpu target for %s must be a percentage between 1 and 100, not %d", composeService, cpu)
+		}
+		autoscaling.TargetCPUUtilization = cpu
+	}
+	if autoscaling.MinReplicas > autoscaling.MaxReplicas {
+		return nil, fmt.Errorf("the autoscaling minimum replicas for %s (%d) is more than the maximum (%d)",
+			composeService, autoscaling.MinReplicas, autoscaling.MaxReplicas)
+	}
+	// apply the cluster wide cap
+	maxReplicas := int32(defaultAutoscalingMaxReplicas)
+	if capFlag := CheckAdminFeatureFlag("AUTOSCALING_MAX_REPLICAS", debug); capFlag != "" {
+		i, err := strconv.ParseInt(capFlag, 10, 32)
+		if err != nil || i < 1 {
+			return nil, fmt.Errorf("the autoscaling maximum replicas admin feature flag must be a positive number, not %s", capFlag)
+		}
+		maxReplicas = int32(i)
+	}
+	if autoscaling.MaxReplicas > maxReplicas {
+		buildValues.addWarning(WarningAutoscalingCapped, composeService, buildValues.LagoonYAML.DockerComposeYAML,
+			"the autoscaling maximum replicas %d is more than this cluster allows, using %d", autoscaling.MaxReplicas, maxReplicas)
+		autoscaling.MaxReplicas = maxReplicas
+		if autoscaling.MinReplicas > maxReplicas {
+			autoscaling.MinReplicas = maxReplicas
+		}
+	}
+	return autoscaling, nil
+}
@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+)
+
+func Test_generateAutoscaling(t *testing.T) {
+	tests := []struct {
+		name         string
+		lagoonType   string
+		labels       composetypes.Labels
+		vars         []helpers.EnvironmentVariable
+		want         *Autoscaling
+		wantWarnings []string
+		wantErr      bool
+	}{
+		{
+			name:       "test1 no labels",
+			lagoonType: "nginx-php",
+			vars:       []helpers.EnvironmentVariable{{Name: "ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING", Value: "enabled"}},
+		},
+		{
+			name:         "test2 labels but not enabled",
+			lagoonType:   "nginx-php",
+			labels:       composetypes.Labels{"lagoon.autoscaling.max": "4"},
+			wantWarnings: []string{WarningAutoscalingDisabled},
+		},
+		{
+			name:       "test3 defaults",
+			lagoonType: "nginx-php",
+			labels:     composetypes.Labels{"lagoon.autoscaling.max": "4"},
+			vars:       []helpers.EnvironmentVariable{{Name: "ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING", Value: "enabled"}},
+			want:       &Autoscaling{MinReplicas: 1, MaxReplicas: 4, TargetCPUUtilization: 80},
+		},
+		{
+			name:       "test4 branch override",
+			lagoonType: "nginx-php",
+			labels: composetypes.Labels{
+				"lagoon.autoscaling.min":                      "2",
+				"lagoon.autoscaling.max":                      "4",
+				"lagoon.autoscaling.override-branch.main.max": "6",
+				"lagoon.autoscaling.override-branch.dev.max":  "3",
+				"lagoon.autoscaling.cpu":                      "70",
+			},
+			vars: []helpers.EnvironmentVariable{{Name: "ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING", Value: "enabled"}},
+			want: &Autoscaling{MinReplicas: 2, MaxReplicas: 6, TargetCPUUtilization: 70},
+		},
+		{
+			name:       "test5 capped by the cluster",
+			lagoonType: "nginx-php",
+			labels:     composetypes.Labels{"lagoon.autoscaling.min": "6", "lagoon.autoscaling.max": "20"},
+			vars: []helpers.EnvironmentVariable{
+				{Name: "ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING", Value: "enabled"},
+				{Name: "ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING_MAX_REPLICAS", Value: "5"},
+			},
+			want:         &Autoscaling{MinReplicas: 5, MaxReplicas: 5, TargetCPUUtilization: 80},
+			wantWarnings: []string{WarningAutoscalingCapped},
+		},
+		{
+			name:       "test6 min more than max",
+			lagoonType: "nginx-php",
+			labels:     composetypes.Labels{"lagoon.autoscaling.min": "4", "lagoon.autoscaling.max": "2"},
+			vars:       []helpers.EnvironmentVariable{{Name: "ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING", Value: "enabled"}},
+			wantErr:    true,
+		},
+		{
+			name:       "test7 missing max",
+			lagoonType: "nginx-php",
+			labels:     composetypes.Labels{"lagoon.autoscaling.min": "2"},
+			vars:       []helpers.EnvironmentVariable{{Name: "ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING", Value: "enabled"}},
+			wantErr:    true,
+		},
+		{
+			name:       "test8 invalid cpu",
+			lagoonType: "nginx-php",
+			labels:     composetypes.Labels{"lagoon.autoscaling.max": "2", "lagoon.autoscaling.cpu": "80%"},
+			vars:       []helpers.EnvironmentVariable{{Name: "ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING", Value: "enabled"}},
+			wantErr:    true,
+		},
+		{
+			name:       "test9 readwriteonce volume",
+			lagoonType: "mariadb-single",
+			labels:     composetypes.Labels{"lagoon.autoscaling.max": "2"},
+			vars:       []helpers.EnvironmentVariable{{Name: "ADMIN_LAGOON_FEATURE_FLAG_AUTOSCALING", Value: "enabled"}},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, envVar := range tt.vars {
+				os.Setenv(envVar.Name, envVar.Value)
+			}
+			defer helpers.UnsetEnvVars(tt.vars)
+			buildValues := &BuildValues{
+				Branch:     "main",
+				LagoonYAML: lagoon.YAML{DockerComposeYAML: "docker-compose.yml"},
+			}
+			got, err := generateAutoscaling(buildValues, "nginx", tt.lagoonType, tt.labels, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generateAutoscaling() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generateAutoscaling() = %v, want %v", got, tt.want)
+			}
+			codes := []string{}
+			for _, w := range buildValues.Warnings {
+				codes = append(codes, w.Code)
+			}
+			if len(codes) != len(tt.wantWarnings) || (len(codes) > 0 && !reflect.DeepEqual(codes, tt.wantWarnings)) {
+				t.Errorf("generateAutoscaling() warnings = %v, want %v", codes, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	composetypes "github.com/compose-spec/compose-go/types"
 	"github.com/uselagoon/build-deploy-tool/internal/dbaasclient"
 	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -13,75 +14,83 @@ const (
 
 // BuildValues is the values file data generated by the lagoon build
 type BuildValues struct {
-	SourceRepository              string                       `json:"sourceRepository" description:"the source repository for the project"`
-	BuildName                     string                       `json:"buildName" description:"the name of the build"`
-	Project                       string                       `json:"project" description:"the name of the project"`
-	Environment                   string                       `json:"environment" description:"the name of the environment, this is the safe version and may differ from the branch name"`
-	EnvironmentType               string                       `json:"environmentType" description:"the type of the environment, production or development"`
-	Namespace                     string                       `json:"namespace" description:"the kubernetes namespace that this environment is built in"`
-	GitSHA                        string                       `json:"gitSha" description:"the git sha of this particular build"`
-	BuildType                     string                       `json:"buildType" description:"the type of build this is, branch, pullrequest, or promote"`
-	Kubernetes                    string                       `json:"kubernetes" description:"the name of the cluster that this hosts this environment"`
-	LagoonVersion                 string                       `json:"lagoonVersion" description:"the version of lagoon that started this build"`
-	ActiveEnvironment             string                       `json:"activeEnvironment" activestandby:"true" description:"the current active environment"`
-	StandbyEnvironment            string                       `json:"standbyEnvironment" activestandby:"true" description:"the current standby environment"`
-	IsActiveEnvironment           bool                         `json:"isActiveEnvironment" activestandby:"true" description:"flag to determine if this environment is currently an active environment"`
-	IsStandbyEnvironment          bool                         `json:"isStandbyEnvironment" activestandby:"true" description:"flag to determine if this environment is currently a standby environment"`
-	PodSecurityContext            PodSecurityContext           `json:"podSecurityContext" description:"stores the podsecuritycontext overrides"`
-	Branch                        string                       `json:"branch" buildtype:"branch" description:"the branch used for this environment"`
-	PRNumber                      string                       `json:"prNumber" buildtype:"pullrequest" description:"pullrequest number"`
-	PRTitle                       string                       `json:"prTitle" buildtype:"pullrequest" description:"title of the pullrequest"`
-	PRHeadBranch                  string                       `json:"prHeadBranch" buildtype:"pullrequest" description:"head branch of the pullrequest"`
-	PRBaseBranch                  string                       `json:"prBaseBranch" buildtype:"pullrequest" description:"base branch of the pullrequest"`
-	PRHeadSHA                     string                       `json:"prHeadSHA" buildtype:"pullrequest" description:"head sha of the pullrequest"`
-	PRBaseSHA                     string                       `json:"prBaseSHA" buildtype:"pullrequest" description:"base sha of the pullrequest"`
-	PrivateRegistryURLS           []string                     `json:"privateRegistryURLS" description:"this stores all the private registry urls used by this environment"`
-	Fastly                        Fastly                       `json:"fastly" deprecated:"true" description:"this is the configuration of fastly for this environment"`
-	FastlyCacheNoCache            string                       `json:"fastlyCacheNoCahce" deprecated:"true" description:"this is the service id of a fastly cache-no-cache service"`
-	ConfigMapSha                  string                       `json:"configMapSha" description:"this is the computed sha of the lagoon-env configmap, it is used to determine if changes are required to deployments"`
-	Route                         string                       `json:"route" description:"this stores the primary determiend route after all have been calculated"`
-	Routes                        []string                     `json:"routes" description:"this stores all routes after they are calculated"`
-	AutogeneratedRoutes           []string                     `json:"autogeneratedRoutes" description:"this stores autogenerated routes after they are calculated"`
-	AutogeneratedRoutesFastly     bool                         `json:"autogeneratedRoutesFastly" deprecated:"true" description:"the flag to determine if autogenerated routes should receive fastly annotations"`
-	Services                      []ServiceValues              `json:"services" description:"stores all the computed values for all docker-compose services for this environment"`
-	Backup                        BackupConfiguration          `json:"backup" description:"stores backup configuration"`
-	Monitoring                    MonitoringConfig             `json:"monitoring" deprecated:"true" description:"stores monitoring configuration"`
-	DBaaSOperatorEndpoint         string                       `json:"dbaasOperatorEndpoint" description:"the dbaas operator to use for provisioning a consumer"`
-	ServiceTypeOverrides          *lagoon.EnvironmentVariable  `json:"serviceTypeOverrides" description:"stores any service type overrides"`
-	DBaaSEnvironmentTypeOverrides *lagoon.EnvironmentVariable  `json:"dbaasEnvironmentTypeOverrides" description:"stores any dbaas type overrides"`
-	DBaaSFallbackSingle           bool                         `json:"dbaasFallbackSingle" description:"the fallback flag to define if a single pod should be used if no provider is found"`
-	IngressClass                  string                       `json:"ingressClass" description:"the ingress class used for this environment"`
-	TaskScaleMaxIterations        int                          `json:"taskScaleMaxIterations" description:"the number of attempts to wait for pods to scale for pre and post rollout tasks"`
-	TaskScaleWaitTime             int                          `json:"taskScaleWaitTime" description:"the time to wait for pods to scale for pre and post rollout tasks"`
-	DynamicSecretMounts           []DynamicSecretMounts        `json:"dynamicSecretMounts" description:"stores any dynamic secret mount definitions"`
-	DynamicSecretVolumes          []DynamicSecretVolumes       `json:"dynamicSecretVolumes" description:"stores any dynamic secret volume definitions"`
-	DynamicDBaaSSecrets           []string                     `json:"dynamicDBaaSSecrets" description:"stores any dynamic dbaas secret definitions"`
-	ImageCache                    string                       `json:"imageCache" description:"if an imagecache has been provided for images outside of the imageregistry"`
-	DefaultBackupSchedule         string                       `json:"defaultBackupSchedule" description:"the default backup scheduled"`
-	DBaaSClient                   *dbaasclient.Client          `json:"-" description:"used to store connection information for the dbaas operator endpoint"`
-	ImageReferences               map[string]string            `json:"imageReferences" description:"the post image build phase storage location of images for this build"`
-	Resources                     Resources                    `json:"resources" description:"this stores resource overrides for this environment"`
-	CronjobsDisabled              bool                         `json:"cronjobsDisabled" description:"this controls whether cronjobs are enabled for this environment or not"`
-	FeatureFlags                  map[string]bool              `json:"-" description:"these are used by templating systems to turn on or off certain functionality based on if feature flags are defined"`
-	ImageRegistry                 string                       `json:"imageRegistry" description:"the image registry in use for this environment, usually harbor"`
-	DockerBuildKit                *bool                        `json:"dockerBuildKit" description:"the flag to determine if docker buildkit is used"`
-	ImageBuildArguments           map[string]string            `json:"imageBuildArguments" description:"where the calculated image build arguments are stored"`
-	EnvironmentVariables          []lagoon.EnvironmentVariable `json:"environmentVariables" description:"the merged project and environment variables for this environment"`
-	LagoonYAML                    lagoon.YAML                  `json:"lagoonYAML" description:"the unmarshalled lagoon yaml file"`
-	PromotionSourceEnvironment    string                       `json:"promotionSourceEnvironment" buildtype:"promote" description:"the promotion source environment to pull images from"`
-	IsCI                          bool                         `json:"isCI" description:"this controls aspects of the environment or build depending on if a CI job"`
-	RWX2RWO                       bool                         `json:"RWX2RWO" description:"this controls whether the ReadWriteMany to ReadWriteOnce override should be used"`
-	IsolationNetworkPolicy        bool                         `json:"isolationNetworkPolicy" description:"this controls whether isolation network policies should be enabled"`
-	ContainerRegistry             []ContainerRegistry          `json:"containerRegistry" description:"this contains any private container registries that may exist within the environment that need to be logged into"`
-	RoutesAutogeneratePrefixes    []string                     `json:"routesAutogeneratePrefixes"`
-	BackupsEnabled                bool                         `json:"backupsEnabled"`
-	RouteQuota                    *int                         `json:"routeQuota"`
-	ImageCacheBuildArguments      []ImageCacheBuildArguments   `json:"imageCacheBuildArgs"`
-	IgnoreImageCache              bool                         `json:"ignoreImageCache"`
-	SSHPrivateKey                 string                       `json:"sshPrivateKey"`
-	ForcePullImages               []string                     `json:"forcePullImages"`
-	Volumes                       []ComposeVolume              `json:"volumes,omitempty" description:"stores any additional persistent volume definitions"`
-	PodSpreadConstraints          bool                         `json:"podSpreadConstraints"`
+	SourceRepository              string                            `json:"sourceRepository" description:"the source repository for the project"`
+	BuildName                     string                            `json:"buildName" description:"the name of the build"`
+	Project                       string                            `json:"project" description:"the name of the project"`
+	Environment                   string                            `json:"environment" description:"the name of the environment, this is the safe version and may differ from the branch name"`
+	EnvironmentType               string                            `json:"environmentType" description:"the type of the environment, production or development"`
+	Namespace                     string                            `json:"namespace" description:"the kubernetes namespace that this environment is built in"`
+	GitSHA                        string                            `json:"gitSha" description:"the git sha of this particular build"`
+	BuildType                     string                            `json:"buildType" description:"the type of build this is, branch, pullrequest, or promote"`
+	Kubernetes                    string                            `json:"kubernetes" description:"the name of the cluster that this hosts this environment"`
+	LagoonVersion                 string                            `json:"lagoonVersion" description:"the version of lagoon that started this build"`
+	ActiveEnvironment             string                            `json:"activeEnvironment" activestandby:"true" description:"the current active environment"`
+	StandbyEnvironment            string                            `json:"standbyEnvironment" activestandby:"true" description:"the current standby environment"`
+	IsActiveEnvironment           bool                              `json:"isActiveEnvironment" activestandby:"true" description:"flag to determine if this environment is currently an active environment"`
+	IsStandbyEnvironment          bool                              `json:"isStandbyEnvironment" activestandby:"true" description:"flag to determine if this environment is currently a standby environment"`
+	PodSecurityContext            PodSecurityContext                `json:"podSecurityContext" description:"stores the podsecuritycontext overrides"`
+	Branch                        string                            `json:"branch" buildtype:"branch" description:"the branch used for this environment"`
+	PRNumber                      string                            `json:"prNumber" buildtype:"pullrequest" description:"pullrequest number"`
+	PRTitle                       string                            `json:"prTitle" buildtype:"pullrequest" description:"title of the pullrequest"`
+	PRHeadBranch                  string                            `json:"prHeadBranch" buildtype:"pullrequest" description:"head branch of the pullrequest"`
+	PRBaseBranch                  string                            `json:"prBaseBranch" buildtype:"pullrequest" description:"base branch of the pullrequest"`
+	PRHeadSHA                     string                            `json:"prHeadSHA" buildtype:"pullrequest" description:"head sha of the pullrequest"`
+	PRBaseSHA                     string                            `json:"prBaseSHA" buildtype:"pullrequest" description:"base sha of the pullrequest"`
+	PrivateRegistryURLS           []string                          `json:"privateRegistryURLS" description:"this stores all the private registry urls used by this environment"`
+	Fastly                        Fastly                            `json:"fastly" deprecated:"true" description:"this is the configuration of fastly for this environment"`
+	FastlyCacheNoCache            string                            `json:"fastlyCacheNoCahce" deprecated:"true" description:"this is the service id of a fastly cache-no-cache service"`
+	ConfigMapSha                  string                            `json:"configMapSha" description:"this is the computed sha of the lagoon-env configmap, it is used to determine if changes are required to deployments"`
+	Route                         string                            `json:"route" description:"this stores the primary determiend route after all have been calculated"`
+	Routes                        []string                          `json:"routes" description:"this stores all routes after they are calculated"`
+	AutogeneratedRoutes           []string                          `json:"autogeneratedRoutes" description:"this stores autogenerated routes after they are calculated"`
+	AutogeneratedRoutesFastly     bool                              `json:"autogeneratedRoutesFastly" deprecated:"true" description:"the flag to determine if autogenerated routes should receive fastly annotations"`
+	Services                      []ServiceValues                   `json:"services" description:"stores all the computed values for all docker-compose services for this environment"`
+	Backup                        BackupConfiguration               `json:"backup" description:"stores backup configuration"`
+	Monitoring                    MonitoringConfig                  `json:"monitoring" deprecated:"true" description:"stores monitoring configuration"`
+	DBaaSOperatorEndpoint         string                            `json:"dbaasOperatorEndpoint" description:"the dbaas operator to use for provisioning a consumer"`
+	ServiceTypeOverrides          *lagoon.EnvironmentVariable       `json:"serviceTypeOverrides" description:"stores any service type overrides"`
+	DBaaSEnvironmentTypeOverrides *lagoon.EnvironmentVariable       `json:"dbaasEnvironmentTypeOverrides" description:"stores any dbaas type overrides"`
+	DBaaSFallbackSingle           bool                              `json:"dbaasFallbackSingle" description:"the fallback flag to define if a single pod should be used if no provider is found"`
+	IngressClass                  string                            `json:"ingressClass" description:"the ingress class used for this environment"`
+	RouteBackend                  string                            `json:"routeBackend,omitempty" description:"how routes are rendered, ingress or gateway"`
+	Gateway                       *GatewayRef                       `json:"gateway,omitempty" description:"the gateway that HTTPRoutes are attached to when the route backend is gateway"`
+	GatewayIngressClasses         map[string]GatewayRef             `json:"gatewayIngressClasses,omitempty" description:"routes with one of these ingress classes are attached to the mapped gateway"`
+	GatewayCertificateIssuer      *CertificateIssuer                `json:"gatewayCertificateIssuer,omitempty" description:"the cert-manager issuer used to request certificates for routes attached to a gateway"`
+	RouteAccessDefaults           *RouteAccess                      `json:"routeAccessDefaults,omitempty" description:"the ip allow and deny lists and basic authentication used by routes in a non-production environment that don't define their own"`
+	TaskScaleMaxIterations        int                               `json:"taskScaleMaxIterations" description:"the number of attempts to wait for pods to scale for pre and post rollout tasks"`
+	TaskScaleWaitTime             int                               `json:"taskScaleWaitTime" description:"the time to wait for pods to scale for pre and post rollout tasks"`
+	DynamicSecretMounts           []DynamicSecretMounts             `json:"dynamicSecretMounts" description:"stores any dynamic secret mount definitions"`
+	DynamicSecretVolumes          []DynamicSecretVolumes            `json:"dynamicSecretVolumes" description:"stores any dynamic secret volume definitions"`
+	DynamicDBaaSSecrets           []string                          `json:"dynamicDBaaSSecrets" description:"stores any dynamic dbaas secret definitions"`
+	ImageCache                    string                            `json:"imageCache" description:"if an imagecache has been provided for images outside of the imageregistry"`
+	DefaultBackupSchedule         string                            `json:"defaultBackupSchedule" description:"the default backup scheduled"`
+	DBaaSClient                   *dbaasclient.Client               `json:"-" description:"used to store connection information for the dbaas operator endpoint"`
+	DBaaSTypes                    map[string]servicetypes.DBaaSType `json:"-" description:"the types of database that can be requested from the dbaas operator"`
+	ResourcePolicy                *ResourcePolicy                   `json:"-" description:"the cluster policy for the resources services can request"`
+	ImageReferences               map[string]string                 `json:"imageReferences" description:"the post image build phase storage location of images for this build"`
+	Resources                     Resources                         `json:"resources" description:"this stores resource overrides for this environment"`
+	CronjobsDisabled              bool                              `json:"cronjobsDisabled" description:"this controls whether cronjobs are enabled for this environment or not"`
+	FeatureFlags                  map[string]bool                   `json:"-" description:"these are used by templating systems to turn on or off certain functionality based on if feature flags are defined"`
+	ImageRegistry                 string                            `json:"imageRegistry" description:"the image registry in use for this environment, usually harbor"`
+	DockerBuildKit                *bool                             `json:"dockerBuildKit" description:"the flag to determine if docker buildkit is used"`
+	ImageBuildArguments           map[string]string                 `json:"imageBuildArguments" description:"where the calculated image build arguments are stored"`
+	EnvironmentVariables          []lagoon.EnvironmentVariable      `json:"environmentVariables" description:"the merged project and environment variables for this environment"`
+	LagoonYAML                    lagoon.YAML                       `json:"lagoonYAML" description:"the unmarshalled lagoon yaml file"`
+	PromotionSourceEnvironment    string                            `json:"promotionSourceEnvironment" buildtype:"promote" description:"the promotion source environment to pull images from"`
+	IsCI                          bool                              `json:"isCI" description:"this controls aspects of the environment or build depending on if a CI job"`
+	RWX2RWO                       bool                              `json:"RWX2RWO" description:"this controls whether the ReadWriteMany to ReadWriteOnce override should be used"`
+	IsolationNetworkPolicy        bool                              `json:"isolationNetworkPolicy" description:"this controls whether isolation network policies should be enabled"`
+	ContainerRegistry             []ContainerRegistry               `json:"containerRegistry" description:"this contains any private container registries that may exist within the environment that need to be logged into"`
+	RoutesAutogeneratePrefixes    []string                          `json:"routesAutogeneratePrefixes"`
+	BackupsEnabled                bool                              `json:"backupsEnabled"`
+	RouteQuota                    *int                              `json:"routeQuota"`
+	ImageCacheBuildArguments      []ImageCacheBuildArguments        `json:"imageCacheBuildArgs"`
+	IgnoreImageCache              bool                              `json:"ignoreImageCache"`
+	SSHPrivateKey                 string                            `json:"sshPrivateKey"`
+	ForcePullImages               []string                          `json:"forcePullImages"`
+	Volumes                       []ComposeVolume                   `json:"volumes,omitempty" description:"stores any additional persistent volume definitions"`
+	PodSpreadConstraints          bool                              `json:"podSpreadConstraints"`
+	Warnings                      []Warning                         `json:"warnings,omitempty" description:"any warnings raised while generating the build"`
 }
 
 type Resources struct {
@@ -131,6 +140,12 @@ type MonitoringConfig struct {
 	StatusPageID string `json:"statusPageID"`
 }
 
+type RouteAccess struct {
+	AllowCIDRs []string          `json:"allowCIDRs,omitempty"`
+	DenyCIDRs  []string          `json:"denyCIDRs,omitempty"`
+	BasicAuth  *lagoon.BasicAuth `json:"basicAuth,omitempty"`
+}
+
 type DynamicSecretMounts struct {
 	Name      string `json:"name"`
 	MountPath string `json:"mountPath"`
@@ -204,6 +219,15 @@ type ServiceValues struct {
 	AdditionalVolumes                      []ServiceVolume         `json:"additonalVolumes,omitempty"`
 	CreateDefaultVolume                    bool                    `json:"createDefaultVolume"`
 	Resources                              Resources               `json:"resources,omitempty"`
+	SecondaryResources                     *Resources              `json:"secondaryResources,omitempty"`
+	Autoscaling                            *Autoscaling            `json:"autoscaling,omitempty"`
+	PodDisruptionBudget                    *PodDisruptionBudget    `json:"podDisruptionBudget,omitempty"`
+	Probes                                 *Probes                 `json:"probes,omitempty"`
+	SidecarOf                              string                  `json:"sidecarOf,omitempty"` // the compose service this sidecar or init container is added to
+	Sidecars                               []ServiceValues         `json:"sidecars,omitempty"`
+	InitContainers                         []ServiceValues         `json:"initContainers,omitempty"`
+	Job                                    *Job                    `json:"job,omitempty"`
+	Command                                []string                `json:"command,omitempty"`
 }
 
 type ImageBuild struct {
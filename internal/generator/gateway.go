@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// IngressRouteBackend renders routes as networking.k8s.io/v1 Ingress objects
+	IngressRouteBackend = "ingress"
+	// GatewayRouteBackend renders routes as Gateway API HTTPRoute objects
+	GatewayRouteBackend = "gateway"
+)
+
+// GatewayRef is the gateway that HTTPRoutes are attached to
+type GatewayRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// CertificateIssuer is the cert-manager issuer used to request certificates for routes that use a gateway
+type CertificateIssuer struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// generateRouteBackend reads the route backend feature flags.
+// `ROUTE_BACKEND` is `ingress` (the default) or `gateway`, and `GATEWAY_NAME` is the `namespace/name` of the gateway to use when it is `gateway`.
+// `GATEWAY_INGRESS_CLASSES` maps ingress classes to gateways, eg `nginx=gateways/public,nginx-internal=gateways/internal`,
+// routes with one of these ingress classes use the mapped gateway no matter what the route backend is.
+// `GATEWAY_CERTIFICATE_ISSUER` is the `Kind/name` of the cert-manager issuer used to request certificates for gateway routes
+func generateRouteBackend(buildValues *BuildValues, debug bool) error {
+	buildValues.RouteBackend = IngressRouteBackend
+	if backend := CheckFeatureFlag("ROUTE_BACKEND", buildValues.EnvironmentVariables, debug); backend != "" {
+		if backend != IngressRouteBackend && backend != GatewayRouteBackend {
+			return fmt.Errorf("the route backend %s is not valid, it must be %s or %s", backend, IngressRouteBackend, GatewayRouteBackend)
+		}
+		buildValues.RouteBackend = backend
+	}
+	if value := CheckFeatureFlag("GATEWAY_NAME", buildValues.EnvironmentVariables, debug); value != "" {
+		gateway, err := parseGatewayRef(value)
+		if err != nil {
+			return err
+		}
+		buildValues.Gateway = gateway
+	}
+	if buildValues.RouteBackend == GatewayRouteBackend && buildValues.Gateway == nil {
+		return fmt.Errorf("the route backend is %s, but no gateway has been provided", GatewayRouteBackend)
+	}
+	if value := CheckFeatureFlag("GATEWAY_INGRESS_CLASSES", buildValues.EnvironmentVariables, debug); value != "" {
+		buildValues.GatewayIngressClasses = map[string]GatewayRef{}
+		for _, mapping := range strings.Split(value, ",") {
+			ingressClass, gatewayValue, ok := strings.Cut(strings.TrimSpace(mapping), "=")
+			if !ok || ingressClass == "" {
+				return fmt.Errorf("the gateway ingress class mapping %s is not valid, it must be ingressclass=namespace/name", mapping)
+			}
+			gateway, err := parseGatewayRef(gatewayValue)
+			if err != nil {
+				return err
+			}
+			buildValues.GatewayIngressClasses[ingressClass] = *gateway
+		}
+	}
+	if value := CheckFeatureFlag("GATEWAY_CERTIFICATE_ISSUER", buildValues.EnvironmentVariables, debug); value != "" {
+		kind, name, ok := strings.Cut(value, "/")
+		if !ok || (kind != "Issuer" && kind != "ClusterIssuer") || name == "" {
+			return fmt.Errorf("the gateway certificate issuer %s is not valid, it must be Issuer/name or ClusterIssuer/name", value)
+		}
+		buildValues.GatewayCertificateIssuer = &CertificateIssuer{Kind: kind, Name: name}
+	}
+	return nil
+}
+
+// parseGatewayRef parses a gateway in the format `namespace/name`, or `name` for a gateway in the environment namespace
+func parseGatewayRef(value string) (*GatewayRef, error) {
+	gateway := &GatewayRef{Name: value}
+	if namespace, name, ok := strings.Cut(value, "/"); ok {
+		gateway = &GatewayRef{Name: name, Namespace: namespace}
+		if errs := validation.IsDNS1123Label(namespace); errs != nil {
+			return nil, fmt.Errorf("the gateway %s is not valid: %v", value, strings.Join(errs, ", "))
+		}
+	}
+	if errs := validation.IsDNS1123Subdomain(gateway.Name); errs != nil {
+		return nil, fmt.Errorf("the gateway %s is not valid: %v", value, strings.Join(errs, ", "))
+	}
+	return gateway, nil
+}
+
+// RouteGateway returns the gateway a route is attached to, or nil if the route is rendered as an ingress
+func RouteGateway(buildValues BuildValues, route lagoon.RouteV2) *GatewayRef {
+	if gateway, ok := buildValues.GatewayIngressClasses[route.IngressClass]; ok && route.IngressClass != "" {
+		return &gateway
+	}
+	if buildValues.RouteBackend == GatewayRouteBackend {
+		return buildValues.Gateway
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+)
+
+func Test_generateRouteBackend(t *testing.T) {
+	tests := []struct {
+		name      string
+		variables []lagoon.EnvironmentVariable
+		want      BuildValues
+		wantErr   bool
+	}{
+		{
+			name: "test1 defaults to ingress",
+			want: BuildValues{RouteBackend: IngressRouteBackend},
+		},
+		{
+			name: "test2 gateway backend",
+			variables: []lagoon.EnvironmentVariable{
+				{Name: "LAGOON_FEATURE_FLAG_ROUTE_BACKEND", Value: "gateway", Scope: "build"},
+				{Name: "LAGOON_FEATURE_FLAG_GATEWAY_NAME", Value: "lagoon-gateway/public", Scope: "build"},
+				{Name: "LAGOON_FEATURE_FLAG_GATEWAY_CERTIFICATE_ISSUER", Value: "ClusterIssuer/lagoon-acme", Scope: "build"},
+			},
+			want: BuildValues{
+				RouteBackend:             GatewayRouteBackend,
+				Gateway:                  &GatewayRef{Name: "public", Namespace: "lagoon-gateway"},
+				GatewayCertificateIssuer: &CertificateIssuer{Kind: "ClusterIssuer", Name: "lagoon-acme"},
+			},
+		},
+		{
+			name: "test3 ingress class mapping",
+			variables: []lagoon.EnvironmentVariable{
+				{Name: "LAGOON_FEATURE_FLAG_GATEWAY_INGRESS_CLASSES", Value: "nginx=lagoon-gateway/public, nginx-internal=internal", Scope: "build"},
+			},
+			want: BuildValues{
+				RouteBackend: IngressRouteBackend,
+				GatewayIngressClasses: map[string]GatewayRef{
+					"nginx":          {Name: "public", Namespace: "lagoon-gateway"},
+					"nginx-internal": {Name: "internal"},
+				},
+			},
+		},
+		{
+			name: "test4 gateway backend without a gateway",
+			variables: []lagoon.EnvironmentVariable{
+				{Name: "LAGOON_FEATURE_FLAG_ROUTE_BACKEND", Value: "gateway", Scope: "build"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test5 invalid backend",
+			variables: []lagoon.EnvironmentVariable{
+				{Name: "LAGOON_FEATURE_FLAG_ROUTE_BACKEND", Value: "traefik", Scope: "build"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test6 invalid issuer kind",
+			variables: []lagoon.EnvironmentVariable{
+				{Name: "LAGOON_FEATURE_FLAG_GATEWAY_CERTIFICATE_ISSUER", Value: "Certificate/lagoon-acme", Scope: "build"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildValues{EnvironmentVariables: tt.variables}
+			err := generateRouteBackend(&got, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generateRouteBackend() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			got.EnvironmentVariables = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generateRouteBackend() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteGateway(t *testing.T) {
+	buildValues := BuildValues{
+		RouteBackend: GatewayRouteBackend,
+		Gateway:      &GatewayRef{Name: "public"},
+		GatewayIngressClasses: map[string]GatewayRef{
+			"nginx-internal": {Name: "internal"},
+		},
+	}
+	if got := RouteGateway(buildValues, lagoon.RouteV2{IngressClass: "nginx"}); !reflect.DeepEqual(got, &GatewayRef{Name: "public"}) {
+		t.Errorf("RouteGateway() = %v, want the default gateway", got)
+	}
+	if got := RouteGateway(buildValues, lagoon.RouteV2{IngressClass: "nginx-internal"}); !reflect.DeepEqual(got, &GatewayRef{Name: "internal"}) {
+		t.Errorf("RouteGateway() = %v, want the mapped gateway", got)
+	}
+	buildValues.RouteBackend = IngressRouteBackend
+	if got := RouteGateway(buildValues, lagoon.RouteV2{IngressClass: "nginx"}); got != nil {
+		t.Errorf("RouteGateway() = %v, want an ingress", got)
+	}
+}
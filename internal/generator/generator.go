@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/uselagoon/build-deploy-tool/internal/dbaasclient"
 	"github.com/uselagoon/build-deploy-tool/internal/helpers"
 	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
 )
 
 type Generator struct {
@@ -20,6 +22,7 @@ type Generator struct {
 	AutogeneratedRoutes *lagoon.RoutesV2
 	MainRoutes          *lagoon.RoutesV2
 	ActiveStandbyRoutes *lagoon.RoutesV2
+	Warnings            []Warning
 }
 
 type GeneratorInput struct {
@@ -65,6 +68,8 @@ type GeneratorInput struct {
 	DynamicDBaaSSecrets        []string
 	ImageCacheBuildArgsJSON    string
 	SSHPrivateKey              string
+	// WarningsAsErrors is a list of warning codes that should fail the generator, `all` fails on any warning
+	WarningsAsErrors []string
 }
 
 func NewGenerator(
@@ -147,6 +152,11 @@ func NewGenerator(
 		return nil, err
 	}
 	buildValues.LagoonYAML = *lYAML
+	// check the .lagoon.yml for anything the build will ignore or quietly correct
+	checkLagoonYAML(&buildValues, generator.LagoonYAML)
+	if _, err := os.Stat(generator.LagoonYAMLOverride); err == nil {
+		checkLagoonYAML(&buildValues, generator.LagoonYAMLOverride)
+	}
 	if buildValues.LagoonYAML.EnvironmentVariables.GitSHA == nil || !*buildValues.LagoonYAML.EnvironmentVariables.GitSHA {
 		buildValues.GitSHA = "0000000000000000000000000000000000000000"
 	}
@@ -205,6 +215,21 @@ func NewGenerator(
 
 	// get the dbaas operator http endpoint or fall back to the default
 	buildValues.DBaaSOperatorEndpoint = helpers.GetEnv("DBAAS_OPERATOR_HTTP", "http://dbaas.lagoon.svc:5000", generator.Debug)
+	// any additional dbaas types the remote supports are defined in a file
+	dbaasTypes, err := servicetypes.GetDBaaSTypes(
+		helpers.GetEnv("DBAAS_TYPES_FILE", "", generator.Debug),
+		helpers.GetEnvBool("DBAAS_TYPES_ALLOW_OVERRIDES", false, generator.Debug),
+	)
+	if err != nil {
+		return nil, err
+	}
+	buildValues.DBaaSTypes = dbaasTypes
+	// the cluster policy for the resources services can request is either in a file, or an admin feature flag
+	resourcePolicy, err := LoadResourcePolicy(helpers.GetEnv("RESOURCE_POLICY_FILE", "", generator.Debug), generator.Debug)
+	if err != nil {
+		return nil, err
+	}
+	buildValues.ResourcePolicy = resourcePolicy
 
 	// by default, environment routes are not monitored
 	buildValues.Monitoring.Enabled = false
@@ -247,8 +272,12 @@ func NewGenerator(
 	// unmarshal and then merge the two so there is only 1 set of variables to iterate over
 	projectVars := []lagoon.EnvironmentVariable{}
 	envVars := []lagoon.EnvironmentVariable{}
-	json.Unmarshal([]byte(projectVariables), &projectVars)
-	json.Unmarshal([]byte(environmentVariables), &envVars)
+	if err := json.Unmarshal([]byte(projectVariables), &projectVars); err != nil && projectVariables != "" {
+		buildValues.addWarning(WarningInvalidVariables, "", "", "unable to read the project variables, they will be ignored: %v", err)
+	}
+	if err := json.Unmarshal([]byte(environmentVariables), &envVars); err != nil && environmentVariables != "" {
+		buildValues.addWarning(WarningInvalidVariables, "", "", "unable to read the environment variables, they will be ignored: %v", err)
+	}
 	mergedVariables := lagoon.MergeVariables(projectVars, envVars)
 	// collect a bunch of the default LAGOON_X based build variables that are injected into `lagoon-env` and make them available
 	configVars := collectBuildVariables(buildValues)
@@ -312,6 +341,16 @@ func NewGenerator(
 	ingressClass := CheckFeatureFlag("INGRESS_CLASS", buildValues.EnvironmentVariables, generator.Debug)
 	buildValues.IngressClass = ingressClass
 
+	// check which backend routes are rendered with
+	if err := generateRouteBackend(&buildValues, generator.Debug); err != nil {
+		return nil, err
+	}
+
+	// check for any default access restrictions for the routes of non-production environments
+	if err := generateRouteAccessDefaults(&buildValues); err != nil {
+		return nil, err
+	}
+
 	// check for rootless workloads
 	rootlessWorkloads := CheckFeatureFlag("ROOTLESS_WORKLOAD", buildValues.EnvironmentVariables, generator.Debug)
 	if rootlessWorkloads == "enabled" {
@@ -403,9 +442,17 @@ func NewGenerator(
 		}
 	}
 
-	// @TODO: eventually fail builds if this is not set https://github.com/uselagoon/build-deploy-tool/issues/56
-	// lagoonDBaaSFallbackSingle, _ := lagoon.GetLagoonVariable("LAGOON_FEATURE_FLAG_DBAAS_FALLBACK_SINGLE", nil, buildValues.EnvironmentVariables)
-	// buildValues.DBaaSFallbackSingle = helpers.StrToBool(lagoonDBaaSFallbackSingle.Value)
+	// check if dbaas services can fall back to a single pod service when the dbaas operator is unavailable or has no provider,
+	// production environments fail the build by default so a production site doesn't quietly get an in-cluster database
+	dbaasFallbackSingle := CheckFeatureFlag("DBAAS_FALLBACK_SINGLE", buildValues.EnvironmentVariables, generator.Debug)
+	switch dbaasFallbackSingle {
+	case "enabled":
+		buildValues.DBaaSFallbackSingle = true
+	case "disabled":
+		buildValues.DBaaSFallbackSingle = false
+	default:
+		buildValues.DBaaSFallbackSingle = buildValues.EnvironmentType != "production"
+	}
 
 	/* start backups configuration */
 	err = generateBackupValues(&buildValues, buildValues.EnvironmentVariables, generator.Debug)
@@ -461,6 +508,15 @@ func NewGenerator(
 	}
 	/* end route generation configuration */
 
+	// promote any warnings to errors if the policy requires it, the policy is a comma separated list of warning codes, or `all`
+	warningsPolicy := generator.WarningsAsErrors
+	if lffWarningsAsErrors := CheckFeatureFlag("WARNINGS_AS_ERRORS", buildValues.EnvironmentVariables, generator.Debug); lffWarningsAsErrors != "" {
+		warningsPolicy = append(warningsPolicy, strings.Split(lffWarningsAsErrors, ",")...)
+	}
+	if promoted := promotedWarnings(buildValues.Warnings, warningsPolicy); len(promoted) > 0 {
+		return nil, &WarningsError{Warnings: buildValues.Warnings, Promoted: promoted}
+	}
+
 	// finally return the generator values, this should be a mostly complete version of the resulting data needed for a build
 	// another step will collect the current or known state of a build.
 	// the output of the generator and the output of that state collector will eventually replace a lot of the legacy BASH script
@@ -471,5 +527,25 @@ func NewGenerator(
 		AutogeneratedRoutes: autogenRoutes,
 		MainRoutes:          mainRoutes,
 		ActiveStandbyRoutes: activeStandbyRoutes,
+		Warnings:            buildValues.Warnings,
 	}, nil
 }
+
+// checkLagoonYAML adds a warning for any unknown keys or booleans defined as strings in the .lagoon.yml file
+func checkLagoonYAML(buildValues *BuildValues, file string) {
+	rawYAML, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+	problems, err := lagoon.CheckLagoonYAML(rawYAML)
+	if err != nil {
+		return
+	}
+	for _, problem := range problems {
+		code := WarningLagoonYAMLUnknownKey
+		if problem.Code == lagoon.ProblemStringBoolean {
+			code = WarningLagoonYAMLStringBoolean
+		}
+		buildValues.addWarning(code, "", fmt.Sprintf("%s:%d", file, problem.Line), "%s: %s", problem.Path, problem.Message)
+	}
+}
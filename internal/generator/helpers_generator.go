@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/uselagoon/build-deploy-tool/internal/dbaasclient"
 	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -111,6 +112,10 @@ func GenerateInput(rootCmd cobra.Command, debug bool) (GeneratorInput, error) {
 	if err != nil {
 		return GeneratorInput{}, fmt.Errorf("error reading default-backup-schedule flag: %v", err)
 	}
+	warningsAsErrors, err := rootCmd.PersistentFlags().GetStringSlice("warnings-as-errors")
+	if err != nil {
+		return GeneratorInput{}, fmt.Errorf("error reading warnings-as-errors flag: %v", err)
+	}
 	// create a dbaas client with the default configuration
 	dbaas := dbaasclient.NewClient(dbaasclient.Client{})
 	return GeneratorInput{
@@ -138,6 +143,7 @@ func GenerateInput(rootCmd cobra.Command, debug bool) (GeneratorInput, error) {
 		IgnoreMissingEnvFiles:    ignoreMissingEnvFiles,
 		IgnoreNonStringKeyErrors: ignoreNonStringKeyErrors,
 		DBaaSClient:              dbaas,
+		WarningsAsErrors:         warningsAsErrors,
 		DefaultBackupSchedule:    defaultBackupSchedule,
 	}, nil
 }
@@ -274,6 +280,20 @@ func getDBaasEnvironment(
 	return exists, nil
 }
 
+// dbaasType returns the dbaas type for a service type, either `<name>-dbaas` or `<name>` if it isn't also a service type
+func (b *BuildValues) dbaasType(lagoonType string) (servicetypes.DBaaSType, bool) {
+	dbaasTypes := b.DBaaSTypes
+	if dbaasTypes == nil {
+		dbaasTypes = servicetypes.DBaaSTypes
+	}
+	if dbaasType, ok := dbaasTypes[strings.TrimSuffix(lagoonType, "-dbaas")]; ok {
+		if _, isServiceType := servicetypes.ServiceTypes[lagoonType]; !isServiceType {
+			return dbaasType, true
+		}
+	}
+	return servicetypes.DBaaSType{}, false
+}
+
 var exp = regexp.MustCompile(`(\\*)\$\{(.+?)(?:(\:\-)(.*?))?\}`)
 
 func determineRefreshImage(serviceName, imageName string, envVars []lagoon.EnvironmentVariable) (string, []error) {
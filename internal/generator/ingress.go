@@ -224,6 +224,13 @@ func generateAutogenRoutes(
 					AlternativeNames:    alternativeNames,
 					RequestVerification: helpers.BoolPtr(service.AutogeneratedRoutesRequestVerification),
 					PathRoutes:          pathRoutes,
+					AllowCIDRs:          buildValues.LagoonYAML.Routes.Autogenerate.AllowCIDRs,
+					DenyCIDRs:           buildValues.LagoonYAML.Routes.Autogenerate.DenyCIDRs,
+					BasicAuth:           buildValues.LagoonYAML.Routes.Autogenerate.BasicAuth,
+				}
+				applyRouteAccessDefaults(&autogenRoute, buildValues.RouteAccessDefaults)
+				if err := lagoon.ValidateRouteV2(autogenRoute); err != nil {
+					return fmt.Errorf("autogenerated route for %s is not valid: %v", serviceOverrideName, err)
 				}
 				autogenRoutes.Routes = append(autogenRoutes.Routes, autogenRoute)
 			}
@@ -308,6 +315,11 @@ func generateActiveStandbyRoutes(
 			}
 		}
 	}
+	for _, route := range activeStanbyRoutes.Routes {
+		if err := checkRouteCanary(route, buildValues); err != nil {
+			return *activeStanbyRoutes, err
+		}
+	}
 	return *activeStanbyRoutes, nil
 }
 
@@ -357,6 +369,11 @@ func generateAndMerge(
 		return *n, err
 	}
 
+	// add the default access restrictions to any routes that don't define their own
+	for idx := range mainRoutes.Routes {
+		applyRouteAccessDefaults(&mainRoutes.Routes[idx], buildValues.RouteAccessDefaults)
+	}
+
 	// check computed routes to make sure that any defined path routes have valid service backends
 	for _, mr := range mainRoutes.Routes {
 		for _, pr := range mr.PathRoutes {
@@ -372,13 +389,97 @@ func generateAndMerge(
 				return *n, err
 			}
 		}
+		if err := checkRouteCanary(mr, buildValues); err != nil {
+			return *n, err
+		}
 	}
 	return mainRoutes, nil
 }
 
+// checkRouteCanary checks that the canary of a route can be used by this environment
+func checkRouteCanary(route lagoon.RouteV2, buildValues BuildValues) error {
+	if route.Canary == nil {
+		return nil
+	}
+	if buildValues.EnvironmentType != "production" {
+		return fmt.Errorf("route %s has a canary, this can only be used in production environments", route.Domain)
+	}
+	if route.Canary.Environment == buildValues.Environment {
+		return fmt.Errorf("the canary of route %s is for this environment, use service instead", route.Domain)
+	}
+	// a service in another environment can't be checked
+	if route.Canary.Environment == "" {
+		if err := checkServiceInServices(route.Canary.Service, buildValues); err != nil {
+			return fmt.Errorf("the canary of route %s is not valid: %v", route.Domain, err)
+		}
+	}
+	return nil
+}
+
+// generateRouteAccessDefaults reads the default access restrictions for the routes of a non-production environment
+// from the LAGOON_ROUTE_ALLOW_CIDRS, LAGOON_ROUTE_DENY_CIDRS and LAGOON_ROUTE_BASIC_AUTH_SECRET variables
+func generateRouteAccessDefaults(buildValues *BuildValues) error {
+	if buildValues.EnvironmentType == "production" {
+		return nil
+	}
+	defaults := &RouteAccess{}
+	if allowCIDRs, _ := lagoon.GetLagoonVariable("LAGOON_ROUTE_ALLOW_CIDRS", []string{"build", "global"}, buildValues.EnvironmentVariables); allowCIDRs != nil {
+		defaults.AllowCIDRs = splitCIDRs(allowCIDRs.Value)
+		if err := lagoon.ValidateCIDRs(defaults.AllowCIDRs); err != nil {
+			return fmt.Errorf("LAGOON_ROUTE_ALLOW_CIDRS is not valid: %v", err)
+		}
+	}
+	if denyCIDRs, _ := lagoon.GetLagoonVariable("LAGOON_ROUTE_DENY_CIDRS", []string{"build", "global"}, buildValues.EnvironmentVariables); denyCIDRs != nil {
+		defaults.DenyCIDRs = splitCIDRs(denyCIDRs.Value)
+		if err := lagoon.ValidateCIDRs(defaults.DenyCIDRs); err != nil {
+			return fmt.Errorf("LAGOON_ROUTE_DENY_CIDRS is not valid: %v", err)
+		}
+	}
+	if basicAuthSecret, _ := lagoon.GetLagoonVariable("LAGOON_ROUTE_BASIC_AUTH_SECRET", []string{"build", "global"}, buildValues.EnvironmentVariables); basicAuthSecret != nil {
+		defaults.BasicAuth = &lagoon.BasicAuth{Secret: strings.TrimSpace(basicAuthSecret.Value)}
+		if err := lagoon.ValidateBasicAuth(defaults.BasicAuth); err != nil {
+			return fmt.Errorf("LAGOON_ROUTE_BASIC_AUTH_SECRET is not valid: %v", err)
+		}
+	}
+	if defaults.AllowCIDRs != nil || defaults.DenyCIDRs != nil || defaults.BasicAuth != nil {
+		buildValues.RouteAccessDefaults = defaults
+	}
+	return nil
+}
+
+// splitCIDRs splits a comma separated list of ip ranges
+func splitCIDRs(value string) []string {
+	cidrs := []string{}
+	for _, cidr := range strings.Split(value, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	return cidrs
+}
+
+// applyRouteAccessDefaults adds the default access restrictions of the environment to a route, for any that the route doesn't define itself
+func applyRouteAccessDefaults(route *lagoon.RouteV2, defaults *RouteAccess) {
+	if defaults == nil {
+		return
+	}
+	if route.AllowCIDRs == nil {
+		route.AllowCIDRs = defaults.AllowCIDRs
+	}
+	if route.DenyCIDRs == nil {
+		route.DenyCIDRs = defaults.DenyCIDRs
+	}
+	if route.BasicAuth == nil {
+		route.BasicAuth = defaults.BasicAuth
+	}
+}
+
 func checkServiceInServices(service string, buildValues BuildValues) error {
 	for _, s := range buildValues.Services {
 		if s.Name == service {
+			if s.SidecarOf != "" {
+				return fmt.Errorf("%s is a sidecar of %s and can't be used by a route", service, s.SidecarOf)
+			}
 			return nil
 		}
 		for _, sp := range s.AdditionalServicePorts {
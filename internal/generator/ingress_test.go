@@ -1295,3 +1295,61 @@ func Test_generateRoutes(t *testing.T) {
 		})
 	}
 }
+
+func Test_generateRouteAccessDefaults(t *testing.T) {
+	tests := []struct {
+		name            string
+		environmentType string
+		variables       []lagoon.EnvironmentVariable
+		want            *RouteAccess
+		wantErr         bool
+	}{
+		{
+			name:            "test1 development defaults",
+			environmentType: "development",
+			variables: []lagoon.EnvironmentVariable{
+				{Name: "LAGOON_ROUTE_ALLOW_CIDRS", Value: "203.0.113.0/24, 198.51.100.10/32", Scope: "build"},
+				{Name: "LAGOON_ROUTE_BASIC_AUTH_SECRET", Value: "basic-auth", Scope: "global"},
+			},
+			want: &RouteAccess{
+				AllowCIDRs: []string{"203.0.113.0/24", "198.51.100.10/32"},
+				BasicAuth:  &lagoon.BasicAuth{Secret: "basic-auth"},
+			},
+		},
+		{
+			name:            "test2 production ignores defaults",
+			environmentType: "production",
+			variables: []lagoon.EnvironmentVariable{
+				{Name: "LAGOON_ROUTE_ALLOW_CIDRS", Value: "203.0.113.0/24", Scope: "build"},
+			},
+		},
+		{
+			name:            "test3 no defaults",
+			environmentType: "development",
+		},
+		{
+			name:            "test4 invalid cidr",
+			environmentType: "development",
+			variables: []lagoon.EnvironmentVariable{
+				{Name: "LAGOON_ROUTE_DENY_CIDRS", Value: "203.0.113.0/33", Scope: "build"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buildValues := BuildValues{
+				EnvironmentType:      tt.environmentType,
+				EnvironmentVariables: tt.variables,
+			}
+			err := generateRouteAccessDefaults(&buildValues)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generateRouteAccessDefaults() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(buildValues.RouteAccessDefaults, tt.want) {
+				t.Errorf("generateRouteAccessDefaults() = %v, want %v", buildValues.RouteAccessDefaults, tt.want)
+			}
+		})
+	}
+}
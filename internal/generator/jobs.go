@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+)
+
+// JobServiceType is the type of compose services that run once as a kubernetes job before the deployments are rolled out
+const JobServiceType = "job"
+
+// defaultJobTimeout is how long a job can run for if `lagoon.job.timeout` is not set
+const defaultJobTimeout = 30 * time.Minute
+
+// Job is the configuration of the kubernetes job for a service with the type job
+type Job struct {
+	// BackoffLimit is how many times a failed job is retried
+	BackoffLimit int32 `json:"backoffLimit"`
+	// ActiveDeadlineSeconds is how long the job can run for before it is failed
+	ActiveDeadlineSeconds int64 `json:"activeDeadlineSeconds"`
+}
+
+// generateJob creates the job configuration from the `lagoon.job.*` labels of a service with the type job
+func generateJob(composeService string, labels composetypes.Labels) (*Job, error) {
+	job := &Job{
+		ActiveDeadlineSeconds: int64(defaultJobTimeout.Seconds()),
+	}
+	if value := lagoon.CheckDockerComposeLagoonLabel(labels, "lagoon.job.backoff-limit"); value != "" {
+		backoffLimit, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || backoffLimit < 0 {
+			return nil, fmt.Errorf("the provided lagoon.job.backoff-limit %s for service %s must be 0 or more", value, composeService)
+		}
+		job.BackoffLimit = int32(backoffLimit)
+	}
+	if value := lagoon.CheckDockerComposeLagoonLabel(labels, "lagoon.job.timeout"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < time.Second {
+			return nil, fmt.Errorf("the provided lagoon.job.timeout %s for service %s must be a duration of at least 1s, for example 10m", value, composeService)
+		}
+		job.ActiveDeadlineSeconds = int64(timeout.Seconds())
+	}
+	return job, nil
+}
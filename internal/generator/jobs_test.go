@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+)
+
+func Test_generateJob(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  composetypes.Labels
+		want    *Job
+		wantErr bool
+	}{
+		{
+			name: "test1 defaults",
+			want: &Job{ActiveDeadlineSeconds: 1800},
+		},
+		{
+			name: "test2 backoff limit and timeout",
+			labels: composetypes.Labels{
+				"lagoon.job.backoff-limit": "2",
+				"lagoon.job.timeout":       "1h",
+			},
+			want: &Job{BackoffLimit: 2, ActiveDeadlineSeconds: 3600},
+		},
+		{
+			name:    "test3 negative backoff limit",
+			labels:  composetypes.Labels{"lagoon.job.backoff-limit": "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "test4 timeout without a unit",
+			labels:  composetypes.Labels{"lagoon.job.timeout": "600"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generateJob("migrate", tt.labels)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generateJob() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generateJob() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
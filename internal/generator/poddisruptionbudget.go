@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// PodDisruptionBudget is the disruption budget for a service that runs more than one replica,
+// only one of MinAvailable or MaxUnavailable is set. Services without one use a maxUnavailable of 1
+type PodDisruptionBudget struct {
+	MinAvailable   *intstr.IntOrString `json:"minAvailable,omitempty"`
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// generatePodDisruptionBudget returns the disruption budget for a service from the `lagoon.pdb.min-available` or
+// `lagoon.pdb.max-unavailable` labels, or nil if neither is defined
+func generatePodDisruptionBudget(composeService string, labels composetypes.Labels) (*PodDisruptionBudget, error) {
+	minAvailable := lagoon.CheckDockerComposeLagoonLabel(labels, "lagoon.pdb.min-available")
+	maxUnavailable := lagoon.CheckDockerComposeLagoonLabel(labels, "lagoon.pdb.max-unavailable")
+	if minAvailable != "" && maxUnavailable != "" {
+		return nil, fmt.Errorf("only one of the labels lagoon.pdb.min-available or lagoon.pdb.max-unavailable can be defined for %s", composeService)
+	}
+	pdb := &PodDisruptionBudget{}
+	switch {
+	case minAvailable != "":
+		value, err := parseDisruptionBudgetValue(minAvailable)
+		if err != nil {
+			return nil, fmt.Errorf("the value of the label lagoon.pdb.min-available for %s is not valid: %v", composeService, err)
+		}
+		pdb.MinAvailable = value
+	case maxUnavailable != "":
+		value, err := parseDisruptionBudgetValue(maxUnavailable)
+		if err != nil {
+			return nil, fmt.Errorf("the value of the label lagoon.pdb.max-unavailable for %s is not valid: %v", composeService, err)
+		}
+		pdb.MaxUnavailable = value
+	default:
+		return nil, nil
+	}
+	return pdb, nil
+}
+
+// parseDisruptionBudgetValue parses a number of pods, or a percentage of pods (eg `50%`)
+func parseDisruptionBudgetValue(s string) (*intstr.IntOrString, error) {
+	number := strings.TrimSuffix(s, "%")
+	i, err := strconv.ParseInt(number, 10, 32)
+	if err != nil || i < 0 {
+		return nil, fmt.Errorf("%s must be a number of pods or a percentage", s)
+	}
+	if number != s {
+		if i > 100 {
+			return nil, fmt.Errorf("%s must be a percentage between 0%% and 100%%", s)
+		}
+		value := intstr.FromString(s)
+		return &value, nil
+	}
+	value := intstr.FromInt32(int32(i))
+	return &value, nil
+}
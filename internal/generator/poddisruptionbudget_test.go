@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_generatePodDisruptionBudget(t *testing.T) {
+	one := intstr.FromInt32(1)
+	half := intstr.FromString("50%")
+	tests := []struct {
+		name    string
+		labels  composetypes.Labels
+		want    *PodDisruptionBudget
+		wantErr bool
+	}{
+		{
+			name: "test1 no labels",
+		},
+		{
+			name:   "test2 min available",
+			labels: composetypes.Labels{"lagoon.pdb.min-available": "1"},
+			want:   &PodDisruptionBudget{MinAvailable: &one},
+		},
+		{
+			name:   "test3 max unavailable percentage",
+			labels: composetypes.Labels{"lagoon.pdb.max-unavailable": "50%"},
+			want:   &PodDisruptionBudget{MaxUnavailable: &half},
+		},
+		{
+			name:    "test4 both defined",
+			labels:  composetypes.Labels{"lagoon.pdb.min-available": "1", "lagoon.pdb.max-unavailable": "1"},
+			wantErr: true,
+		},
+		{
+			name:    "test5 invalid number",
+			labels:  composetypes.Labels{"lagoon.pdb.min-available": "one"},
+			wantErr: true,
+		},
+		{
+			name:    "test6 percentage over 100",
+			labels:  composetypes.Labels{"lagoon.pdb.max-unavailable": "150%"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generatePodDisruptionBudget("nginx", tt.labels)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generatePodDisruptionBudget() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generatePodDisruptionBudget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
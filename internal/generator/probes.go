@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+)
+
+// Probes are the probe overrides for the primary container of a service, they are merged over the probes of the service type
+type Probes struct {
+	Readiness *Probe `json:"readiness,omitempty"`
+	Liveness  *Probe `json:"liveness,omitempty"`
+	Startup   *Probe `json:"startup,omitempty"`
+}
+
+// Probe is the override for a single probe, only the fields that are set replace the service type defaults.
+// Setting a path changes the probe to an http probe
+type Probe struct {
+	Disabled            bool   `json:"disabled,omitempty"`
+	Path                string `json:"path,omitempty"`
+	Port                int32  `json:"port,omitempty"`
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       *int32 `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      *int32 `json:"timeoutSeconds,omitempty"`
+	SuccessThreshold    *int32 `json:"successThreshold,omitempty"`
+	FailureThreshold    *int32 `json:"failureThreshold,omitempty"`
+}
+
+// probeSettings are the settings that can be defined with a `lagoon.probes.<probe>.<setting>` label
+var probeSettings = []string{
+	"disabled",
+	"path",
+	"port",
+	"initialDelaySeconds",
+	"periodSeconds",
+	"timeoutSeconds",
+	"successThreshold",
+	"failureThreshold",
+}
+
+// generateProbes returns the probe overrides for a service from the `lagoon.probes.readiness.*`, `lagoon.probes.liveness.*`
+// and `lagoon.probes.startup.*` labels, or nil if none are defined
+func generateProbes(composeService string, labels composetypes.Labels) (*Probes, error) {
+	probes := &Probes{}
+	var err error
+	if probes.Readiness, err = generateProbe(composeService, "readiness", labels); err != nil {
+		return nil, err
+	}
+	if probes.Liveness, err = generateProbe(composeService, "liveness", labels); err != nil {
+		return nil, err
+	}
+	if probes.Startup, err = generateProbe(composeService, "startup", labels); err != nil {
+		return nil, err
+	}
+	if probes.Readiness == nil && probes.Liveness == nil && probes.Startup == nil {
+		return nil, nil
+	}
+	return probes, nil
+}
+
+// generateProbe returns the override for one probe, or nil if it has no labels
+func generateProbe(composeService, probeName string, labels composetypes.Labels) (*Probe, error) {
+	var probe *Probe
+	for _, setting := range probeSettings {
+		label := fmt.Sprintf("lagoon.probes.%s.%s", probeName, setting)
+		value := lagoon.CheckDockerComposeLagoonLabel(labels, label)
+		if value == "" {
+			continue
+		}
+		if probe == nil {
+			probe = &Probe{}
+		}
+		switch setting {
+		case "disabled":
+			disabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("the value of the label %s for %s must be true or false, not %s", label, composeService, value)
+			}
+			probe.Disabled = disabled
+		case "path":
+			if !strings.HasPrefix(value, "/") {
+				return nil, fmt.Errorf("the value of the label %s for %s must be a path starting with /, not %s", label, composeService, value)
+			}
+			probe.Path = value
+		case "port":
+			i, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || i < 1 || i > 65535 {
+				return nil, fmt.Errorf("the value of the label %s for %s must be a port between 1 and 65535, not %s", label, composeService, value)
+			}
+			probe.Port = int32(i)
+		default:
+			// initial delay can be 0, every other setting must be at least 1
+			minValue := int64(1)
+			if setting == "initialDelaySeconds" {
+				minValue = 0
+			}
+			i, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || i < minValue {
+				return nil, fmt.Errorf("the value of the label %s for %s must be a number of at least %d, not %s", label, composeService, minValue, value)
+			}
+			seconds := int32(i)
+			switch setting {
+			case "initialDelaySeconds":
+				probe.InitialDelaySeconds = &seconds
+			case "periodSeconds":
+				probe.PeriodSeconds = &seconds
+			case "timeoutSeconds":
+				probe.TimeoutSeconds = &seconds
+			case "successThreshold":
+				probe.SuccessThreshold = &seconds
+			case "failureThreshold":
+				probe.FailureThreshold = &seconds
+			}
+		}
+	}
+	if probe == nil {
+		return nil, nil
+	}
+	// liveness and startup probes must have a success threshold of 1
+	if probeName != "readiness" && probe.SuccessThreshold != nil && *probe.SuccessThreshold != 1 {
+		return nil, fmt.Errorf("the %s probe success threshold for %s must be 1", probeName, composeService)
+	}
+	if probe.Disabled && (probe.Path != "" || probe.Port != 0) {
+		return nil, fmt.Errorf("the %s probe for %s is disabled, but a path or port is also defined", probeName, composeService)
+	}
+	return probe, nil
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+)
+
+func Test_generateProbes(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  composetypes.Labels
+		want    *Probes
+		wantErr bool
+	}{
+		{
+			name: "test1 no labels",
+		},
+		{
+			name: "test2 readiness path and timings",
+			labels: composetypes.Labels{
+				"lagoon.probes.readiness.path":                "/healthz",
+				"lagoon.probes.readiness.initialDelaySeconds": "0",
+				"lagoon.probes.readiness.periodSeconds":       "5",
+			},
+			want: &Probes{
+				Readiness: &Probe{
+					Path:                "/healthz",
+					InitialDelaySeconds: helpers.Int32Ptr(0),
+					PeriodSeconds:       helpers.Int32Ptr(5),
+				},
+			},
+		},
+		{
+			name: "test3 liveness disabled and startup port",
+			labels: composetypes.Labels{
+				"lagoon.probes.liveness.disabled":          "true",
+				"lagoon.probes.startup.port":               "8080",
+				"lagoon.probes.startup.failureThreshold":   "30",
+				"lagoon.probes.startup.successThreshold":   "1",
+				"lagoon.probes.readiness.timeoutSeconds":   "2",
+				"lagoon.probes.readiness.successThreshold": "2",
+			},
+			want: &Probes{
+				Readiness: &Probe{
+					TimeoutSeconds:   helpers.Int32Ptr(2),
+					SuccessThreshold: helpers.Int32Ptr(2),
+				},
+				Liveness: &Probe{
+					Disabled: true,
+				},
+				Startup: &Probe{
+					Port:             8080,
+					FailureThreshold: helpers.Int32Ptr(30),
+					SuccessThreshold: helpers.Int32Ptr(1),
+				},
+			},
+		},
+		{
+			name:    "test4 invalid path",
+			labels:  composetypes.Labels{"lagoon.probes.readiness.path": "healthz"},
+			wantErr: true,
+		},
+		{
+			name:    "test5 invalid port",
+			labels:  composetypes.Labels{"lagoon.probes.liveness.port": "70000"},
+			wantErr: true,
+		},
+		{
+			name:    "test6 invalid timing",
+			labels:  composetypes.Labels{"lagoon.probes.liveness.periodSeconds": "0"},
+			wantErr: true,
+		},
+		{
+			name:    "test7 invalid disabled",
+			labels:  composetypes.Labels{"lagoon.probes.liveness.disabled": "yes please"},
+			wantErr: true,
+		},
+		{
+			name:    "test8 liveness success threshold",
+			labels:  composetypes.Labels{"lagoon.probes.liveness.successThreshold": "3"},
+			wantErr: true,
+		},
+		{
+			name: "test9 disabled with a path",
+			labels: composetypes.Labels{
+				"lagoon.probes.readiness.disabled": "true",
+				"lagoon.probes.readiness.path":     "/healthz",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generateProbes("node", tt.labels)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generateProbes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generateProbes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+)
+
+// DanglingReference is a service or container referenced in the .lagoon.yml that doesn't exist in the docker-compose file
+type DanglingReference struct {
+	// Path is where the reference is in the .lagoon.yml, eg `environments.main.cronjobs[0].service`
+	Path      string `json:"path"`
+	Reference string `json:"reference"`
+	Reason    string `json:"reason"`
+}
+
+func (d DanglingReference) String() string {
+	return fmt.Sprintf("%s: %s", d.Path, d.Reason)
+}
+
+type composeReferences struct {
+	// the compose service names, and the lagoon.type of each
+	types map[string]string
+	// the lagoon.name of each compose service
+	overrideNames map[string]string
+	// services created from `lagoon.service.usecomposeports`
+	additionalServices []string
+}
+
+// CheckServiceReferences checks every service that the .lagoon.yml refers to exists in the docker-compose file. This covers
+// environment types and overrides, cronjobs, tasks, routes, and path routes. Unlike the build, all environments in the .lagoon.yml are checked.
+func CheckServiceReferences(lYAML lagoon.YAML, compose *composetypes.Project) []DanglingReference {
+	refs := composeReferences{
+		types:         map[string]string{},
+		overrideNames: map[string]string{},
+	}
+	for _, service := range compose.Services {
+		refs.types[service.Name] = lagoon.CheckDockerComposeLagoonLabel(service.Labels, "lagoon.type")
+		refs.overrideNames[service.Name] = service.Name
+		if lagoonOverrideName := lagoon.CheckDockerComposeLagoonLabel(service.Labels, "lagoon.name"); lagoonOverrideName != "" {
+			refs.overrideNames[service.Name] = lagoonOverrideName
+		}
+		if lagoon.CheckDockerComposeLagoonLabel(service.Labels, "lagoon.service.usecomposeports") == "true" {
+			for _, compPort := range service.Ports {
+				refs.additionalServices = append(refs.additionalServices, fmt.Sprintf("%s-%d", service.Name, compPort.Target))
+			}
+		}
+	}
+
+	dangling := []DanglingReference{}
+	for _, eName := range sortedKeys(lYAML.Environments) {
+		e := lYAML.Environments[eName]
+		path := fmt.Sprintf("environments.%s", eName)
+		for _, service := range sortedKeys(e.Types) {
+			dangling = refs.checkComposeService(dangling, fmt.Sprintf("%s.types.%s", path, service), service)
+		}
+		for _, service := range sortedKeys(e.Overrides) {
+			dangling = refs.checkComposeService(dangling, fmt.Sprintf("%s.overrides.%s", path, service), service)
+		}
+		for idx, cronjob := range e.Cronjobs {
+			cronPath := fmt.Sprintf("%s.cronjobs[%d].service", path, idx)
+			dangling = refs.checkComposeService(dangling, cronPath, cronjob.Service)
+			if refs.lagoonType(e, cronjob.Service) == "none" {
+				dangling = append(dangling, DanglingReference{
+					Path:      cronPath,
+					Reference: cronjob.Service,
+					Reason:    fmt.Sprintf("service %s has lagoon.type none, so cronjob %s will not run", cronjob.Service, cronjob.Name),
+				})
+			}
+		}
+		dangling = refs.checkRoutes(dangling, fmt.Sprintf("%s.routes", path), e.Routes)
+		for idx, pr := range e.AutogeneratePathRoutes {
+			dangling = refs.checkRouteService(dangling, fmt.Sprintf("%s.autogeneratePathRoutes[%d].fromService", path, idx), pr.FromService)
+			dangling = refs.checkRouteService(dangling, fmt.Sprintf("%s.autogeneratePathRoutes[%d].toService", path, idx), pr.ToService)
+		}
+	}
+	if lYAML.ProductionRoutes != nil {
+		if lYAML.ProductionRoutes.Active != nil {
+			dangling = refs.checkRoutes(dangling, "production_routes.active.routes", lYAML.ProductionRoutes.Active.Routes)
+		}
+		if lYAML.ProductionRoutes.Standby != nil {
+			dangling = refs.checkRoutes(dangling, "production_routes.standby.routes", lYAML.ProductionRoutes.Standby.Routes)
+		}
+	}
+	for idx, pr := range lYAML.Routes.Autogenerate.PathRoutes {
+		dangling = refs.checkRouteService(dangling, fmt.Sprintf("routes.autogenerate.pathRoutes[%d].fromService", idx), pr.FromService)
+		dangling = refs.checkRouteService(dangling, fmt.Sprintf("routes.autogenerate.pathRoutes[%d].toService", idx), pr.ToService)
+	}
+	for idx, task := range lYAML.Tasks.Prerollout {
+		dangling = refs.checkTask(dangling, fmt.Sprintf("tasks.pre-rollout[%d].run", idx), task.Run)
+	}
+	for idx, task := range lYAML.Tasks.Postrollout {
+		dangling = refs.checkTask(dangling, fmt.Sprintf("tasks.post-rollout[%d].run", idx), task.Run)
+	}
+	return dangling
+}
+
+// lagoonType returns the lagoon.type of a compose service, taking into account any override in the environment
+func (r composeReferences) lagoonType(e lagoon.Environment, service string) string {
+	if value, ok := e.Types[service]; ok {
+		return value
+	}
+	return r.types[service]
+}
+
+// checkComposeService checks that the service is the name of a docker-compose service
+func (r composeReferences) checkComposeService(dangling []DanglingReference, path, service string) []DanglingReference {
+	if _, ok := r.types[service]; ok {
+		return dangling
+	}
+	return append(dangling, DanglingReference{
+		Path:      path,
+		Reference: service,
+		Reason:    fmt.Sprintf("service %s doesn't exist in the docker-compose file", service),
+	})
+}
+
+// checkRouteService checks that the service could be the backend of a route, the same as checkServiceInServices during a build
+func (r composeReferences) checkRouteService(dangling []DanglingReference, path, service string) []DanglingReference {
+	if service == "" {
+		return dangling
+	}
+	if _, ok := r.types[service]; ok {
+		return dangling
+	}
+	for _, overrideName := range r.overrideNames {
+		if overrideName == service {
+			return dangling
+		}
+	}
+	if helpers.Contains(r.additionalServices, service) {
+		return dangling
+	}
+	return append(dangling, DanglingReference{
+		Path:      path,
+		Reference: service,
+		Reason:    fmt.Sprintf("service %s doesn't exist in the docker-compose file", service),
+	})
+}
+
+func (r composeReferences) checkRoutes(dangling []DanglingReference, path string, routes []map[string][]lagoon.Route) []DanglingReference {
+	for idx, routeMap := range routes {
+		for _, service := range sortedKeys(routeMap) {
+			servicePath := fmt.Sprintf("%s[%d].%s", path, idx, service)
+			dangling = r.checkRouteService(dangling, servicePath, service)
+			for rIdx, route := range routeMap[service] {
+				for _, domain := range sortedKeys(route.Ingresses) {
+					for pIdx, pr := range route.Ingresses[domain].PathRoutes {
+						dangling = r.checkRouteService(dangling, fmt.Sprintf("%s[%d][%s].pathRoutes[%d].toService", servicePath, rIdx, domain, pIdx), pr.ToService)
+					}
+				}
+			}
+		}
+	}
+	return dangling
+}
+
+// checkTask checks the service a task runs in exists, tasks select the deployment using the `lagoon.sh/service` label so
+// this is the lagoon.name of the service. If a container is defined, it must be one of the containers of the service.
+func (r composeReferences) checkTask(dangling []DanglingReference, path string, task lagoon.Task) []DanglingReference {
+	if task.Service == "" {
+		return dangling
+	}
+	containers := []string{}
+	found := false
+	for composeService, overrideName := range r.overrideNames {
+		if overrideName != task.Service {
+			continue
+		}
+		found = true
+		if sType, ok := servicetypes.ServiceTypes[r.types[composeService]]; ok {
+			containers = append(containers, sType.PrimaryContainer.Name)
+			if sType.SecondaryContainer.Name != "" {
+				containers = append(containers, sType.SecondaryContainer.Name)
+			}
+		} else {
+			// the containers of this type aren't known, so the container can't be checked
+			containers = append(containers, task.Container)
+		}
+	}
+	if !found {
+		return append(dangling, DanglingReference{
+			Path:      path + ".service",
+			Reference: task.Service,
+			Reason:    fmt.Sprintf("service %s doesn't exist in the docker-compose file", task.Service),
+		})
+	}
+	if task.Container != "" && !helpers.Contains(containers, task.Container) {
+		return append(dangling, DanglingReference{
+			Path:      path + ".container",
+			Reference: task.Container,
+			Reason:    fmt.Sprintf("container %s doesn't exist in service %s", task.Container, task.Service),
+		})
+	}
+	return dangling
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+)
+
+func TestCheckServiceReferences(t *testing.T) {
+	compose := &composetypes.Project{
+		Services: composetypes.Services{
+			{Name: "cli", Labels: composetypes.Labels{"lagoon.type": "cli-persistent"}},
+			{Name: "nginx", Labels: composetypes.Labels{"lagoon.type": "nginx-php-persistent", "lagoon.name": "nginx-php"}},
+			{Name: "php", Labels: composetypes.Labels{"lagoon.type": "nginx-php-persistent", "lagoon.name": "nginx-php"}},
+			{Name: "redis", Labels: composetypes.Labels{"lagoon.type": "none"}},
+			{
+				Name:   "node",
+				Labels: composetypes.Labels{"lagoon.type": "basic", "lagoon.service.usecomposeports": "true"},
+				Ports:  []composetypes.ServicePortConfig{{Target: 1234}},
+			},
+		},
+	}
+	tests := []struct {
+		name  string
+		lYAML lagoon.YAML
+		want  []DanglingReference
+	}{
+		{
+			name: "test1 all references exist",
+			lYAML: lagoon.YAML{
+				Environments: lagoon.Environments{
+					"main": lagoon.Environment{
+						Types:     map[string]string{"redis": "redis"},
+						Overrides: map[string]lagoon.Override{"cli": {Image: "example/cli"}},
+						Cronjobs: []lagoon.Cronjob{
+							{Name: "drush cron", Service: "cli"},
+							{Name: "redis cron", Service: "redis"},
+						},
+						Routes: []map[string][]lagoon.Route{
+							{
+								"nginx-php": {
+									{Name: "a.example.com"},
+									{Ingresses: map[string]lagoon.Ingress{
+										"b.example.com": {PathRoutes: []lagoon.PathRoute{{ToService: "node-1234", Path: "/api"}}},
+									}},
+								},
+								"nginx": {{Name: "c.example.com"}},
+							},
+						},
+					},
+				},
+				Tasks: lagoon.Tasks{
+					Postrollout: []lagoon.TaskRun{
+						{Run: lagoon.Task{Name: "drush cr", Service: "cli"}},
+						{Run: lagoon.Task{Name: "php", Service: "nginx-php", Container: "php"}},
+					},
+				},
+			},
+			want: []DanglingReference{},
+		},
+		{
+			name: "test2 dangling references",
+			lYAML: lagoon.YAML{
+				Environments: lagoon.Environments{
+					"main": lagoon.Environment{
+						Types:     map[string]string{"mariadb": "mariadb-single"},
+						Overrides: map[string]lagoon.Override{"clii": {Image: "example/cli"}},
+						Cronjobs: []lagoon.Cronjob{
+							{Name: "drush cron", Service: "clli"},
+							{Name: "redis cron", Service: "redis"},
+						},
+						Routes: []map[string][]lagoon.Route{
+							{
+								"varnish": {{Name: "a.example.com"}},
+								"nginx-php": {
+									{Ingresses: map[string]lagoon.Ingress{
+										"b.example.com": {PathRoutes: []lagoon.PathRoute{{ToService: "node-8080", Path: "/api"}}},
+									}},
+								},
+							},
+						},
+						AutogeneratePathRoutes: []lagoon.AutogeneratePathRoute{
+							{FromService: "nginx", PathRoute: lagoon.PathRoute{ToService: "api", Path: "/api"}},
+						},
+					},
+				},
+				Routes: lagoon.Routes{
+					Autogenerate: lagoon.Autogenerate{
+						PathRoutes: []lagoon.AutogeneratePathRoute{
+							{FromService: "web", PathRoute: lagoon.PathRoute{ToService: "node", Path: "/api"}},
+						},
+					},
+				},
+				Tasks: lagoon.Tasks{
+					Prerollout: []lagoon.TaskRun{
+						{Run: lagoon.Task{Name: "drush cr", Service: "nginx"}},
+					},
+					Postrollout: []lagoon.TaskRun{
+						{Run: lagoon.Task{Name: "php", Service: "nginx-php", Container: "cli"}},
+					},
+				},
+			},
+			want: []DanglingReference{
+				{Path: "environments.main.types.mariadb", Reference: "mariadb", Reason: "service mariadb doesn't exist in the docker-compose file"},
+				{Path: "environments.main.overrides.clii", Reference: "clii", Reason: "service clii doesn't exist in the docker-compose file"},
+				{Path: "environments.main.cronjobs[0].service", Reference: "clli", Reason: "service clli doesn't exist in the docker-compose file"},
+				{Path: "environments.main.cronjobs[1].service", Reference: "redis", Reason: "service redis has lagoon.type none, so cronjob redis cron will not run"},
+				{Path: "environments.main.routes[0].nginx-php[0][b.example.com].pathRoutes[0].toService", Reference: "node-8080", Reason: "service node-8080 doesn't exist in the docker-compose file"},
+				{Path: "environments.main.routes[0].varnish", Reference: "varnish", Reason: "service varnish doesn't exist in the docker-compose file"},
+				{Path: "environments.main.autogeneratePathRoutes[0].toService", Reference: "api", Reason: "service api doesn't exist in the docker-compose file"},
+				{Path: "routes.autogenerate.pathRoutes[0].fromService", Reference: "web", Reason: "service web doesn't exist in the docker-compose file"},
+				{Path: "tasks.pre-rollout[0].run.service", Reference: "nginx", Reason: "service nginx doesn't exist in the docker-compose file"},
+				{Path: "tasks.post-rollout[0].run.container", Reference: "cli", Reason: "container cli doesn't exist in service nginx-php"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckServiceReferences(tt.lYAML, compose)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckServiceReferences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
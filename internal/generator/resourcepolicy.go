@@ -0,0 +1,216 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ResourcePolicyClamp changes any resource requirement outside the policy to the nearest allowed value
+	ResourcePolicyClamp = "clamp"
+	// ResourcePolicyReject fails the build if any resource requirement is outside the policy
+	ResourcePolicyReject = "reject"
+)
+
+// ResourcePolicy is the cluster policy for the resource requirements that services can request with the `lagoon.resources.*` labels
+type ResourcePolicy struct {
+	// Mode is either `clamp` (the default) or `reject`
+	Mode string `json:"mode,omitempty"`
+	// EnvironmentTypes is the policy for each environment type (production or development)
+	EnvironmentTypes map[string]ResourcePolicyEnvironment `json:"environmentTypes"`
+}
+
+// ResourcePolicyEnvironment is the resource policy for one environment type, the resource names are `cpu`, `memory` and `ephemeral-storage`
+type ResourcePolicyEnvironment struct {
+	Requests map[string]ResourcePolicyRange `json:"requests,omitempty"`
+	Limits   map[string]ResourcePolicyRange `json:"limits,omitempty"`
+	// MaxLimitRequestRatio is the largest a limit can be as a multiple of the request, eg `4`
+	MaxLimitRequestRatio map[string]float64 `json:"maxLimitRequestRatio,omitempty"`
+}
+
+// ResourcePolicyRange is the allowed range of a resource, and the value to use if a service doesn't request one
+type ResourcePolicyRange struct {
+	Min     string `json:"min,omitempty"`
+	Max     string `json:"max,omitempty"`
+	Default string `json:"default,omitempty"`
+}
+
+// policyResources are the resources a policy can be defined for
+var policyResources = []string{"cpu", "memory", "ephemeral-storage"}
+
+// LoadResourcePolicy returns the resource policy from the provided yaml file, or from the inline yaml or json of the
+// ADMIN_LAGOON_FEATURE_FLAG_RESOURCE_POLICY admin feature flag. It returns nil if there is no policy
+func LoadResourcePolicy(file string, debug bool) (*ResourcePolicy, error) {
+	data := []byte(CheckAdminFeatureFlag("RESOURCE_POLICY", debug))
+	source := "the resource policy admin feature flag"
+	if file != "" {
+		if len(data) > 0 {
+			return nil, fmt.Errorf("a resource policy is defined in both %v and the admin feature flag, only one can be used", file)
+		}
+		var err error
+		data, err = os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read %v: %v", file, err)
+		}
+		source = file
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	policy := &ResourcePolicy{}
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal %v: %v", source, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("the resource policy in %v is not valid: %v", source, err)
+	}
+	return policy, nil
+}
+
+func (p *ResourcePolicy) validate() error {
+	switch p.Mode {
+	case "":
+		p.Mode = ResourcePolicyClamp
+	case ResourcePolicyClamp, ResourcePolicyReject:
+	default:
+		return fmt.Errorf("mode must be %s or %s, not %s", ResourcePolicyClamp, ResourcePolicyReject, p.Mode)
+	}
+	for envType, env := range p.EnvironmentTypes {
+		for kind, ranges := range map[string]map[string]ResourcePolicyRange{"requests": env.Requests, "limits": env.Limits} {
+			for name, r := range ranges {
+				if !helpers.Contains(policyResources, name) {
+					return fmt.Errorf("%s: %s.%s is not a resource a policy can be defined for", envType, kind, name)
+				}
+				for _, value := range []string{r.Min, r.Max, r.Default} {
+					if value == "" {
+						continue
+					}
+					if err := ValidateResourceQuantity(value); err != nil {
+						return fmt.Errorf("%s: %s.%s value %s is not a valid resource quantity", envType, kind, name, value)
+					}
+				}
+				if r.Min != "" && r.Max != "" && quantityCmp(r.Min, r.Max) > 0 {
+					return fmt.Errorf("%s: %s.%s min %s is more than the max %s", envType, kind, name, r.Min, r.Max)
+				}
+				// the default is used as is, so it has to be allowed by the policy too
+				if r.Default != "" && r.Min != "" && quantityCmp(r.Default, r.Min) < 0 {
+					return fmt.Errorf("%s: %s.%s default %s is less than the min %s", envType, kind, name, r.Default, r.Min)
+				}
+				if r.Default != "" && r.Max != "" && quantityCmp(r.Default, r.Max) > 0 {
+					return fmt.Errorf("%s: %s.%s default %s is more than the max %s", envType, kind, name, r.Default, r.Max)
+				}
+			}
+		}
+		for name, ratio := range env.MaxLimitRequestRatio {
+			if !helpers.Contains(policyResources, name) {
+				return fmt.Errorf("%s: maxLimitRequestRatio.%s is not a resource a policy can be defined for", envType, name)
+			}
+			if ratio < 1 {
+				return fmt.Errorf("%s: maxLimitRequestRatio.%s must be at least 1, not %v", envType, name, ratio)
+			}
+		}
+	}
+	return nil
+}
+
+// applyResourcePolicy applies the resource policy for the environment type of the build to the resources of a container,
+// any value that is outside the policy is clamped (and a warning is raised) or rejected depending on the policy mode
+func applyResourcePolicy(buildValues *BuildValues, composeService, container string, resources *Resources) error {
+	if buildValues.ResourcePolicy == nil {
+		return nil
+	}
+	env, ok := buildValues.ResourcePolicy.EnvironmentTypes[buildValues.EnvironmentType]
+	if !ok {
+		return nil
+	}
+	name := composeService
+	if container != "" {
+		name = fmt.Sprintf("the %s container of %s", container, composeService)
+	}
+	// adjust returns an error if the policy rejects values, otherwise it records the adjustment as a warning
+	adjust := func(value, message string) error {
+		if buildValues.ResourcePolicy.Mode == ResourcePolicyReject {
+			return fmt.Errorf("%s for %s is not allowed by the resource policy of this cluster", message, name)
+		}
+		buildValues.addWarning(WarningResourcePolicyAdjusted, composeService, buildValues.LagoonYAML.DockerComposeYAML,
+			"%s for %s is not allowed by the resource policy of this cluster, using %s", message, name, value)
+		return nil
+	}
+	for _, r := range policyResources {
+		request, limit := resourceRequestValue(&resources.Requests, r), resourceLimitValue(&resources.Limits, r)
+		for _, v := range []struct {
+			kind   string
+			value  *string
+			policy ResourcePolicyRange
+		}{
+			{"request", request, env.Requests[r]},
+			{"limit", limit, env.Limits[r]},
+		} {
+			if *v.value == "" {
+				*v.value = v.policy.Default
+				continue
+			}
+			if v.policy.Min != "" && quantityCmp(*v.value, v.policy.Min) < 0 {
+				if err := adjust(v.policy.Min, fmt.Sprintf("the %s %s %s is less than the minimum %s", r, v.kind, *v.value, v.policy.Min)); err != nil {
+					return err
+				}
+				*v.value = v.policy.Min
+			}
+			if v.policy.Max != "" && quantityCmp(*v.value, v.policy.Max) > 0 {
+				if err := adjust(v.policy.Max, fmt.Sprintf("the %s %s %s is more than the maximum %s", r, v.kind, *v.value, v.policy.Max)); err != nil {
+					return err
+				}
+				*v.value = v.policy.Max
+			}
+		}
+		if *request == "" || *limit == "" {
+			continue
+		}
+		if ratio, ok := env.MaxLimitRequestRatio[r]; ok {
+			requestQuantity, limitQuantity := resource.MustParse(*request), resource.MustParse(*limit)
+			maxLimit := resource.NewMilliQuantity(int64(float64(requestQuantity.MilliValue())*ratio), requestQuantity.Format)
+			if limitQuantity.Cmp(*maxLimit) > 0 {
+				if err := adjust(maxLimit.String(), fmt.Sprintf("the %s limit %s is more than %v times the request %s", r, *limit, ratio, *request)); err != nil {
+					return err
+				}
+				*limit = maxLimit.String()
+			}
+		}
+		if quantityCmp(*request, *limit) > 0 {
+			return fmt.Errorf("the %s request %s for %s is more than the limit %s after applying the resource policy of this cluster", r, *request, name, *limit)
+		}
+	}
+	return nil
+}
+
+// resourceRequestValue returns the request value for a resource name
+func resourceRequestValue(requests *ResourceRequests, name string) *string {
+	switch name {
+	case "cpu":
+		return &requests.Cpu
+	case "memory":
+		return &requests.Memory
+	}
+	return &requests.EphemeralStorage
+}
+
+// resourceLimitValue returns the limit value for a resource name
+func resourceLimitValue(limits *ResourceLimits, name string) *string {
+	switch name {
+	case "cpu":
+		return &limits.Cpu
+	case "memory":
+		return &limits.Memory
+	}
+	return &limits.EphemeralStorage
+}
+
+// quantityCmp compares two already validated resource quantities
+func quantityCmp(a, b string) int {
+	qa, qb := resource.MustParse(a), resource.MustParse(b)
+	return qa.Cmp(qb)
+}
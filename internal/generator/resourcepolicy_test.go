@@ -0,0 +1,181 @@
+package generator
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestLoadResourcePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		flag     string
+		wantMode string
+		wantNil  bool
+		wantErr  bool
+	}{
+		{
+			name:    "test1 no policy",
+			wantNil: true,
+		},
+		{
+			name:     "test2 policy file",
+			file:     "../testdata/resource-policy/resource-policy.yml",
+			wantMode: ResourcePolicyClamp,
+		},
+		{
+			name:     "test3 policy admin feature flag",
+			flag:     `{"mode":"reject","environmentTypes":{"production":{"limits":{"cpu":{"max":"2"}}}}}`,
+			wantMode: ResourcePolicyReject,
+		},
+		{
+			name:    "test4 policy file and admin feature flag",
+			file:    "../testdata/resource-policy/resource-policy.yml",
+			flag:    `{"mode":"reject"}`,
+			wantErr: true,
+		},
+		{
+			name:    "test5 unknown resource",
+			file:    "../testdata/resource-policy/resource-policy-invalid.yml",
+			wantErr: true,
+		},
+		{
+			name:    "test6 invalid mode",
+			flag:    `{"mode":"ignore"}`,
+			wantErr: true,
+		},
+		{
+			name:    "test7 min more than max",
+			flag:    `{"environmentTypes":{"production":{"requests":{"cpu":{"min":"2","max":"1"}}}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "test8 default less than min",
+			flag:    `{"environmentTypes":{"production":{"requests":{"memory":{"min":"100Mi","default":"10Mi"}}}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "test9 default more than max",
+			flag:    `{"environmentTypes":{"production":{"limits":{"cpu":{"max":"2","default":"4"}}}}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.flag != "" {
+				os.Setenv("ADMIN_LAGOON_FEATURE_FLAG_RESOURCE_POLICY", tt.flag)
+			}
+			defer os.Unsetenv("ADMIN_LAGOON_FEATURE_FLAG_RESOURCE_POLICY")
+			got, err := LoadResourcePolicy(tt.file, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadResourcePolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("LoadResourcePolicy() = %v, wantNil %v", got, tt.wantNil)
+				return
+			}
+			if got != nil && got.Mode != tt.wantMode {
+				t.Errorf("LoadResourcePolicy() mode = %v, want %v", got.Mode, tt.wantMode)
+			}
+		})
+	}
+}
+
+func Test_applyResourcePolicy(t *testing.T) {
+	policy, err := LoadResourcePolicy("../testdata/resource-policy/resource-policy.yml", false)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	rejectPolicy := *policy
+	rejectPolicy.Mode = ResourcePolicyReject
+	tests := []struct {
+		name            string
+		policy          *ResourcePolicy
+		environmentType string
+		resources       Resources
+		want            Resources
+		wantWarnings    int
+		wantErr         bool
+	}{
+		{
+			name:            "test1 no policy",
+			environmentType: "production",
+			resources:       Resources{Requests: ResourceRequests{Cpu: "8"}},
+			want:            Resources{Requests: ResourceRequests{Cpu: "8"}},
+		},
+		{
+			name:            "test2 environment type without a policy",
+			policy:          policy,
+			environmentType: "staging",
+			resources:       Resources{Requests: ResourceRequests{Cpu: "8"}},
+			want:            Resources{Requests: ResourceRequests{Cpu: "8"}},
+		},
+		{
+			name:            "test3 defaults",
+			policy:          policy,
+			environmentType: "production",
+			resources:       Resources{Requests: ResourceRequests{Cpu: "100m"}},
+			want:            Resources{Requests: ResourceRequests{Cpu: "100m", Memory: "100Mi"}},
+		},
+		{
+			name:            "test4 clamped to the min and max",
+			policy:          policy,
+			environmentType: "production",
+			resources: Resources{
+				Requests: ResourceRequests{Cpu: "1m", Memory: "1Gi"},
+				Limits:   ResourceLimits{Memory: "16Gi"},
+			},
+			want: Resources{
+				Requests: ResourceRequests{Cpu: "10m", Memory: "1Gi"},
+				Limits:   ResourceLimits{Memory: "4Gi"},
+			},
+			// the memory limit is clamped to the max, then to 4 times the request
+			wantWarnings: 3,
+		},
+		{
+			name:            "test5 rejected",
+			policy:          &rejectPolicy,
+			environmentType: "production",
+			resources:       Resources{Requests: ResourceRequests{Cpu: "4"}},
+			wantErr:         true,
+		},
+		{
+			name:            "test6 request more than the limit",
+			policy:          policy,
+			environmentType: "development",
+			resources: Resources{
+				Requests: ResourceRequests{Memory: "1Gi"},
+				Limits:   ResourceLimits{Memory: "512Mi"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buildValues := &BuildValues{
+				EnvironmentType: tt.environmentType,
+				ResourcePolicy:  tt.policy,
+			}
+			resources := tt.resources
+			err := applyResourcePolicy(buildValues, "nginx", "", &resources)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("applyResourcePolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(resources, tt.want) {
+				t.Errorf("applyResourcePolicy() = %v, want %v", resources, tt.want)
+			}
+			if len(buildValues.Warnings) != tt.wantWarnings {
+				t.Errorf("applyResourcePolicy() warnings = %v, want %v", buildValues.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
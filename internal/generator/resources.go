@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"fmt"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// resourceLabel returns the label and value of a resource requirement label, overrides are checked in the order
+//   - the branch override (example: lagoon.resources.override-branch.main.requests.cpu)
+//   - the pull request override, for all pull request environments (example: lagoon.resources.override-pr.requests.cpu)
+//   - the environment type override (example: lagoon.resources.override-environment-type.production.requests.cpu)
+//   - the base label (example: lagoon.resources.requests.cpu)
+//
+// if a container is provided, the labels are for that container (example: lagoon.resources.php.requests.cpu)
+func resourceLabel(buildValues *BuildValues, labels composetypes.Labels, container, suffix string) (string, string) {
+	if container != "" {
+		suffix = fmt.Sprintf("%s.%s", container, suffix)
+	}
+	overrideLabels := []string{
+		fmt.Sprintf("lagoon.resources.override-branch.%s.%s", buildValues.Branch, suffix),
+	}
+	if buildValues.BuildType == "pullrequest" {
+		overrideLabels = append(overrideLabels, fmt.Sprintf("lagoon.resources.override-pr.%s", suffix))
+	}
+	overrideLabels = append(overrideLabels,
+		fmt.Sprintf("lagoon.resources.override-environment-type.%s.%s", buildValues.EnvironmentType, suffix),
+		fmt.Sprintf("lagoon.resources.%s", suffix),
+	)
+	for _, label := range overrideLabels {
+		if value := lagoon.CheckDockerComposeLagoonLabel(labels, label); value != "" {
+			return label, value
+		}
+	}
+	return "", ""
+}
+
+// generateResources returns the resource requirements for a container of a service from the `lagoon.resources.*` labels
+func generateResources(buildValues *BuildValues, composeService string, labels composetypes.Labels, container string) (Resources, error) {
+	resources := Resources{}
+	for _, r := range []struct {
+		suffix string
+		dest   *string
+	}{
+		{"requests.cpu", &resources.Requests.Cpu},
+		{"requests.memory", &resources.Requests.Memory},
+		{"requests.ephemeral-storage", &resources.Requests.EphemeralStorage},
+		{"limits.cpu", &resources.Limits.Cpu},
+		{"limits.memory", &resources.Limits.Memory},
+		{"limits.ephemeral-storage", &resources.Limits.EphemeralStorage},
+	} {
+		label, value := resourceLabel(buildValues, labels, container, r.suffix)
+		if value == "" {
+			continue
+		}
+		if err := ValidateResourceQuantity(value); err != nil {
+			return resources, fmt.Errorf("Value of resource requirement label %s for %s is not valid resource quantity: %v", label, composeService, err)
+		}
+		*r.dest = value
+	}
+	// requests can't be more than the limits
+	for _, r := range []struct {
+		name    string
+		request string
+		limit   string
+	}{
+		{"cpu", resources.Requests.Cpu, resources.Limits.Cpu},
+		{"memory", resources.Requests.Memory, resources.Limits.Memory},
+		{"ephemeral-storage", resources.Requests.EphemeralStorage, resources.Limits.EphemeralStorage},
+	} {
+		if r.request == "" || r.limit == "" {
+			continue
+		}
+		request, limit := resource.MustParse(r.request), resource.MustParse(r.limit)
+		if request.Cmp(limit) > 0 {
+			name := composeService
+			if container != "" {
+				name = fmt.Sprintf("the %s container of %s", container, composeService)
+			}
+			return resources, fmt.Errorf("the %s request %s for %s is more than the limit %s", r.name, r.request, name, r.limit)
+		}
+	}
+	return resources, nil
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+)
+
+func Test_generateResources(t *testing.T) {
+	tests := []struct {
+		name        string
+		buildValues *BuildValues
+		labels      composetypes.Labels
+		container   string
+		want        Resources
+		wantErr     bool
+	}{
+		{
+			name:        "test1 no labels",
+			buildValues: &BuildValues{Branch: "main", BuildType: "branch", EnvironmentType: "production"},
+		},
+		{
+			name:        "test2 base labels with ephemeral storage",
+			buildValues: &BuildValues{Branch: "main", BuildType: "branch", EnvironmentType: "production"},
+			labels: composetypes.Labels{
+				"lagoon.resources.requests.cpu":               "100m",
+				"lagoon.resources.limits.memory":              "1Gi",
+				"lagoon.resources.requests.ephemeral-storage": "1Gi",
+				"lagoon.resources.limits.ephemeral-storage":   "2Gi",
+			},
+			want: Resources{
+				Requests: ResourceRequests{Cpu: "100m", EphemeralStorage: "1Gi"},
+				Limits:   ResourceLimits{Memory: "1Gi", EphemeralStorage: "2Gi"},
+			},
+		},
+		{
+			name:        "test3 branch override over environment type override",
+			buildValues: &BuildValues{Branch: "main", BuildType: "branch", EnvironmentType: "production"},
+			labels: composetypes.Labels{
+				"lagoon.resources.limits.memory":                                      "1Gi",
+				"lagoon.resources.override-environment-type.production.limits.memory": "2Gi",
+				"lagoon.resources.override-environment-type.production.limits.cpu":    "1",
+				"lagoon.resources.override-environment-type.development.limits.cpu":   "500m",
+				"lagoon.resources.override-branch.main.limits.memory":                 "4Gi",
+			},
+			want: Resources{
+				Limits: ResourceLimits{Cpu: "1", Memory: "4Gi"},
+			},
+		},
+		{
+			name:        "test4 pull request override",
+			buildValues: &BuildValues{Branch: "pr-12", BuildType: "pullrequest", EnvironmentType: "development"},
+			labels: composetypes.Labels{
+				"lagoon.resources.limits.memory":                                       "1Gi",
+				"lagoon.resources.override-environment-type.development.limits.memory": "2Gi",
+				"lagoon.resources.override-pr.limits.memory":                           "512Mi",
+			},
+			want: Resources{
+				Limits: ResourceLimits{Memory: "512Mi"},
+			},
+		},
+		{
+			name:        "test5 pull request override on a branch",
+			buildValues: &BuildValues{Branch: "main", BuildType: "branch", EnvironmentType: "development"},
+			labels: composetypes.Labels{
+				"lagoon.resources.limits.memory":             "1Gi",
+				"lagoon.resources.override-pr.limits.memory": "512Mi",
+			},
+			want: Resources{
+				Limits: ResourceLimits{Memory: "1Gi"},
+			},
+		},
+		{
+			name:        "test6 secondary container",
+			buildValues: &BuildValues{Branch: "main", BuildType: "branch", EnvironmentType: "production"},
+			labels: composetypes.Labels{
+				"lagoon.resources.limits.memory":                            "256Mi",
+				"lagoon.resources.php.limits.memory":                        "1Gi",
+				"lagoon.resources.override-branch.main.php.requests.memory": "512Mi",
+			},
+			container: "php",
+			want: Resources{
+				Requests: ResourceRequests{Memory: "512Mi"},
+				Limits:   ResourceLimits{Memory: "1Gi"},
+			},
+		},
+		{
+			name:        "test7 request more than limit",
+			buildValues: &BuildValues{Branch: "main", BuildType: "branch", EnvironmentType: "production"},
+			labels: composetypes.Labels{
+				"lagoon.resources.requests.memory": "2Gi",
+				"lagoon.resources.limits.memory":   "1Gi",
+			},
+			wantErr: true,
+		},
+		{
+			name:        "test8 invalid quantity",
+			buildValues: &BuildValues{Branch: "main", BuildType: "branch", EnvironmentType: "production"},
+			labels: composetypes.Labels{
+				"lagoon.resources.limits.ephemeral-storage": "lots",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generateResources(tt.buildValues, "nginx", tt.labels, tt.container)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("generateResources() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generateResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
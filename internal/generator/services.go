@@ -39,23 +39,6 @@ var supportedAutogeneratedTypes = []string{
 	"python",
 }
 
-// these service types don't have images
-var ignoredImageTypes = []string{
-	"mariadb-dbaas",
-	"postgres-dbaas",
-	"mongodb-dbaas",
-}
-
-// these are lagoon types that support autogenerated routes
-var supportedDBTypes = []string{
-	"mariadb",
-	"mariadb-dbaas",
-	"postgres",
-	"postgres-dbaas",
-	"mongodb",
-	"mongodb-dbaas",
-}
-
 // these are lagoon types that come with resources requiring backups
 var typesWithBackups = []string{
 	"basic-persistent",
@@ -136,7 +119,8 @@ func generateServicesFromDockerCompose(
 			}
 		}
 	}
-	return nil
+	// sidecars can be defined before the service they belong to, so check them once all services are known
+	return checkSidecars(buildValues)
 }
 
 // composeToServiceValues is the primary function used to pre-seed how templates are created
@@ -148,6 +132,13 @@ func composeToServiceValues(
 	composeServiceValues composetypes.ServiceConfig,
 	debug bool,
 ) (*ServiceValues, error) {
+	// sidecars and init containers are added to the deployment of another service, and don't need a lagoon.type
+	if sidecarOf := lagoon.CheckDockerComposeLagoonLabel(composeServiceValues.Labels, "lagoon.sidecar.of"); sidecarOf != "" {
+		return generateSidecarValues(buildValues, composeService, sidecarOf, SidecarServiceType, composeServiceValues)
+	}
+	if initOf := lagoon.CheckDockerComposeLagoonLabel(composeServiceValues.Labels, "lagoon.init.of"); initOf != "" {
+		return generateSidecarValues(buildValues, composeService, initOf, InitContainerServiceType, composeServiceValues)
+	}
 	lagoonType := ""
 	// if there are no labels, then this is probably not going to end up in Lagoon
 	// the lagoonType check will skip to the end and return an empty service definition
@@ -277,23 +268,28 @@ func composeToServiceValues(
 		dbaasEnvironment := buildValues.EnvironmentType
 		svcIsDBaaS := false
 		svcIsSingle := false
-		if helpers.Contains(supportedDBTypes, lagoonType) {
+		if dbaasType, ok := buildValues.dbaasType(lagoonType); ok {
 			// strip the dbaas off the supplied type for checking against providers, it gets added again later
-			lagoonType = strings.Split(lagoonType, "-dbaas")[0]
+			lagoonType = dbaasType.Name
+			// the `lagoon.dbaas.fallback-single` label on a service overrides the DBAAS_FALLBACK_SINGLE flag
+			fallbackSingle := buildValues.DBaaSFallbackSingle
+			if fallbackLabel := lagoon.CheckDockerComposeLagoonLabel(composeServiceValues.Labels, "lagoon.dbaas.fallback-single"); fallbackLabel != "" {
+				fallbackSingle = helpers.StrToBool(fallbackLabel)
+			}
 			err := buildValues.DBaaSClient.CheckHealth(buildValues.DBaaSOperatorEndpoint)
 			if err != nil {
-				// @TODO eventually this error should be handled and fail a build, with a flag to override https://github.com/uselagoon/build-deploy-tool/issues/56
-				// if !buildValues.DBaaSFallbackSingle {
-				// 	return nil, fmt.Errorf("unable to check the DBaaS endpoint %s: %v", buildValues.DBaaSOperatorEndpoint, err)
-				// }
-				if debug {
-					fmt.Printf("Unable to check the DBaaS endpoint %s, falling back to %s-single: %v\n", buildValues.DBaaSOperatorEndpoint, lagoonType, err)
+				if !fallbackSingle {
+					return nil, fmt.Errorf("service %s: unable to check the DBaaS endpoint %s for a %s provider in the %s DBaaS environment, and falling back to %s is disabled: %v",
+						composeService, buildValues.DBaaSOperatorEndpoint, lagoonType, dbaasEnvironment, dbaasType.SingleType, err)
 				}
+				buildValues.addWarning(WarningDBaaSUnavailable, composeService, buildValues.LagoonYAML.DockerComposeYAML,
+					"unable to check the DBaaS endpoint %s, falling back to %s: %v", buildValues.DBaaSOperatorEndpoint, dbaasType.SingleType, err)
 				// normally we would fall back to doing a cluster capability check, this is phased out in the build tool, it isn't reliable
 				// and noone should be doing checks that way any more
 				// the old bash check is the following
 				// elif [[ "${CAPABILITIES[@]}" =~ "mariadb.amazee.io/v1/MariaDBConsumer" ]] && ! checkDBaaSHealth ; then
-				lagoonType = fmt.Sprintf("%s-single", lagoonType)
+				lagoonType = dbaasType.SingleType
+				svcIsSingle = true
 			} else {
 				// if there is a `lagoon.%s-dbaas.environment` label on this service, this should be used as an the environment type for the dbaas
 				dbaasLabelOverride := lagoon.CheckDockerComposeLagoonLabel(composeServiceValues.Labels, fmt.Sprintf("lagoon.%s-dbaas.environment", lagoonType))
@@ -311,16 +307,12 @@ func composeToServiceValues(
 				// handle those here
 				exists, err := getDBaasEnvironment(buildValues, &dbaasEnvironment, lagoonOverrideName, lagoonType)
 				if err != nil {
-					// @TODO eventually this error should be handled and fail a build, with a flag to override https://github.com/uselagoon/build-deploy-tool/issues/56
-					// if !buildValues.DBaaSFallbackSingle {
-					// 	return nil, err
-					// }
-					if debug {
-						fmt.Printf(
-							"There was an error checking DBaaS endpoint %s, falling back to %s-single: %v\n",
-							buildValues.DBaaSOperatorEndpoint, lagoonType, err,
-						)
+					if !fallbackSingle {
+						return nil, fmt.Errorf("service %s: unable to find a %s provider in the %s DBaaS environment, and falling back to %s is disabled: %v",
+							composeService, lagoonType, dbaasEnvironment, dbaasType.SingleType, err)
 					}
+					buildValues.addWarning(WarningDBaaSEnvironment, composeService, buildValues.LagoonYAML.DockerComposeYAML,
+						"there was an error checking DBaaS endpoint %s, falling back to %s: %v", buildValues.DBaaSOperatorEndpoint, dbaasType.SingleType, err)
 				}
 
 				// if the requested dbaas environment exists, then set the type to be the requested type with `-dbaas`
@@ -328,8 +320,12 @@ func composeToServiceValues(
 					lagoonType = fmt.Sprintf("%s-dbaas", lagoonType)
 					svcIsDBaaS = true
 				} else {
-					// otherwise fallback to -single (if DBaaSFallbackSingle is enabled, otherwise it will error out prior)
-					lagoonType = fmt.Sprintf("%s-single", lagoonType)
+					if err == nil && !fallbackSingle {
+						return nil, fmt.Errorf("service %s: the DBaaS endpoint %s has no %s provider in the %s DBaaS environment, and falling back to %s is disabled",
+							composeService, buildValues.DBaaSOperatorEndpoint, lagoonType, dbaasEnvironment, dbaasType.SingleType)
+					}
+					// otherwise fallback to the single type
+					lagoonType = dbaasType.SingleType
 					svcIsSingle = true
 				}
 			}
@@ -383,7 +379,7 @@ func composeToServiceValues(
 					if idx+1 == len(errs) {
 						return nil, err
 					} else {
-						fmt.Println(err)
+						buildValues.addWarning(WarningBaseImage, composeService, buildValues.LagoonYAML.DockerComposeYAML, "%v", err)
 					}
 				}
 			}
@@ -507,64 +503,63 @@ func composeToServiceValues(
 			}
 		}
 
-		// check if this service is one that supports autogenerated routes
-		if !helpers.Contains(supportedAutogeneratedTypes, lagoonType) {
+		// check if this service is one that supports autogenerated routes and backups
+		// service types loaded from a file define these themselves
+		supportsAutogeneratedRoutes := helpers.Contains(supportedAutogeneratedTypes, lagoonType)
+		backupsEnabled := helpers.Contains(typesWithBackups, lagoonType)
+		if serviceType, ok := servicetypes.ServiceTypes[lagoonType]; ok && serviceType.Custom {
+			supportsAutogeneratedRoutes = serviceType.AutogeneratedRoutes
+			backupsEnabled = serviceType.Volumes.Backup
+		}
+		if !supportsAutogeneratedRoutes {
 			autogenEnabled = false
 			autogenTLSAcmeEnabled = false
 		}
 
-		// check if this service is one that supports backups
-		backupsEnabled := false
-		if helpers.Contains(typesWithBackups, lagoonType) {
-			backupsEnabled = true
-
+		// Build container resource requirements from service labels, either base label or an override
+		resources, err := generateResources(buildValues, composeService, composeServiceValues.Labels, "")
+		if err != nil {
+			return nil, err
 		}
-
-		// Helper lambda to look for resource requirement label, validate it, and update value
-		updateResourceRequirement := func(dest *string, resource_suffix string) (err error) {
-			// First check for branch override labels (example: lagoon.resources.override-branch.dev.requests.cpu)
-			{
-				branchOverrideLabel := fmt.Sprintf("lagoon.resources.override-branch.%s.%s", buildValues.Branch, resource_suffix)
-
-				overrideResourceValue := lagoon.CheckDockerComposeLagoonLabel(composeServiceValues.Labels, branchOverrideLabel)
-				if overrideResourceValue != "" {
-					err := ValidateResourceQuantity(overrideResourceValue)
-					if err != nil {
-						return fmt.Errorf("Value of resource requirement label %s for %s is not valid resource quantity: %v", branchOverrideLabel, servicePersistentName, err)
-					}
-					*dest = overrideResourceValue
-					return nil
-				}
+		if err := applyResourcePolicy(buildValues, composeService, "", &resources); err != nil {
+			return nil, err
+		}
+		// and for the secondary container of the service type if it has one (example: lagoon.resources.php.limits.memory)
+		var secondaryResources *Resources
+		if serviceType, ok := servicetypes.ServiceTypes[lagoonType]; ok && serviceType.SecondaryContainer.Name != "" {
+			r, err := generateResources(buildValues, composeService, composeServiceValues.Labels, serviceType.SecondaryContainer.Name)
+			if err != nil {
+				return nil, err
 			}
-
-			// If no branch override, check for base service label (example: lagoon.resources.requests.cpu)
-			{
-				baseLabel := fmt.Sprintf("lagoon.resources.%s", resource_suffix)
-				resourceValue := lagoon.CheckDockerComposeLagoonLabel(composeServiceValues.Labels, baseLabel)
-				if resourceValue != "" {
-					err := ValidateResourceQuantity(resourceValue)
-					if err != nil {
-						return fmt.Errorf("Value of resource requirement label %s for %s is not valid resource quantity: %v", baseLabel, servicePersistentName, err)
-					}
-					*dest = resourceValue
-				}
+			if err := applyResourcePolicy(buildValues, composeService, serviceType.SecondaryContainer.Name, &r); err != nil {
+				return nil, err
+			}
+			if r != (Resources{}) {
+				secondaryResources = &r
 			}
-			return nil
 		}
 
-		// Build container resource requirements from service labels, either base label or branch specific override.
-		resources := Resources{}
-		if err := updateResourceRequirement(&resources.Requests.Cpu, "requests.cpu"); err != nil {
+		autoscaling, err := generateAutoscaling(buildValues, composeService, lagoonType, composeServiceValues.Labels, debug)
+		if err != nil {
 			return nil, err
 		}
-		if err := updateResourceRequirement(&resources.Requests.Memory, "requests.memory"); err != nil {
+		podDisruptionBudget, err := generatePodDisruptionBudget(composeService, composeServiceValues.Labels)
+		if err != nil {
 			return nil, err
 		}
-		if err := updateResourceRequirement(&resources.Limits.Cpu, "limits.cpu"); err != nil {
+		probes, err := generateProbes(composeService, composeServiceValues.Labels)
+		if err != nil {
 			return nil, err
 		}
-		if err := updateResourceRequirement(&resources.Limits.Memory, "limits.memory"); err != nil {
-			return nil, err
+		// jobs run the command of the compose service once, before the deployments are rolled out
+		var job *Job
+		var command []string
+		if lagoonType == JobServiceType {
+			job, err = generateJob(composeService, composeServiceValues.Labels)
+			if err != nil {
+				return nil, err
+			}
+			command = composeServiceValues.Command
 		}
 
 		// create the service values
@@ -592,11 +587,17 @@ func composeToServiceValues(
 			BackupsEnabled:                         backupsEnabled,
 			AdditionalVolumes:                      serviceVolumes,
 			Resources:                              resources,
+			SecondaryResources:                     secondaryResources,
+			Autoscaling:                            autoscaling,
+			PodDisruptionBudget:                    podDisruptionBudget,
+			Probes:                                 probes,
+			Job:                                    job,
+			Command:                                command,
 		}
 
 		// work out the images here and the associated dockerfile and contexts
-		// if the type is in the ignored image types, then there is no image to build or pull for this service (eg, its a dbaas service)
-		if !helpers.Contains(ignoredImageTypes, lagoonType) {
+		// dbaas services don't have an image to build or pull
+		if !svcIsDBaaS {
 			imageBuild, err := generateImageBuild(*buildValues, composeServiceValues, composeService)
 			if err != nil {
 				return nil, err
@@ -11,9 +11,14 @@ import (
 	"github.com/uselagoon/build-deploy-tool/internal/dbaasclient"
 	"github.com/uselagoon/build-deploy-tool/internal/helpers"
 	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
 )
 
 func Test_composeToServiceValues(t *testing.T) {
+	additionalDBaaSTypes, err := servicetypes.GetDBaaSTypes("../testdata/dbaas/dbaas-types.yml", false)
+	if err != nil {
+		t.Fatal(err)
+	}
 	type args struct {
 		buildValues          *BuildValues
 		composeService       string
@@ -553,7 +558,43 @@ func Test_composeToServiceValues(t *testing.T) {
 			},
 		},
 		{
-			//@TODO: this should FAIL in the future https://github.com/uselagoon/build-deploy-tool/issues/56
+			name: "test10a - valkey to valkey-dbaas from an additional dbaas type",
+			args: args{
+				buildValues: &BuildValues{
+					Namespace:            "example-project-main",
+					Project:              "example-project",
+					ImageRegistry:        "harbor.example",
+					Environment:          "main",
+					Branch:               "main",
+					BuildType:            "branch",
+					EnvironmentType:      "production",
+					ServiceTypeOverrides: &lagoon.EnvironmentVariable{},
+					DBaaSTypes:           additionalDBaaSTypes,
+					LagoonYAML: lagoon.YAML{
+						Environments: lagoon.Environments{
+							"main": lagoon.Environment{},
+						},
+					},
+				},
+				composeService: "valkey",
+				composeServiceValues: composetypes.ServiceConfig{
+					Labels: composetypes.Labels{
+						"lagoon.type": "valkey-dbaas",
+					},
+					Image: "uselagoon/valkey-8:latest",
+				},
+			},
+			want: &ServiceValues{
+				Name:             "valkey",
+				OverrideName:     "valkey",
+				Type:             "valkey-dbaas",
+				DBaaSEnvironment: "production",
+				InPodCronjobs:    []lagoon.Cronjob{},
+				NativeCronjobs:   []lagoon.Cronjob{},
+				IsDBaaS:          true,
+			},
+		},
+		{
 			name: "test11 - mariadb to mariadb-single via environment override with no patching db provider",
 			args: args{
 				buildValues: &BuildValues{
@@ -565,6 +606,7 @@ func Test_composeToServiceValues(t *testing.T) {
 					BuildType:            "branch",
 					EnvironmentType:      "development",
 					ServiceTypeOverrides: &lagoon.EnvironmentVariable{},
+					DBaaSFallbackSingle:  true,
 					DBaaSEnvironmentTypeOverrides: &lagoon.EnvironmentVariable{
 						Name:  "LAGOON_DBAAS_ENVIRONMENT_TYPES",
 						Value: "mariadb:development2,postgres:postgres-single",
@@ -611,6 +653,105 @@ func Test_composeToServiceValues(t *testing.T) {
 				IsSingle:       true,
 			},
 		},
+		{
+			name: "test11a - mariadb with no patching db provider and fallback to mariadb-single disabled",
+			args: args{
+				buildValues: &BuildValues{
+					Namespace:            "example-project-main",
+					Project:              "example-project",
+					ImageRegistry:        "harbor.example",
+					Environment:          "main",
+					Branch:               "main",
+					BuildType:            "branch",
+					EnvironmentType:      "development",
+					ServiceTypeOverrides: &lagoon.EnvironmentVariable{},
+					DBaaSEnvironmentTypeOverrides: &lagoon.EnvironmentVariable{
+						Name:  "LAGOON_DBAAS_ENVIRONMENT_TYPES",
+						Value: "mariadb:development2,postgres:postgres-single",
+					},
+					LagoonYAML: lagoon.YAML{
+						Routes: lagoon.Routes{
+							Autogenerate: lagoon.Autogenerate{
+								Enabled:           helpers.BoolPtr(true),
+								AllowPullRequests: helpers.BoolPtr(false),
+							},
+						},
+						Environments: lagoon.Environments{
+							"main": lagoon.Environment{
+								AutogenerateRoutes: helpers.BoolPtr(true),
+							},
+						},
+					},
+				},
+				composeService: "mariadb",
+				composeServiceValues: composetypes.ServiceConfig{
+					Labels: composetypes.Labels{
+						"lagoon.type": "mariadb",
+					},
+					Image: "uselagoon/fake-mariadb:latest",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test11b - mariadb to mariadb-single with fallback enabled by the service label",
+			args: args{
+				buildValues: &BuildValues{
+					Namespace:            "example-project-main",
+					Project:              "example-project",
+					ImageRegistry:        "harbor.example",
+					Environment:          "main",
+					Branch:               "main",
+					BuildType:            "branch",
+					EnvironmentType:      "development",
+					ServiceTypeOverrides: &lagoon.EnvironmentVariable{},
+					DBaaSEnvironmentTypeOverrides: &lagoon.EnvironmentVariable{
+						Name:  "LAGOON_DBAAS_ENVIRONMENT_TYPES",
+						Value: "mariadb:development2,postgres:postgres-single",
+					},
+					LagoonYAML: lagoon.YAML{
+						Routes: lagoon.Routes{
+							Autogenerate: lagoon.Autogenerate{
+								Enabled:           helpers.BoolPtr(true),
+								AllowPullRequests: helpers.BoolPtr(false),
+							},
+						},
+						Environments: lagoon.Environments{
+							"main": lagoon.Environment{
+								AutogenerateRoutes: helpers.BoolPtr(true),
+							},
+						},
+					},
+				},
+				composeService: "mariadb",
+				composeServiceValues: composetypes.ServiceConfig{
+					Labels: composetypes.Labels{
+						"lagoon.type":                  "mariadb",
+						"lagoon.dbaas.fallback-single": "true",
+					},
+					Image: "uselagoon/fake-mariadb:latest",
+				},
+			},
+			want: &ServiceValues{
+				Name:                       "mariadb",
+				OverrideName:               "mariadb",
+				Type:                       "mariadb-single",
+				AutogeneratedRoutesEnabled: false,
+				AutogeneratedRoutesTLSAcme: false,
+				PersistentVolumePath:       "/var/lib/mysql",
+				PersistentVolumeName:       "mariadb",
+				PersistentVolumeSize:       "5Gi",
+				DBaaSEnvironment:           "development2",
+				InPodCronjobs:              []lagoon.Cronjob{},
+				NativeCronjobs:             []lagoon.Cronjob{},
+				ImageBuild: &ImageBuild{
+					PullImage:  "uselagoon/fake-mariadb:latest",
+					BuildImage: "harbor.example/example-project/main/mariadb:latest",
+				},
+				BackupsEnabled: true,
+				IsSingle:       true,
+			},
+		},
 		{
 			name: "test12 - postgres to postgres-dbaas",
 			args: args{
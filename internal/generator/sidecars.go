@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"fmt"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+)
+
+// SidecarServiceType is the type given to compose services with a `lagoon.sidecar.of` label
+const SidecarServiceType = "sidecar"
+
+// InitContainerServiceType is the type given to compose services with a `lagoon.init.of` label
+const InitContainerServiceType = "init"
+
+// generateSidecarValues creates the service values for a compose service with a `lagoon.sidecar.of` or `lagoon.init.of` label.
+// a sidecar or init container is added as an extra container to the deployment of the service it belongs to, and isn't deployed
+// on its own so only the image, command, resources and the service it belongs to are needed
+func generateSidecarValues(
+	buildValues *BuildValues,
+	composeService, sidecarOf, sidecarType string,
+	composeServiceValues composetypes.ServiceConfig,
+) (*ServiceValues, error) {
+	if sidecarOf == composeService {
+		return nil, fmt.Errorf("service %s can't be a %s of itself", composeService, sidecarType)
+	}
+	// a sidecar can still be disabled for an environment with the type none
+	lagoonType := lagoon.CheckDockerComposeLagoonLabel(composeServiceValues.Labels, "lagoon.type")
+	if value, ok := buildValues.LagoonYAML.Environments[buildValues.Environment].Types[composeService]; ok {
+		lagoonType = value
+	}
+	switch lagoonType {
+	case "none":
+		return nil, nil
+	case "", sidecarType:
+	default:
+		return nil, fmt.Errorf("service %s is a %s of %s, its lagoon.type must be %s or none, not %s",
+			composeService, sidecarType, sidecarOf, sidecarType, lagoonType)
+	}
+	resources, err := generateResources(buildValues, composeService, composeServiceValues.Labels, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := applyResourcePolicy(buildValues, composeService, "", &resources); err != nil {
+		return nil, err
+	}
+	imageBuild, err := generateImageBuild(*buildValues, composeServiceValues, composeService)
+	if err != nil {
+		return nil, err
+	}
+	return &ServiceValues{
+		Name:         composeService,
+		OverrideName: composeService,
+		Type:         sidecarType,
+		SidecarOf:    sidecarOf,
+		Command:      composeServiceValues.Command,
+		Resources:    resources,
+		ImageBuild:   &imageBuild,
+	}, nil
+}
+
+// checkSidecars checks that every sidecar and init container belongs to a service that has a deployment
+func checkSidecars(buildValues *BuildValues) error {
+	for _, sidecar := range buildValues.Services {
+		if sidecar.SidecarOf == "" {
+			continue
+		}
+		found := false
+		for _, service := range buildValues.Services {
+			if service.Name != sidecar.SidecarOf {
+				continue
+			}
+			if service.SidecarOf != "" {
+				return fmt.Errorf("service %s is a %s of %s, which is a %s itself", sidecar.Name, sidecar.Type, service.Name, service.Type)
+			}
+			if service.IsDBaaS {
+				return fmt.Errorf("service %s is a %s of %s, which is a DBaaS service without a deployment", sidecar.Name, sidecar.Type, service.Name)
+			}
+			if service.Type == JobServiceType {
+				return fmt.Errorf("service %s is a %s of %s, which is a job without a deployment", sidecar.Name, sidecar.Type, service.Name)
+			}
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("service %s is a %s of %s, which is not a service that is deployed", sidecar.Name, sidecar.Type, sidecar.SidecarOf)
+		}
+	}
+	return nil
+}
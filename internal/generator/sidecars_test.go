@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"testing"
+)
+
+func Test_checkSidecars(t *testing.T) {
+	tests := []struct {
+		name     string
+		services []ServiceValues
+		wantErr  bool
+	}{
+		{
+			name: "test1 sidecar of a service",
+			services: []ServiceValues{
+				{Name: "log-shipper", Type: SidecarServiceType, SidecarOf: "nginx"},
+				{Name: "nginx", Type: "nginx-php"},
+			},
+		},
+		{
+			name: "test2 sidecar of a service that doesn't exist",
+			services: []ServiceValues{
+				{Name: "nginx", Type: "nginx-php"},
+				{Name: "log-shipper", Type: SidecarServiceType, SidecarOf: "node"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test3 sidecar of a sidecar",
+			services: []ServiceValues{
+				{Name: "nginx", Type: "nginx-php"},
+				{Name: "log-shipper", Type: SidecarServiceType, SidecarOf: "nginx"},
+				{Name: "log-exporter", Type: SidecarServiceType, SidecarOf: "log-shipper"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test4 init container of a job",
+			services: []ServiceValues{
+				{Name: "migrate", Type: JobServiceType},
+				{Name: "wait-for-db", Type: InitContainerServiceType, SidecarOf: "migrate"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test5 sidecar of a dbaas service",
+			services: []ServiceValues{
+				{Name: "mariadb", Type: "mariadb-dbaas", IsDBaaS: true},
+				{Name: "log-shipper", Type: SidecarServiceType, SidecarOf: "mariadb"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSidecars(&BuildValues{Services: tt.services}); (err != nil) != tt.wantErr {
+				t.Errorf("checkSidecars() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+)
+
+// the codes of the warnings the generator can raise, these can be used in the warnings policy to promote a warning to an error
+const (
+	WarningDBaaSUnavailable        = "DBaaSUnavailable"
+	WarningDBaaSEnvironment        = "DBaaSEnvironmentCheckFailed"
+	WarningBaseImage               = "BaseImageRefresh"
+	WarningInvalidVariables        = "InvalidVariables"
+	WarningLagoonYAMLUnknownKey    = "LagoonYAMLUnknownKey"
+	WarningLagoonYAMLStringBoolean = "LagoonYAMLStringBoolean"
+	WarningAutoscalingDisabled     = "AutoscalingDisabled"
+	WarningAutoscalingCapped       = "AutoscalingCapped"
+	WarningResourcePolicyAdjusted  = "ResourcePolicyAdjusted"
+)
+
+// Warning is a problem found while generating the build that doesn't stop the build, but the user should know about
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Service is the docker-compose service the warning is for, if any
+	Service string `json:"service,omitempty"`
+	// Source is where in the users files the warning comes from, eg `docker-compose.yml` or `.lagoon.yml:12`
+	Source string `json:"source,omitempty"`
+}
+
+func (w Warning) String() string {
+	location := []string{}
+	if w.Source != "" {
+		location = append(location, w.Source)
+	}
+	if w.Service != "" {
+		location = append(location, fmt.Sprintf("service %s", w.Service))
+	}
+	if len(location) > 0 {
+		return fmt.Sprintf("[%s] %s (%s)", w.Code, w.Message, strings.Join(location, ", "))
+	}
+	return fmt.Sprintf("[%s] %s", w.Code, w.Message)
+}
+
+// WarningsError is returned by the generator when the warnings policy promotes any of the warnings to errors
+type WarningsError struct {
+	// Warnings are all the warnings raised by the generator, including the promoted ones
+	Warnings []Warning
+	Promoted []Warning
+}
+
+func (e *WarningsError) Error() string {
+	promoted := []string{}
+	for _, w := range e.Promoted {
+		promoted = append(promoted, w.String())
+	}
+	return fmt.Sprintf("warnings promoted to errors: %s", strings.Join(promoted, "; "))
+}
+
+// addWarning records a warning in the build values
+func (b *BuildValues) addWarning(code, service, source, format string, a ...interface{}) {
+	b.Warnings = append(b.Warnings, Warning{
+		Code:    code,
+		Message: fmt.Sprintf(format, a...),
+		Service: service,
+		Source:  source,
+	})
+}
+
+// promotedWarnings returns the warnings whose code is in the policy, `all` promotes every warning
+func promotedWarnings(warnings []Warning, policy []string) []Warning {
+	promoted := []Warning{}
+	for _, w := range warnings {
+		if helpers.Contains(policy, "all") || helpers.Contains(policy, w.Code) {
+			promoted = append(promoted, w)
+		}
+	}
+	return promoted
+}
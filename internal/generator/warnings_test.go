@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	composetypes "github.com/compose-spec/compose-go/types"
+	"github.com/uselagoon/build-deploy-tool/internal/dbaasclient"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+)
+
+func Test_promotedWarnings(t *testing.T) {
+	warnings := []Warning{
+		{Code: WarningDBaaSUnavailable, Message: "unable to check the DBaaS endpoint", Service: "mariadb"},
+		{Code: WarningLagoonYAMLUnknownKey, Message: "enviroments: unknown key", Source: ".lagoon.yml:3"},
+	}
+	tests := []struct {
+		name   string
+		policy []string
+		want   []Warning
+	}{
+		{
+			name:   "test1 no policy",
+			policy: nil,
+			want:   []Warning{},
+		},
+		{
+			name:   "test2 single code",
+			policy: []string{WarningDBaaSUnavailable},
+			want:   []Warning{warnings[0]},
+		},
+		{
+			name:   "test3 all",
+			policy: []string{"all"},
+			want:   warnings,
+		},
+		{
+			name:   "test4 code not raised",
+			policy: []string{WarningBaseImage},
+			want:   []Warning{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := promotedWarnings(warnings, tt.policy); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("promotedWarnings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWarning_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		warning Warning
+		want    string
+	}{
+		{
+			name:    "test1 no location",
+			warning: Warning{Code: WarningInvalidVariables, Message: "unable to read the project variables"},
+			want:    "[InvalidVariables] unable to read the project variables",
+		},
+		{
+			name:    "test2 source and service",
+			warning: Warning{Code: WarningBaseImage, Message: "no image", Service: "nginx", Source: "docker-compose.yml"},
+			want:    "[BaseImageRefresh] no image (docker-compose.yml, service nginx)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.warning.String(); got != tt.want {
+				t.Errorf("Warning.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_composeToServiceValues_dbaasWarning(t *testing.T) {
+	buildValues := &BuildValues{
+		Namespace:             "example-project-main",
+		Project:               "example-project",
+		ImageRegistry:         "harbor.example",
+		Environment:           "main",
+		Branch:                "main",
+		BuildType:             "branch",
+		EnvironmentType:       "development",
+		ServiceTypeOverrides:  &lagoon.EnvironmentVariable{},
+		DBaaSOperatorEndpoint: "http://127.0.0.1:1",
+		DBaaSFallbackSingle:   true,
+		DBaaSClient: dbaasclient.NewClient(dbaasclient.Client{
+			RetryMax:     1,
+			RetryWaitMin: time.Duration(10) * time.Millisecond,
+			RetryWaitMax: time.Duration(50) * time.Millisecond,
+		}),
+		LagoonYAML: lagoon.YAML{DockerComposeYAML: "docker-compose.yml"},
+	}
+	got, err := composeToServiceValues(buildValues, "mariadb", composetypes.ServiceConfig{
+		Labels: composetypes.Labels{"lagoon.type": "mariadb"},
+		Image:  "uselagoon/fake-mariadb:latest",
+	}, false)
+	if err != nil {
+		t.Fatalf("composeToServiceValues() error = %v", err)
+	}
+	if got.Type != "mariadb-single" {
+		t.Errorf("composeToServiceValues() type = %v, want mariadb-single", got.Type)
+	}
+	if len(buildValues.Warnings) != 1 {
+		t.Fatalf("composeToServiceValues() warnings = %v, want 1 warning", buildValues.Warnings)
+	}
+	w := buildValues.Warnings[0]
+	if w.Code != WarningDBaaSUnavailable || w.Service != "mariadb" || w.Source != "docker-compose.yml" {
+		t.Errorf("composeToServiceValues() warning = %v", w)
+	}
+}
@@ -0,0 +1,184 @@
+package lagoon
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// ignoredTopLevelKeys are keys in the .lagoon.yml that are used by lagoon or other tools outside of builds
+var ignoredTopLevelKeys = []string{"project", "ssh", "api", "lagoon-sync"}
+
+// the codes of the problems CheckLagoonYAML can find
+const (
+	ProblemUnknownKey    = "UnknownKey"
+	ProblemStringBoolean = "StringBoolean"
+)
+
+// YAMLProblem is something in a .lagoon.yml file that the build ignores or quietly corrects, like an unknown key
+// or a boolean defined as a string.
+type YAMLProblem struct {
+	Code    string `json:"code"`
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (p YAMLProblem) String() string {
+	return fmt.Sprintf("line %d: %s: %s", p.Line, p.Path, p.Message)
+}
+
+// CheckLagoonYAML checks the raw .lagoon.yml for keys that aren't known to the build, and for booleans that are defined as strings.
+// Top level keys that aren't known and contain a map with any .lagoon.yml keys are checked as polysite projects, any other map
+// is reported as an unknown key, as it is more likely to be a typo like `enviroments`.
+func CheckLagoonYAML(rawYAML []byte) ([]YAMLProblem, error) {
+	doc := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(rawYAML, doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yamlv3.MappingNode {
+		return nil, nil
+	}
+	problems := []YAMLProblem{}
+	fields := jsonFields(reflect.TypeOf(YAML{}))
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if field, ok := fields[key.Value]; ok {
+			problems = checkNode(problems, value, field, joinYAMLPath("", key.Value))
+			continue
+		}
+		if helpers.Contains(ignoredTopLevelKeys, key.Value) {
+			continue
+		}
+		if value.Kind == yamlv3.MappingNode && hasAnyKey(value, fields) {
+			// a polysite project
+			problems = checkNode(problems, value, reflect.StructField{Type: reflect.TypeOf(YAML{})}, joinYAMLPath("", key.Value))
+			continue
+		}
+		problems = append(problems, YAMLProblem{
+			Code:    ProblemUnknownKey,
+			Path:    joinYAMLPath("", key.Value),
+			Line:    key.Line,
+			Message: "unknown key",
+		})
+	}
+	return problems, nil
+}
+
+// hasAnyKey returns true if any of the keys of a mapping node are in the fields
+func hasAnyKey(node *yamlv3.Node, fields map[string]reflect.StructField) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if _, ok := fields[node.Content[i].Value]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func checkNode(problems []YAMLProblem, node *yamlv3.Node, field reflect.StructField, path string) []YAMLProblem {
+	if node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(Route{}) {
+		// a route is either a domain name, or a map of the domain name to the ingress configuration
+		if node.Kind == yamlv3.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				problems = checkNode(problems, node.Content[i+1], reflect.StructField{Type: reflect.TypeOf(Ingress{})}, joinYAMLPath(path, node.Content[i].Value))
+			}
+		}
+		return problems
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yamlv3.MappingNode {
+			return problems
+		}
+		fields := jsonFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				// yaml merge keys are fine, the values they merge in are checked where they are anchored
+				continue
+			}
+			f, ok := fields[key.Value]
+			if !ok {
+				problems = append(problems, YAMLProblem{
+					Code:    ProblemUnknownKey,
+					Path:    joinYAMLPath(path, key.Value),
+					Line:    key.Line,
+					Message: "unknown key",
+				})
+				continue
+			}
+			problems = checkNode(problems, value, f, joinYAMLPath(path, key.Value))
+		}
+	case reflect.Map:
+		if node.Kind != yamlv3.MappingNode {
+			return problems
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			problems = checkNode(problems, node.Content[i+1], reflect.StructField{Type: t.Elem()}, joinYAMLPath(path, node.Content[i].Value))
+		}
+	case reflect.Slice:
+		if node.Kind != yamlv3.SequenceNode {
+			return problems
+		}
+		for i, item := range node.Content {
+			problems = checkNode(problems, item, reflect.StructField{Type: t.Elem()}, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Bool:
+		if field.Tag.Get("schema") == "boolOrString" && node.Kind == yamlv3.ScalarNode && node.Tag == "!!str" {
+			problems = append(problems, YAMLProblem{
+				Code:    ProblemStringBoolean,
+				Path:    path,
+				Line:    node.Line,
+				Message: fmt.Sprintf("boolean defined as the string %q, use true or false without quotes", node.Value),
+			})
+		}
+	}
+	return problems
+}
+
+// jsonFields returns the fields of a struct keyed by their json name, including the fields of embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous && name == "" {
+			for n, f := range jsonFields(field.Type) {
+				fields[n] = f
+			}
+			continue
+		}
+		if name == "-" || name == "" {
+			continue
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func joinYAMLPath(path, key string) string {
+	if strings.ContainsAny(key, "./") {
+		return fmt.Sprintf("%s[%s]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
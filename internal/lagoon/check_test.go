@@ -0,0 +1,121 @@
+package lagoon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckLagoonYAML(t *testing.T) {
+	tests := []struct {
+		name      string
+		lagoonYml string
+		want      []YAMLProblem
+		wantErr   bool
+	}{
+		{
+			name: "test1 no problems",
+			lagoonYml: `docker-compose-yaml: docker-compose.yml
+project: example-project
+routes:
+  autogenerate:
+    enabled: false
+environments:
+  main:
+    routes:
+      - nginx:
+        - a.example.com
+        - b.example.com:
+            tls-acme: true
+`,
+			want: []YAMLProblem{},
+		},
+		{
+			name: "test2 unknown keys",
+			lagoonYml: `docker-compose-yaml: docker-compose.yml
+environments:
+  main:
+    autogenerateRoute: false
+    routes:
+      - nginx:
+        - a.example.com:
+            tls_acme: true
+typo: true
+`,
+			want: []YAMLProblem{
+				{Code: ProblemUnknownKey, Path: "environments.main.autogenerateRoute", Line: 4, Message: "unknown key"},
+				{Code: ProblemUnknownKey, Path: "environments.main.routes[0].nginx[0][a.example.com].tls_acme", Line: 8, Message: "unknown key"},
+				{Code: ProblemUnknownKey, Path: "typo", Line: 9, Message: "unknown key"},
+			},
+		},
+		{
+			name: "test3 booleans as strings",
+			lagoonYml: `routes:
+  autogenerate:
+    enabled: "false"
+    tls-acme: true
+environment_variables:
+  git_sha: 'true'
+environments:
+  main:
+    routes:
+      - nginx:
+        - a.example.com:
+            tls-acme: "true"
+            fastly:
+              watch: "false"
+`,
+			want: []YAMLProblem{
+				{Code: ProblemStringBoolean, Path: "routes.autogenerate.enabled", Line: 3, Message: `boolean defined as the string "false", use true or false without quotes`},
+				{Code: ProblemStringBoolean, Path: "environment_variables.git_sha", Line: 6, Message: `boolean defined as the string "true", use true or false without quotes`},
+				{Code: ProblemStringBoolean, Path: "environments.main.routes[0].nginx[0][a.example.com].tls-acme", Line: 12, Message: `boolean defined as the string "true", use true or false without quotes`},
+				{Code: ProblemStringBoolean, Path: "environments.main.routes[0].nginx[0][a.example.com].fastly.watch", Line: 14, Message: `boolean defined as the string "false", use true or false without quotes`},
+			},
+		},
+		{
+			name: "test4 polysite",
+			lagoonYml: `example-project:
+  environments:
+    main:
+      cronjobs:
+        - name: drush cron
+          schedule: "M * * * *"
+          command: drush cron
+          service: cli
+          shell: bash
+`,
+			want: []YAMLProblem{
+				{Code: ProblemUnknownKey, Path: "example-project.environments.main.cronjobs[0].shell", Line: 9, Message: "unknown key"},
+			},
+		},
+		{
+			name:      "test5 invalid yaml",
+			lagoonYml: "environments: [",
+			wantErr:   true,
+		},
+		{
+			name: "test6 misspelled top level key",
+			lagoonYml: `docker-compose-yaml: docker-compose.yml
+enviroments:
+  main:
+    routes:
+      - nginx:
+        - a.example.com
+`,
+			want: []YAMLProblem{
+				{Code: ProblemUnknownKey, Path: "enviroments", Line: 2, Message: "unknown key"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckLagoonYAML([]byte(tt.lagoonYml))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckLagoonYAML() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckLagoonYAML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
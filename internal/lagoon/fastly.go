@@ -8,8 +8,8 @@ import (
 
 // Fastly represents the fastly configuration for a Lagoon route
 type Fastly struct {
-	ServiceID string `json:"service-id,omitempty"`
-	Watch     bool   `json:"watch,omitempty"`
+	ServiceID string `json:"service-id,omitempty" description:"the fastly service id"`
+	Watch     bool   `json:"watch,omitempty" description:"watch the route with fastly" schema:"boolOrString"`
 }
 
 // GenerateFastlyConfiguration generates the fastly configuration for a specific route from Lagoon variables.
@@ -14,37 +14,37 @@ import (
 
 // ProductionRoutes represents an active/standby configuration.
 type ProductionRoutes struct {
-	Active  *Environment `json:"active"`
-	Standby *Environment `json:"standby"`
+	Active  *Environment `json:"active" description:"the routes for the active environment"`
+	Standby *Environment `json:"standby" description:"the routes for the standby environment"`
 }
 
 // Environment represents a Lagoon environment.
 type Environment struct {
-	AutogenerateRoutes     *bool                   `json:"autogenerateRoutes"`
-	Types                  map[string]string       `json:"types"`
-	Routes                 []map[string][]Route    `json:"routes"`
-	Cronjobs               []Cronjob               `json:"cronjobs"`
-	Overrides              map[string]Override     `json:"overrides,omitempty"`
-	AutogeneratePathRoutes []AutogeneratePathRoute `json:"autogeneratePathRoutes,omitempty"`
+	AutogenerateRoutes     *bool                   `json:"autogenerateRoutes" description:"override the project autogenerated routes setting for this environment"`
+	Types                  map[string]string       `json:"types" description:"override the lagoon.type of a docker-compose service in this environment"`
+	Routes                 []map[string][]Route    `json:"routes" description:"the custom routes for each service in this environment"`
+	Cronjobs               []Cronjob               `json:"cronjobs" description:"the cronjobs to run in this environment"`
+	Overrides              map[string]Override     `json:"overrides,omitempty" description:"override the build or image of a docker-compose service in this environment"`
+	AutogeneratePathRoutes []AutogeneratePathRoute `json:"autogeneratePathRoutes,omitempty" description:"path based routing for the autogenerated routes of this environment"`
 }
 
 // Cronjob represents a Lagoon cronjob.
 type Cronjob struct {
-	Name     string `json:"name"`
-	Service  string `json:"service"`
-	Schedule string `json:"schedule"`
-	Command  string `json:"command"`
-	InPod    *bool  `json:"inPod"`
+	Name     string `json:"name" description:"the name of the cronjob"`
+	Service  string `json:"service" description:"the docker-compose service to run the cronjob in"`
+	Schedule string `json:"schedule" description:"the cron schedule, M and H can be used to randomise the minute and hour"`
+	Command  string `json:"command" description:"the command to run"`
+	InPod    *bool  `json:"inPod" description:"run the cronjob inside the running pod rather than as a kubernetes cronjob"`
 }
 
 type Override struct {
-	Build Build  `json:"build,omitempty"`
-	Image string `json:"image,omitempty"`
+	Build Build  `json:"build,omitempty" description:"override the build of the service"`
+	Image string `json:"image,omitempty" description:"override the image of the service"`
 }
 
 type Build struct {
-	Dockerfile string `json:"dockerfile,omitempty"`
-	Context    string `json:"context,omitempty"`
+	Dockerfile string `json:"dockerfile,omitempty" description:"the dockerfile to build the service with"`
+	Context    string `json:"context,omitempty" description:"the context to build the service in"`
 }
 
 // Environments .
@@ -52,73 +52,76 @@ type Environments map[string]Environment
 
 // TaskRun .
 type TaskRun struct {
-	Run Task `json:"run"`
+	Run Task `json:"run" description:"the task to run"`
 }
 
 // Tasks .
 type Tasks struct {
-	Prerollout  []TaskRun `json:"pre-rollout"`
-	Postrollout []TaskRun `json:"post-rollout"`
+	Prerollout  []TaskRun `json:"pre-rollout" description:"tasks to run before the services are rolled out"`
+	Postrollout []TaskRun `json:"post-rollout" description:"tasks to run after the services are rolled out"`
 }
 
 // YAML represents the .lagoon.yml file.
 type YAML struct {
-	DockerComposeYAML    string                       `json:"docker-compose-yaml"`
-	Environments         Environments                 `json:"environments"`
-	ProductionRoutes     *ProductionRoutes            `json:"production_routes"`
-	Tasks                Tasks                        `json:"tasks"`
-	Routes               Routes                       `json:"routes"`
-	BackupRetention      BackupRetention              `json:"backup-retention"`
-	BackupSchedule       BackupSchedule               `json:"backup-schedule"`
-	EnvironmentVariables EnvironmentVariables         `json:"environment_variables,omitempty"`
-	ContainerRegistries  map[string]ContainerRegistry `json:"container-registries,omitempty"`
+	DockerComposeYAML    string                       `json:"docker-compose-yaml" description:"the docker-compose file to use for the build"`
+	Environments         Environments                 `json:"environments" description:"the configuration for each environment, keyed by the branch or pullrequest environment name"`
+	ProductionRoutes     *ProductionRoutes            `json:"production_routes" description:"the routes for active/standby environments"`
+	Tasks                Tasks                        `json:"tasks" description:"the tasks to run during the build"`
+	Routes               Routes                       `json:"routes" description:"the route configuration for the project"`
+	BackupRetention      BackupRetention              `json:"backup-retention" description:"the backup retention for production environments"`
+	BackupSchedule       BackupSchedule               `json:"backup-schedule" description:"the backup schedule for production environments"`
+	EnvironmentVariables EnvironmentVariables         `json:"environment_variables,omitempty" description:"variables that are injected into the build"`
+	ContainerRegistries  map[string]ContainerRegistry `json:"container-registries,omitempty" description:"private container registries used to pull images, keyed by the registry name"`
 }
 
 type ContainerRegistry struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-	URL      string `json:"url"`
+	Username string `json:"username" description:"the username for the registry"`
+	Password string `json:"password" description:"the password for the registry, or the name of a lagoon variable containing it"`
+	URL      string `json:"url" description:"the url of the registry, defaults to docker hub"`
 }
 
 type EnvironmentVariables struct {
-	GitSHA *bool `json:"git_sha"`
+	GitSHA *bool `json:"git_sha" description:"inject the git sha into the environment as LAGOON_GIT_SHA" schema:"boolOrString"`
 }
 
 type BackupRetention struct {
-	Production Retention `json:"production"`
+	Production Retention `json:"production" description:"the retention for production environments"`
 }
 
 type BackupSchedule struct {
-	Production string `json:"production"`
+	Production string `json:"production" description:"the schedule for production environments"`
 }
 
 type Retention struct {
-	Hourly  *int `json:"hourly"`
-	Daily   *int `json:"daily"`
-	Weekly  *int `json:"weekly"`
-	Monthly *int `json:"monthly"`
+	Hourly  *int `json:"hourly" description:"the number of hourly backups to keep"`
+	Daily   *int `json:"daily" description:"the number of daily backups to keep"`
+	Weekly  *int `json:"weekly" description:"the number of weekly backups to keep"`
+	Monthly *int `json:"monthly" description:"the number of monthly backups to keep"`
 }
 
 // Routes .
 type Routes struct {
-	Autogenerate Autogenerate `json:"autogenerate"`
+	Autogenerate Autogenerate `json:"autogenerate" description:"the autogenerated route configuration"`
 }
 
 // Autogenerate .
 type Autogenerate struct {
-	Enabled             *bool                   `json:"enabled"`
-	AllowPullRequests   *bool                   `json:"allowPullRequests"`
-	Insecure            string                  `json:"insecure"`
-	Prefixes            []string                `json:"prefixes"`
-	TLSAcme             *bool                   `json:"tls-acme,omitempty"`
-	IngressClass        string                  `json:"ingressClass"`
-	RequestVerification *bool                   `json:"disableRequestVerification,omitempty"`
-	PathRoutes          []AutogeneratePathRoute `json:"pathRoutes,omitempty"`
+	Enabled             *bool                   `json:"enabled" description:"generate routes for the environment" schema:"boolOrString"`
+	AllowPullRequests   *bool                   `json:"allowPullRequests" description:"generate routes for pullrequest environments when enabled is false" schema:"boolOrString"`
+	Insecure            string                  `json:"insecure" description:"how insecure traffic is handled, Allow, Redirect or None"`
+	Prefixes            []string                `json:"prefixes" description:"additional prefixes to add to the autogenerated routes"`
+	TLSAcme             *bool                   `json:"tls-acme,omitempty" description:"request certificates for the autogenerated routes" schema:"boolOrString"`
+	IngressClass        string                  `json:"ingressClass" description:"the ingress class to use for the autogenerated routes"`
+	RequestVerification *bool                   `json:"disableRequestVerification,omitempty" description:"disable the request verification on the autogenerated routes"`
+	PathRoutes          []AutogeneratePathRoute `json:"pathRoutes,omitempty" description:"path based routing for the autogenerated routes"`
+	AllowCIDRs          []string                `json:"allowCIDRs,omitempty" description:"only allow requests to the autogenerated routes from these ip ranges"`
+	DenyCIDRs           []string                `json:"denyCIDRs,omitempty" description:"deny requests to the autogenerated routes from these ip ranges"`
+	BasicAuth           *BasicAuth              `json:"basicAuth,omitempty" description:"require basic authentication on the autogenerated routes"`
 }
 
 type AutogeneratePathRoute struct {
 	PathRoute
-	FromService string `json:"fromService"`
+	FromService string `json:"fromService" description:"the service whose autogenerated route the path is added to"`
 }
 
 func (a *Routes) UnmarshalJSON(data []byte) error {
@@ -133,8 +136,7 @@ func (a *Routes) UnmarshalJSON(data []byte) error {
 			if reflect.TypeOf(value.(map[string]interface{})["tls-acme"]).Kind() == reflect.String {
 				vBool, err := strconv.ParseBool(value.(map[string]interface{})["tls-acme"].(string))
 				if err == nil {
-					// @TODO: add warning functionality here to inform that users should fix their yaml to be boolean not string
-					// this could warn in a yaml validation step at the start of builds
+					// CheckLagoonYAML warns users that they should fix their yaml to be boolean not string
 					value.(map[string]interface{})["tls-acme"] = vBool
 				}
 			}
@@ -143,8 +145,7 @@ func (a *Routes) UnmarshalJSON(data []byte) error {
 			if reflect.TypeOf(value.(map[string]interface{})["enabled"]).Kind() == reflect.String {
 				vBool, err := strconv.ParseBool(value.(map[string]interface{})["enabled"].(string))
 				if err == nil {
-					// @TODO: add warning functionality here to inform that users should fix their yaml to be boolean not string
-					// this could warn in a yaml validation step at the start of builds
+					// CheckLagoonYAML warns users that they should fix their yaml to be boolean not string
 					value.(map[string]interface{})["enabled"] = vBool
 				}
 			}
@@ -153,8 +154,7 @@ func (a *Routes) UnmarshalJSON(data []byte) error {
 			if reflect.TypeOf(value.(map[string]interface{})["allowPullRequests"]).Kind() == reflect.String {
 				vBool, err := strconv.ParseBool(value.(map[string]interface{})["allowPullRequests"].(string))
 				if err == nil {
-					// @TODO: add warning functionality here to inform that users should fix their yaml to be boolean not string
-					// this could warn in a yaml validation step at the start of builds
+					// CheckLagoonYAML warns users that they should fix their yaml to be boolean not string
 					value.(map[string]interface{})["allowPullRequests"] = vBool
 				}
 			}
@@ -176,8 +176,7 @@ func (a *EnvironmentVariables) UnmarshalJSON(data []byte) error {
 		if reflect.TypeOf(value).Kind() == reflect.String {
 			vBool, err := strconv.ParseBool(value.(string))
 			if err == nil {
-				// @TODO: add warning functionality here to inform that users should fix their yaml to be boolean not string
-				// this could warn in a yaml validation step at the start of builds
+				// CheckLagoonYAML warns users that they should fix their yaml to be boolean not string
 				value = vBool
 			}
 		}
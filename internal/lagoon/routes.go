@@ -3,6 +3,8 @@ package lagoon
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
@@ -39,25 +41,43 @@ type RouteV2 struct {
 	Wildcard              *bool             `json:"wildcard,omitempty"`
 	RequestVerification   *bool             `json:"disableRequestVerification,omitempty"`
 	PathRoutes            []PathRoute       `json:"pathRoutes,omitempty"`
+	ResponseHeaders       map[string]string `json:"responseHeaders,omitempty"`
+	Redirects             []Redirect        `json:"redirects,omitempty"`
+	AllowCIDRs            []string          `json:"allowCIDRs,omitempty"`
+	DenyCIDRs             []string          `json:"denyCIDRs,omitempty"`
+	BasicAuth             *BasicAuth        `json:"basicAuth,omitempty"`
+	Canary                *Canary           `json:"canary,omitempty"`
+	TLSSecret             string            `json:"tlsSecret,omitempty"`
+	Issuer                string            `json:"issuer,omitempty"`
+	ClusterIssuer         string            `json:"clusterIssuer,omitempty"`
 }
 
 // Ingress represents a Lagoon route.
 type Ingress struct {
-	TLSAcme               *bool             `json:"tls-acme,omitempty"`
-	Migrate               *bool             `json:"migrate,omitempty"`
-	Insecure              *string           `json:"insecure,omitempty"`
-	MonitoringPath        string            `json:"monitoring-path,omitempty"`
-	Fastly                Fastly            `json:"fastly,omitempty"`
-	Annotations           map[string]string `json:"annotations,omitempty"`
-	IngressClass          string            `json:"ingressClass"`
-	HSTSEnabled           *bool             `json:"hstsEnabled,omitempty"`
-	HSTSMaxAge            int               `json:"hstsMaxAge,omitempty"`
-	HSTSIncludeSubdomains *bool             `json:"hstsIncludeSubdomains,omitempty"`
-	HSTSPreload           *bool             `json:"hstsPreload,omitempty"`
-	AlternativeNames      []string          `json:"alternativenames,omitempty"`
-	Wildcard              *bool             `json:"wildcard,omitempty"`
-	RequestVerification   *bool             `json:"disableRequestVerification,omitempty"`
-	PathRoutes            []PathRoute       `json:"pathRoutes,omitempty"`
+	TLSAcme               *bool             `json:"tls-acme,omitempty" description:"request a certificate for the route" schema:"boolOrString"`
+	Migrate               *bool             `json:"migrate,omitempty" description:"migrate the route between active and standby environments"`
+	Insecure              *string           `json:"insecure,omitempty" description:"how insecure traffic is handled, Allow, Redirect or None"`
+	MonitoringPath        string            `json:"monitoring-path,omitempty" description:"the path used to monitor the route"`
+	Fastly                Fastly            `json:"fastly,omitempty" description:"the fastly configuration for the route"`
+	Annotations           map[string]string `json:"annotations,omitempty" description:"additional annotations to add to the ingress"`
+	IngressClass          string            `json:"ingressClass" description:"the ingress class to use for the route"`
+	HSTSEnabled           *bool             `json:"hstsEnabled,omitempty" description:"add the strict-transport-security header"`
+	HSTSMaxAge            int               `json:"hstsMaxAge,omitempty" description:"the max-age of the strict-transport-security header"`
+	HSTSIncludeSubdomains *bool             `json:"hstsIncludeSubdomains,omitempty" description:"add includeSubDomains to the strict-transport-security header"`
+	HSTSPreload           *bool             `json:"hstsPreload,omitempty" description:"add preload to the strict-transport-security header"`
+	AlternativeNames      []string          `json:"alternativenames,omitempty" description:"additional domains for the route"`
+	Wildcard              *bool             `json:"wildcard,omitempty" description:"make the route a wildcard route, tls-acme can only be used with an issuer or clusterIssuer"`
+	RequestVerification   *bool             `json:"disableRequestVerification,omitempty" description:"disable the request verification on the route"`
+	PathRoutes            []PathRoute       `json:"pathRoutes,omitempty" description:"send requests for a path on the route to another service"`
+	ResponseHeaders       map[string]string `json:"responseHeaders,omitempty" description:"headers to add to the responses of the route"`
+	Redirects             []Redirect        `json:"redirects,omitempty" description:"redirect the route, or paths of the route, to another location"`
+	AllowCIDRs            []string          `json:"allowCIDRs,omitempty" description:"only allow requests from these ip ranges"`
+	DenyCIDRs             []string          `json:"denyCIDRs,omitempty" description:"deny requests from these ip ranges"`
+	BasicAuth             *BasicAuth        `json:"basicAuth,omitempty" description:"require basic authentication with the users in a secret"`
+	Canary                *Canary           `json:"canary,omitempty" description:"send a share of the requests to another service or environment, only for production environments"`
+	TLSSecret             string            `json:"tlsSecret,omitempty" description:"use the certificate in this secret instead of requesting one, it can't be used with tls-acme"`
+	Issuer                string            `json:"issuer,omitempty" description:"request the certificate from this cert-manager issuer in the environment namespace"`
+	ClusterIssuer         string            `json:"clusterIssuer,omitempty" description:"request the certificate from this cert-manager cluster issuer, wildcard routes need one that solves dns01 challenges"`
 }
 
 // Route can be either a string or a map[string]Ingress, so we must
@@ -68,8 +88,31 @@ type Route struct {
 }
 
 type PathRoute struct {
-	ToService string `json:"toService"`
-	Path      string `json:"path"`
+	ToService string `json:"toService" description:"the service to send requests for the path to"`
+	Path      string `json:"path" description:"the path to send to the service"`
+	Rewrite   string `json:"rewrite,omitempty" description:"replace the path with this before the request is sent to the service"`
+}
+
+// Redirect redirects requests for a route, or for a path of a route, to another location
+type Redirect struct {
+	Path         string `json:"path,omitempty" description:"the path to redirect, the whole route is redirected if this isn't set"`
+	To           string `json:"to" description:"where to redirect to, a url or a path on the route"`
+	Permanent    bool   `json:"permanent,omitempty" description:"use a permanent (301) redirect instead of a temporary (302) redirect"`
+	PreservePath bool   `json:"preservePath,omitempty" description:"add the path of the request to the url when the whole route is redirected"`
+}
+
+// BasicAuth requires basic authentication on a route, with the users in the `auth` key of a secret in htpasswd format
+type BasicAuth struct {
+	Secret   string `json:"secret,omitempty" description:"the secret that has the users in htpasswd format in the auth key"`
+	Realm    string `json:"realm,omitempty" description:"the realm shown in the login prompt"`
+	Disabled bool   `json:"disabled,omitempty" description:"disable the default basic authentication of the environment on this route"`
+}
+
+// Canary sends a share of the requests for a route to another service, or to a service in another environment of the project
+type Canary struct {
+	Service     string `json:"service,omitempty" description:"the service to send the requests to, the service of the route if not set"`
+	Environment string `json:"environment,omitempty" description:"send the requests to the service in this environment of the project"`
+	Weight      int    `json:"weight" description:"the percentage of requests to send, from 0 to 100"`
 }
 
 // defaults
@@ -154,6 +197,14 @@ func GenerateRoutesV2(yamlRoutes *RoutesV2, routeMap map[string][]Route, variabl
 					if ingress.TLSAcme != nil {
 						newRoute.TLSAcme = ingress.TLSAcme
 					}
+					// certificates
+					newRoute.TLSSecret = ingress.TLSSecret
+					newRoute.Issuer = ingress.Issuer
+					newRoute.ClusterIssuer = ingress.ClusterIssuer
+					if ingress.TLSSecret != "" && ingress.TLSAcme == nil {
+						// the certificate is provided, so one isn't requested
+						newRoute.TLSAcme = helpers.BoolPtr(false)
+					}
 					if ingress.Insecure != nil {
 						newRoute.Insecure = ingress.Insecure
 					}
@@ -189,8 +240,8 @@ func GenerateRoutesV2(yamlRoutes *RoutesV2, routeMap map[string][]Route, variabl
 					// handle wildcards
 					if ingress.Wildcard != nil {
 						newRoute.Wildcard = ingress.Wildcard
-						if *newRoute.TLSAcme && *newRoute.Wildcard {
-							return fmt.Errorf("Route %s has wildcard: true and tls-acme: true, this is not supported", newRoute.Domain)
+						if *newRoute.TLSAcme && *newRoute.Wildcard && newRoute.Issuer == "" && newRoute.ClusterIssuer == "" {
+							return fmt.Errorf("Route %s has wildcard: true and tls-acme: true, this is only supported with an issuer or clusterIssuer that solves dns01 challenges", newRoute.Domain)
 						}
 						if ingress.AlternativeNames != nil && *newRoute.Wildcard {
 							return fmt.Errorf("Route %s has wildcard: true and alternativenames defined, this is not supported", newRoute.Domain)
@@ -210,6 +261,30 @@ func GenerateRoutesV2(yamlRoutes *RoutesV2, routeMap map[string][]Route, variabl
 					if ingress.PathRoutes != nil {
 						newRoute.PathRoutes = ingress.PathRoutes
 					}
+
+					// response headers and redirects
+					if ingress.ResponseHeaders != nil {
+						newRoute.ResponseHeaders = ingress.ResponseHeaders
+					}
+					if ingress.Redirects != nil {
+						newRoute.Redirects = ingress.Redirects
+					}
+
+					// access restrictions
+					if ingress.AllowCIDRs != nil {
+						newRoute.AllowCIDRs = ingress.AllowCIDRs
+					}
+					if ingress.DenyCIDRs != nil {
+						newRoute.DenyCIDRs = ingress.DenyCIDRs
+					}
+					if ingress.BasicAuth != nil {
+						newRoute.BasicAuth = ingress.BasicAuth
+					}
+
+					// traffic splitting
+					if ingress.Canary != nil {
+						newRoute.Canary = ingress.Canary
+					}
 				}
 			} else {
 				// this route is just a domain
@@ -228,6 +303,9 @@ func GenerateRoutesV2(yamlRoutes *RoutesV2, routeMap map[string][]Route, variabl
 			if err := validation.IsDNS1123Subdomain(strings.ToLower(newRoute.Domain)); err != nil {
 				return fmt.Errorf("Route %s in .lagoon.yml is not valid: %v", newRoute.Domain, err)
 			}
+			if err := ValidateRouteV2(newRoute); err != nil {
+				return fmt.Errorf("Route %s in .lagoon.yml is not valid: %v", newRoute.Domain, err)
+			}
 			yamlRoutes.Routes = append(yamlRoutes.Routes, newRoute)
 		}
 	}
@@ -321,6 +399,14 @@ func handleAPIRoute(defaultIngressClass string, apiRoute RouteV2) (RouteV2, erro
 	} else {
 		routeAdd.TLSAcme = defaultTLSAcme
 	}
+	// certificates
+	routeAdd.TLSSecret = apiRoute.TLSSecret
+	routeAdd.Issuer = apiRoute.Issuer
+	routeAdd.ClusterIssuer = apiRoute.ClusterIssuer
+	if apiRoute.TLSSecret != "" && apiRoute.TLSAcme == nil {
+		// the certificate is provided, so one isn't requested
+		routeAdd.TLSAcme = helpers.BoolPtr(false)
+	}
 	if apiRoute.Insecure != nil {
 		routeAdd.Insecure = apiRoute.Insecure
 	} else {
@@ -364,8 +450,8 @@ func handleAPIRoute(defaultIngressClass string, apiRoute RouteV2) (RouteV2, erro
 	// handle wildcards
 	if apiRoute.Wildcard != nil {
 		routeAdd.Wildcard = apiRoute.Wildcard
-		if *routeAdd.TLSAcme && *routeAdd.Wildcard {
-			return routeAdd, fmt.Errorf("Route %s has wildcard=true and tls-acme=true, this is not supported", routeAdd.Domain)
+		if *routeAdd.TLSAcme && *routeAdd.Wildcard && routeAdd.Issuer == "" && routeAdd.ClusterIssuer == "" {
+			return routeAdd, fmt.Errorf("Route %s has wildcard=true and tls-acme=true, this is only supported with an issuer or clusterIssuer that solves dns01 challenges", routeAdd.Domain)
 		}
 		if apiRoute.AlternativeNames != nil && *routeAdd.Wildcard {
 			return routeAdd, fmt.Errorf("Route %s has wildcard=true and alternativenames defined, this is not supported", routeAdd.Domain)
@@ -386,5 +472,198 @@ func handleAPIRoute(defaultIngressClass string, apiRoute RouteV2) (RouteV2, erro
 	if apiRoute.PathRoutes != nil {
 		routeAdd.PathRoutes = apiRoute.PathRoutes
 	}
+
+	// response headers and redirects
+	if apiRoute.ResponseHeaders != nil {
+		routeAdd.ResponseHeaders = apiRoute.ResponseHeaders
+	}
+	if apiRoute.Redirects != nil {
+		routeAdd.Redirects = apiRoute.Redirects
+	}
+
+	// access restrictions
+	if apiRoute.AllowCIDRs != nil {
+		routeAdd.AllowCIDRs = apiRoute.AllowCIDRs
+	}
+	if apiRoute.DenyCIDRs != nil {
+		routeAdd.DenyCIDRs = apiRoute.DenyCIDRs
+	}
+	if apiRoute.BasicAuth != nil {
+		routeAdd.BasicAuth = apiRoute.BasicAuth
+	}
+
+	// traffic splitting
+	if apiRoute.Canary != nil {
+		routeAdd.Canary = apiRoute.Canary
+	}
+	if err := ValidateRouteV2(routeAdd); err != nil {
+		return routeAdd, fmt.Errorf("Route %s in API defined routes is not valid: %v", routeAdd.Domain, err)
+	}
 	return routeAdd, nil
 }
+
+// unsafeRouteCharacters can't be used in redirects or rewrites, as they are added to the configuration of the ingress controller
+const unsafeRouteCharacters = " \t\r\n;{}'\"\\$`"
+
+// ValidateRouteV2 validates the response headers, redirects, path rewrites and access restrictions of a route
+func ValidateRouteV2(route RouteV2) error {
+	if err := ValidateCIDRs(route.AllowCIDRs); err != nil {
+		return fmt.Errorf("allowCIDRs is not valid: %v", err)
+	}
+	if err := ValidateCIDRs(route.DenyCIDRs); err != nil {
+		return fmt.Errorf("denyCIDRs is not valid: %v", err)
+	}
+	if err := ValidateBasicAuth(route.BasicAuth); err != nil {
+		return err
+	}
+	if err := validateRouteCertificate(route); err != nil {
+		return err
+	}
+	if route.Canary != nil {
+		if route.Canary.Weight < 0 || route.Canary.Weight > 100 {
+			return fmt.Errorf("canary weight %d is not valid, it must be from 0 to 100", route.Canary.Weight)
+		}
+		if route.Canary.Service == "" && route.Canary.Environment == "" {
+			return fmt.Errorf("canary needs a service, an environment, or both")
+		}
+		if route.Canary.Environment != "" {
+			if errs := validation.IsDNS1123Label(route.Canary.Environment); errs != nil {
+				return fmt.Errorf("canary environment %s is not valid: %v", route.Canary.Environment, strings.Join(errs, ", "))
+			}
+		}
+		for _, redirect := range route.Redirects {
+			if redirect.Path == "" {
+				return fmt.Errorf("the whole route is redirected to %s, so it can't have a canary", redirect.To)
+			}
+		}
+	}
+	for name, value := range route.ResponseHeaders {
+		if errs := validation.IsHTTPHeaderName(name); errs != nil {
+			return fmt.Errorf("response header %s is not valid: %v", name, strings.Join(errs, ", "))
+		}
+		if strings.EqualFold(name, "Strict-Transport-Security") {
+			return fmt.Errorf("response header %s can't be set, use the hsts fields instead", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("the value of response header %s can't contain new lines", name)
+		}
+	}
+	paths := map[string]bool{}
+	for _, pr := range route.PathRoutes {
+		paths[pr.Path] = true
+		if pr.Rewrite == "" {
+			continue
+		}
+		if err := validateRoutePath(pr.Path); err != nil {
+			return fmt.Errorf("path route %s is not valid: %v", pr.Path, err)
+		}
+		if err := validateRoutePath(pr.Rewrite); err != nil {
+			return fmt.Errorf("the rewrite of path route %s is not valid: %v", pr.Path, err)
+		}
+	}
+	wholeRoute := false
+	for _, redirect := range route.Redirects {
+		if strings.ContainsAny(redirect.To, unsafeRouteCharacters) || redirect.To == "" {
+			return fmt.Errorf("redirect to %s is not valid, it must be a url or a path without spaces or any of ;{}'\"\\$`", redirect.To)
+		}
+		target, err := url.Parse(redirect.To)
+		if err != nil {
+			return fmt.Errorf("redirect to %s is not valid: %v", redirect.To, err)
+		}
+		absolute := (target.Scheme == "http" || target.Scheme == "https") && target.Host != ""
+		if !absolute && !strings.HasPrefix(redirect.To, "/") {
+			return fmt.Errorf("redirect to %s is not valid, it must be a http or https url or a path starting with /", redirect.To)
+		}
+		if redirect.Path == "" {
+			// the whole route is redirected
+			if wholeRoute {
+				return fmt.Errorf("only one redirect can redirect the whole route, the others need a path")
+			}
+			wholeRoute = true
+			if !absolute {
+				return fmt.Errorf("redirect of the whole route to %s is not valid, it must be a http or https url", redirect.To)
+			}
+			if len(route.PathRoutes) > 0 {
+				return fmt.Errorf("the whole route is redirected to %s, so it can't have path routes", redirect.To)
+			}
+			continue
+		}
+		if err := validateRoutePath(redirect.Path); err != nil {
+			return fmt.Errorf("redirect of path %s is not valid: %v", redirect.Path, err)
+		}
+		if redirect.PreservePath {
+			return fmt.Errorf("redirect of path %s is not valid, preservePath can only be used when the whole route is redirected", redirect.Path)
+		}
+		if paths[redirect.Path] {
+			return fmt.Errorf("path %s has more than one redirect or path route", redirect.Path)
+		}
+		paths[redirect.Path] = true
+	}
+	return nil
+}
+
+// validateRouteCertificate checks that the certificate of a route only comes from one place
+func validateRouteCertificate(route RouteV2) error {
+	tlsAcme := route.TLSAcme != nil && *route.TLSAcme
+	if route.Issuer != "" && route.ClusterIssuer != "" {
+		return fmt.Errorf("issuer and clusterIssuer can't both be used, the certificate can only come from one of them")
+	}
+	if route.TLSSecret != "" {
+		if tlsAcme {
+			return fmt.Errorf("tlsSecret can't be used with tls-acme: true, the certificate is in the secret")
+		}
+		if route.Issuer != "" || route.ClusterIssuer != "" {
+			return fmt.Errorf("tlsSecret can't be used with an issuer or clusterIssuer, the certificate is in the secret")
+		}
+		if errs := validation.IsDNS1123Subdomain(route.TLSSecret); errs != nil {
+			return fmt.Errorf("tlsSecret %s is not valid: %v", route.TLSSecret, strings.Join(errs, ", "))
+		}
+	}
+	for _, issuer := range []string{route.Issuer, route.ClusterIssuer} {
+		if issuer == "" {
+			continue
+		}
+		if !tlsAcme {
+			return fmt.Errorf("issuer %s can't be used with tls-acme: false, a certificate is only requested with tls-acme: true", issuer)
+		}
+		if errs := validation.IsDNS1123Subdomain(issuer); errs != nil {
+			return fmt.Errorf("issuer %s is not valid: %v", issuer, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidateCIDRs checks the ip ranges of an allow or deny list
+func ValidateCIDRs(cidrs []string) error {
+	for _, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("%s is not a valid cidr, use a range like 203.0.113.0/24, or 203.0.113.10/32 for a single address", cidr)
+		}
+	}
+	return nil
+}
+
+// ValidateBasicAuth checks the basic authentication of a route
+func ValidateBasicAuth(basicAuth *BasicAuth) error {
+	if basicAuth == nil || basicAuth.Disabled {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(basicAuth.Secret); errs != nil {
+		return fmt.Errorf("basic auth secret %s is not valid: %v", basicAuth.Secret, strings.Join(errs, ", "))
+	}
+	if strings.ContainsAny(basicAuth.Realm, "\t\r\n;{}'\"\\$`") {
+		return fmt.Errorf("basic auth realm %s is not valid, it can't contain new lines or any of ;{}'\"\\$`", basicAuth.Realm)
+	}
+	return nil
+}
+
+// validateRoutePath checks a path used in a redirect or rewrite
+func validateRoutePath(path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("it must start with /")
+	}
+	if strings.ContainsAny(path, unsafeRouteCharacters) || strings.ContainsAny(path, "?#") {
+		return fmt.Errorf("it can't contain spaces, a query, or any of ;{}'\"\\$`")
+	}
+	return nil
+}
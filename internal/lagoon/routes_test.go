@@ -408,6 +408,97 @@ func TestGenerateRouteStructure(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "test8 - wildcard with tls-acme true and a cluster issuer",
+			args: args{
+				yamlRoutes: &RoutesV2{},
+				yamlRouteMap: map[string][]Route{
+					"nginx": {
+						{
+							Ingresses: map[string]Ingress{
+								"www.example.com": {
+									TLSAcme:       helpers.BoolPtr(true),
+									Wildcard:      helpers.BoolPtr(true),
+									ClusterIssuer: "letsencrypt-dns01",
+								},
+							},
+						},
+					},
+				},
+				activeStandby: false,
+			},
+			want: &RoutesV2{
+				Routes: []RouteV2{
+					{
+						Domain:              "www.example.com",
+						LagoonService:       "nginx",
+						MonitoringPath:      "/",
+						Insecure:            helpers.StrPtr("Redirect"),
+						TLSAcme:             helpers.BoolPtr(true),
+						Annotations:         map[string]string{},
+						AlternativeNames:    []string{},
+						Wildcard:            helpers.BoolPtr(true),
+						IngressName:         "wildcard-www.example.com",
+						RequestVerification: helpers.BoolPtr(false),
+						ClusterIssuer:       "letsencrypt-dns01",
+					},
+				},
+			},
+		},
+		{
+			name: "test9 - tls secret turns off tls-acme",
+			args: args{
+				yamlRoutes: &RoutesV2{},
+				yamlRouteMap: map[string][]Route{
+					"nginx": {
+						{
+							Ingresses: map[string]Ingress{
+								"www.example.com": {
+									TLSSecret: "www-example-com-ev",
+								},
+							},
+						},
+					},
+				},
+				activeStandby: false,
+			},
+			want: &RoutesV2{
+				Routes: []RouteV2{
+					{
+						Domain:              "www.example.com",
+						LagoonService:       "nginx",
+						MonitoringPath:      "/",
+						Insecure:            helpers.StrPtr("Redirect"),
+						TLSAcme:             helpers.BoolPtr(false),
+						Annotations:         map[string]string{},
+						AlternativeNames:    []string{},
+						IngressName:         "www.example.com",
+						RequestVerification: helpers.BoolPtr(false),
+						TLSSecret:           "www-example-com-ev",
+					},
+				},
+			},
+		},
+		{
+			name: "test10 - tls secret with tls-acme true",
+			args: args{
+				yamlRoutes: &RoutesV2{},
+				yamlRouteMap: map[string][]Route{
+					"nginx": {
+						{
+							Ingresses: map[string]Ingress{
+								"www.example.com": {
+									TLSAcme:   helpers.BoolPtr(true),
+									TLSSecret: "www-example-com-ev",
+								},
+							},
+						},
+					},
+				},
+				activeStandby: false,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -772,3 +863,273 @@ func TestMergeRouteStructures(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRouteV2(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   RouteV2
+		wantErr bool
+	}{
+		{
+			name: "test1 valid headers, redirects and rewrites",
+			route: RouteV2{
+				Domain: "example.com",
+				ResponseHeaders: map[string]string{
+					"X-Frame-Options": "SAMEORIGIN",
+				},
+				PathRoutes: []PathRoute{
+					{ToService: "node", Path: "/api", Rewrite: "/"},
+				},
+				Redirects: []Redirect{
+					{Path: "/old", To: "/new", Permanent: true},
+					{Path: "/docs", To: "https://docs.example.com/"},
+				},
+			},
+		},
+		{
+			name: "test2 whole route redirect",
+			route: RouteV2{
+				Domain: "example.com",
+				Redirects: []Redirect{
+					{To: "https://www.example.com", PreservePath: true},
+				},
+			},
+		},
+		{
+			name: "test3 invalid header name",
+			route: RouteV2{
+				Domain: "example.com",
+				ResponseHeaders: map[string]string{
+					"X Frame Options": "SAMEORIGIN",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test4 hsts header",
+			route: RouteV2{
+				Domain: "example.com",
+				ResponseHeaders: map[string]string{
+					"strict-transport-security": "max-age=300",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test5 header value with a new line",
+			route: RouteV2{
+				Domain: "example.com",
+				ResponseHeaders: map[string]string{
+					"X-Test": "one\r\nX-Other: two",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test6 redirect with unsafe characters",
+			route: RouteV2{
+				Domain: "example.com",
+				Redirects: []Redirect{
+					{Path: "/old", To: "/new; return 200"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test7 whole route redirect to a path",
+			route: RouteV2{
+				Domain: "example.com",
+				Redirects: []Redirect{
+					{To: "/new"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test8 whole route redirect with path routes",
+			route: RouteV2{
+				Domain: "example.com",
+				PathRoutes: []PathRoute{
+					{ToService: "node", Path: "/api"},
+				},
+				Redirects: []Redirect{
+					{To: "https://www.example.com"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test9 redirect of a path route",
+			route: RouteV2{
+				Domain: "example.com",
+				PathRoutes: []PathRoute{
+					{ToService: "node", Path: "/api"},
+				},
+				Redirects: []Redirect{
+					{Path: "/api", To: "/v2/api"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test10 rewrite without a leading slash",
+			route: RouteV2{
+				Domain: "example.com",
+				PathRoutes: []PathRoute{
+					{ToService: "node", Path: "/api", Rewrite: "v2"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test11 preserve path on a path redirect",
+			route: RouteV2{
+				Domain: "example.com",
+				Redirects: []Redirect{
+					{Path: "/old", To: "https://www.example.com", PreservePath: true},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test12 access restrictions",
+			route: RouteV2{
+				Domain:     "example.com",
+				AllowCIDRs: []string{"203.0.113.0/24", "2001:db8::/32"},
+				DenyCIDRs:  []string{"203.0.113.10/32"},
+				BasicAuth: &BasicAuth{
+					Secret: "basic-auth",
+					Realm:  "Staging site",
+				},
+			},
+		},
+		{
+			name: "test13 invalid cidr",
+			route: RouteV2{
+				Domain:     "example.com",
+				AllowCIDRs: []string{"203.0.113.10"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test14 invalid basic auth secret",
+			route: RouteV2{
+				Domain: "example.com",
+				BasicAuth: &BasicAuth{
+					Secret: "Basic_Auth",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test15 basic auth realm with a quote",
+			route: RouteV2{
+				Domain: "example.com",
+				BasicAuth: &BasicAuth{
+					Secret: "basic-auth",
+					Realm:  "it's private",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test16 disabled basic auth",
+			route: RouteV2{
+				Domain: "example.com",
+				BasicAuth: &BasicAuth{
+					Disabled: true,
+				},
+			},
+		},
+		{
+			name: "test17 canary",
+			route: RouteV2{
+				Domain: "example.com",
+				Canary: &Canary{
+					Environment: "release",
+					Weight:      10,
+				},
+			},
+		},
+		{
+			name: "test18 canary weight over 100",
+			route: RouteV2{
+				Domain: "example.com",
+				Canary: &Canary{
+					Service: "node",
+					Weight:  150,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test19 canary without a service or environment",
+			route: RouteV2{
+				Domain: "example.com",
+				Canary: &Canary{
+					Weight: 10,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test20 canary of a redirected route",
+			route: RouteV2{
+				Domain: "example.com",
+				Canary: &Canary{
+					Service: "node",
+					Weight:  10,
+				},
+				Redirects: []Redirect{
+					{To: "https://www.example.com"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "test21 issuer and cluster issuer",
+			route: RouteV2{
+				Domain:        "example.com",
+				TLSAcme:       helpers.BoolPtr(true),
+				Issuer:        "letsencrypt",
+				ClusterIssuer: "letsencrypt",
+			},
+			wantErr: true,
+		},
+		{
+			name: "test22 tls secret and an issuer",
+			route: RouteV2{
+				Domain:    "example.com",
+				TLSAcme:   helpers.BoolPtr(false),
+				TLSSecret: "example-com-ev",
+				Issuer:    "letsencrypt",
+			},
+			wantErr: true,
+		},
+		{
+			name: "test23 issuer with tls-acme false",
+			route: RouteV2{
+				Domain:  "example.com",
+				TLSAcme: helpers.BoolPtr(false),
+				Issuer:  "letsencrypt",
+			},
+			wantErr: true,
+		},
+		{
+			name: "test24 invalid tls secret",
+			route: RouteV2{
+				Domain:    "example.com",
+				TLSAcme:   helpers.BoolPtr(false),
+				TLSSecret: "Example_Certificate",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateRouteV2(tt.route); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRouteV2() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package lagoon
+
+import (
+	"reflect"
+	"strings"
+)
+
+// boolStrings are the string values that the custom unmarshalers accept for fields that can be a bool or string,
+// these are the values accepted by strconv.ParseBool
+var boolStrings = []interface{}{"1", "t", "T", "TRUE", "true", "True", "0", "f", "F", "FALSE", "false", "False"}
+
+type schemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+// GenerateYAMLSchema generates the JSON Schema for the .lagoon.yml file from the YAML type.
+// Fields are described using their `description` tag, and fields that can be a bool or a string in the .lagoon.yml
+// are tagged with `schema:"boolOrString"`. Unknown keys are allowed, as lagoon ignores them during a build.
+func GenerateYAMLSchema() map[string]interface{} {
+	s := &schemaGenerator{definitions: map[string]interface{}{}}
+	root := s.structSchema(reflect.TypeOf(YAML{}))
+	s.schemaFor(reflect.TypeOf(YAML{}))
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["$id"] = "https://github.com/uselagoon/build-deploy-tool/lagoon-yml.schema.json"
+	root["title"] = ".lagoon.yml"
+	// a polysite .lagoon.yml has the full configuration for each project under a key named after the project,
+	// other top level keys like `project` are used by lagoon outside of builds
+	root["additionalProperties"] = map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"not": map[string]interface{}{"type": "object"}},
+			map[string]interface{}{"$ref": "#/definitions/YAML"},
+		},
+		"description": "the configuration for a polysite project, keyed by the project name",
+	}
+	root["definitions"] = s.definitions
+	return root
+}
+
+func (s *schemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(Route{}):
+		// a route is either a domain name, or a map of the domain name to the ingress configuration
+		if _, ok := s.definitions["Route"]; !ok {
+			s.definitions["Route"] = map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{
+						"type":        "string",
+						"description": "the domain of the route",
+					},
+					map[string]interface{}{
+						"type":                 "object",
+						"description":          "the domain of the route and its configuration",
+						"additionalProperties": s.schemaFor(reflect.TypeOf(Ingress{})),
+						"minProperties":        1,
+					},
+				},
+			}
+		}
+		return map[string]interface{}{"$ref": "#/definitions/Route"}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, ok := s.definitions[t.Name()]; !ok {
+			// add a placeholder first, so types that refer to themselves don't recurse forever
+			s.definitions[t.Name()] = map[string]interface{}{}
+			s.definitions[t.Name()] = s.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": s.schemaFor(t.Elem()),
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": s.schemaFor(t.Elem()),
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{}
+}
+
+func (s *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	s.addProperties(t, properties)
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+func (s *schemaGenerator) addProperties(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous && name == "" {
+			// embedded structs have their fields flattened into the parent
+			s.addProperties(field.Type, properties)
+			continue
+		}
+		if name == "-" || name == "" {
+			continue
+		}
+		var property map[string]interface{}
+		if field.Tag.Get("schema") == "boolOrString" {
+			property = map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "boolean"},
+					map[string]interface{}{"type": "string", "enum": boolStrings},
+				},
+			}
+		} else {
+			property = s.schemaFor(field.Type)
+		}
+		if description := field.Tag.Get("description"); description != "" {
+			if _, ok := property["$ref"]; ok {
+				// draft-07 ignores anything alongside a $ref, so wrap it
+				property = map[string]interface{}{"allOf": []interface{}{property}}
+			}
+			property["description"] = description
+		}
+		properties[name] = property
+	}
+}
@@ -0,0 +1,123 @@
+package lagoon
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/xeipuuv/gojsonschema"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGenerateYAMLSchema(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		lagoonYml string
+		wantValid bool
+	}{
+		{
+			name:      "test1 booleans represented as strings",
+			file:      "test-resources/lagoon-yaml/test1/lagoon.yml",
+			wantValid: true,
+		},
+		{
+			name:      "test2 polysite",
+			file:      "test-resources/lagoon-yaml/test9/lagoon.yml",
+			wantValid: true,
+		},
+		{
+			name:      "test3 container registries",
+			file:      "test-resources/lagoon-yaml/test8/lagoon.yml",
+			wantValid: true,
+		},
+		{
+			name: "test4 route as a string and a map",
+			lagoonYml: `
+environments:
+  main:
+    routes:
+      - nginx:
+        - a.example.com
+        - b.example.com:
+            tls-acme: "false"
+            insecure: Redirect
+            annotations:
+              nginx.ingress.kubernetes.io/permanent-redirect: https://www.example.com$request_uri
+`,
+			wantValid: true,
+		},
+		{
+			name: "test5 invalid boolean string",
+			lagoonYml: `
+routes:
+  autogenerate:
+    enabled: "nope"
+`,
+			wantValid: false,
+		},
+		{
+			name: "test6 route with an invalid type",
+			lagoonYml: `
+environments:
+  main:
+    routes:
+      - nginx:
+        - 1234
+`,
+			wantValid: false,
+		},
+		{
+			name: "test7 cronjob with an invalid type",
+			lagoonYml: `
+environments:
+  main:
+    cronjobs:
+      - name: drush cron
+        schedule: "M * * * *"
+        command: drush cron
+        service: cli
+        inPod: "yes"
+`,
+			wantValid: false,
+		},
+		{
+			name: "test8 invalid polysite project",
+			lagoonYml: `
+project-a:
+  environments: []
+`,
+			wantValid: false,
+		},
+	}
+	schemaJSON, err := json.Marshal(GenerateYAMLSchema())
+	if err != nil {
+		t.Fatalf("couldn't marshal schema: %v", err)
+	}
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schemaJSON))
+	if err != nil {
+		t.Fatalf("couldn't load schema: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawYAML := []byte(tt.lagoonYml)
+			if tt.file != "" {
+				rawYAML, err = os.ReadFile(tt.file)
+				if err != nil {
+					t.Fatalf("couldn't read %v: %v", tt.file, err)
+				}
+			}
+			rawJSON, err := yaml.YAMLToJSON(rawYAML)
+			if err != nil {
+				t.Fatalf("couldn't convert yaml: %v", err)
+			}
+			result, err := schema.Validate(gojsonschema.NewBytesLoader(rawJSON))
+			if err != nil {
+				t.Fatalf("couldn't validate: %v", err)
+			}
+			if result.Valid() != tt.wantValid {
+				t.Errorf("Validate() valid = %v, want %v, errors %v", result.Valid(), tt.wantValid, result.Errors())
+			}
+		})
+	}
+}
@@ -23,17 +23,17 @@ var debug bool
 
 // Task .
 type Task struct {
-	Name                string `json:"name"`
-	Command             string `json:"command"`
+	Name                string `json:"name" description:"the name of the task"`
+	Command             string `json:"command" description:"the command to run"`
 	Namespace           string `json:"namespace"`
-	Service             string `json:"service"`
-	Shell               string `json:"shell"`
-	Container           string `json:"container"`
-	When                string `json:"when"`
-	Weight              int    `json:"weight"`
-	ScaleWaitTime       int    `json:"scaleWaitTime"`
-	ScaleMaxIterations  int    `json:"scaleMaxIterations"`
-	RequiresEnvironment bool   `json:"requiresEnvironment"`
+	Service             string `json:"service" description:"the docker-compose service to run the task in"`
+	Shell               string `json:"shell" description:"the shell to run the command with"`
+	Container           string `json:"container" description:"the container to run the task in, for services with multiple containers"`
+	When                string `json:"when" description:"a condition that must be true for the task to run"`
+	Weight              int    `json:"weight" description:"the order to run the task in when merging tasks from the override file"`
+	ScaleWaitTime       int    `json:"scaleWaitTime" description:"how long to wait between checks when scaling the environment up for the task"`
+	ScaleMaxIterations  int    `json:"scaleMaxIterations" description:"how many times to check when scaling the environment up for the task"`
+	RequiresEnvironment bool   `json:"requiresEnvironment" description:"fail the task if the environment can't be scaled up"`
 }
 
 // NewTask .
@@ -76,7 +76,8 @@ func GetK8sClient(config *rest.Config) (*kubernetes.Clientset, error) {
 	return clientset, nil
 }
 
-func getConfig() (*rest.Config, error) {
+// GetK8sConfig returns the rest config from KUBECONFIG if set, otherwise it uses the deployer token from within the cluster
+func GetK8sConfig() (*rest.Config, error) {
 	var kubeconfig *string
 	kubeconfig = new(string)
 	*kubeconfig = helpers.GetEnv("KUBECONFIG", "", false)
@@ -144,7 +145,7 @@ func ExecTaskInPod(
 	tty bool,
 ) error {
 
-	restCfg, err := getConfig()
+	restCfg, err := GetK8sConfig()
 	if err != nil {
 		return err
 	}
@@ -310,7 +311,7 @@ func unidleReplicas(deploy appsv1.Deployment) int {
 var NamespaceUnidlingTimeoutError = errors.New("Unable to scale idled deployments due to timeout")
 
 func UnidleNamespace(ctx context.Context, namespace string, retries int, waitTime int) error {
-	restCfg, err := getConfig()
+	restCfg, err := GetK8sConfig()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,85 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Version is the version of the result envelope. It only changes if the envelope changes in a way that isn't backwards compatible,
+// new fields can be added without changing it.
+const Version = "build-deploy-tool/v1"
+
+// the supported output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Envelope wraps the result of a command when the output is json or yaml, so that anything consuming the output
+// has the same structure to work with regardless of the command
+type Envelope struct {
+	Version  string      `json:"version"`
+	Command  string      `json:"command"`
+	Success  bool        `json:"success"`
+	Warnings []Message   `json:"warnings"`
+	Errors   []Message   `json:"errors"`
+	Timings  Timings     `json:"timings"`
+	Result   interface{} `json:"result"`
+}
+
+// Message is a warning or error from a command
+type Message struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+	// Service is the docker-compose service the message is for, if any
+	Service string `json:"service,omitempty"`
+	// Source is where in the users files the message comes from, eg `.lagoon.yml:12`
+	Source string `json:"source,omitempty"`
+}
+
+// Timings are how long a command took to run
+type Timings struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds float64   `json:"durationSeconds"`
+}
+
+// ValidateFormat returns an error if the format isn't supported
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON, FormatYAML:
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %s, must be one of text, json or yaml", format)
+}
+
+// Write writes the envelope to w in the json or yaml format
+func Write(w io.Writer, format string, envelope Envelope) error {
+	envelope.Version = Version
+	if envelope.Warnings == nil {
+		envelope.Warnings = []Message{}
+	}
+	if envelope.Errors == nil {
+		envelope.Errors = []Message{}
+	}
+	var out []byte
+	var err error
+	switch format {
+	case FormatJSON:
+		out, err = json.MarshalIndent(envelope, "", "  ")
+	case FormatYAML:
+		out, err = yaml.Marshal(envelope)
+	default:
+		return fmt.Errorf("unsupported output format %s, must be one of json or yaml", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
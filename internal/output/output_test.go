@@ -0,0 +1,82 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		format   string
+		envelope Envelope
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:   "test1 json result",
+			format: FormatJSON,
+			envelope: Envelope{
+				Command: "identify feature",
+				Success: true,
+				Timings: Timings{Start: start, End: start.Add(1500 * time.Millisecond), DurationSeconds: 1.5},
+				Result:  "enabled",
+			},
+			want: `{
+  "version": "build-deploy-tool/v1",
+  "command": "identify feature",
+  "success": true,
+  "warnings": [],
+  "errors": [],
+  "timings": {
+    "start": "2024-01-01T00:00:00Z",
+    "end": "2024-01-01T00:00:01.5Z",
+    "durationSeconds": 1.5
+  },
+  "result": "enabled"
+}
+`,
+		},
+		{
+			name:   "test2 yaml error",
+			format: FormatYAML,
+			envelope: Envelope{
+				Command: "identify ingress",
+				Errors:  []Message{{Message: "missing arguments: branch not defined"}},
+				Timings: Timings{Start: start, End: start},
+			},
+			want: `command: identify ingress
+errors:
+- message: 'missing arguments: branch not defined'
+result: null
+success: false
+timings:
+  durationSeconds: 0
+  end: "2024-01-01T00:00:00Z"
+  start: "2024-01-01T00:00:00Z"
+version: build-deploy-tool/v1
+warnings: []
+
+`,
+		},
+		{
+			name:    "test3 text is not an envelope format",
+			format:  FormatText,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bytes.Buffer
+			if err := Write(&got, tt.format, tt.envelope); (err != nil) != tt.wantErr {
+				t.Errorf("Write() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("Write() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package servicetypes
+
+import (
+	"fmt"
+	"os"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// builtinServiceTypes are the service types compiled into the tool, the registry is reset to these before loading a file
+var builtinServiceTypes = func() map[string]ServiceType {
+	types := map[string]ServiceType{}
+	for name, serviceType := range ServiceTypes {
+		types[name] = serviceType
+	}
+	return types
+}()
+
+// LoadServiceTypes resets the service types registry to the built in types, then adds the service types defined in the provided
+// yaml file. Types in the file with the same name as a built in type are refused unless allowOverrides is set, then they replace it.
+func LoadServiceTypes(file string, allowOverrides bool) error {
+	serviceTypes := map[string]ServiceType{}
+	for name, serviceType := range builtinServiceTypes {
+		serviceTypes[name] = serviceType
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("couldn't read %v: %v", file, err)
+		}
+		custom := []ServiceType{}
+		if err := yaml.Unmarshal(data, &custom); err != nil {
+			return fmt.Errorf("couldn't unmarshal %v: %v", file, err)
+		}
+		loaded := map[string]bool{}
+		for _, serviceType := range custom {
+			if err := serviceType.validate(); err != nil {
+				return fmt.Errorf("service type in %v is not valid: %v", file, err)
+			}
+			if loaded[serviceType.Name] {
+				return fmt.Errorf("service type in %v is not valid: %s is defined more than once", file, serviceType.Name)
+			}
+			if _, ok := builtinServiceTypes[serviceType.Name]; ok && !allowOverrides {
+				return fmt.Errorf("service type in %v is not valid: %s is a built in service type, overriding built in types is not allowed", file, serviceType.Name)
+			}
+			// the init container is added to the deployment as is, so it needs the name too
+			if serviceType.InitContainer.Name != "" && serviceType.InitContainer.Container.Name == "" {
+				serviceType.InitContainer.Container.Name = serviceType.InitContainer.Name
+			}
+			serviceType.Custom = true
+			serviceTypes[serviceType.Name] = serviceType
+			loaded[serviceType.Name] = true
+		}
+	}
+	ServiceTypes = serviceTypes
+	return nil
+}
+
+func (s ServiceType) validate() error {
+	switch s.Name {
+	case "":
+		return fmt.Errorf("name is required")
+	case "none":
+		return fmt.Errorf("none is reserved for services that aren't deployed")
+	case "sidecar", "init":
+		return fmt.Errorf("%s is reserved for the sidecars and init containers of other services", s.Name)
+	case "job":
+		return fmt.Errorf("job is reserved for services that run as a kubernetes job")
+	}
+	if s.PrimaryContainer.Name == "" {
+		return fmt.Errorf("%s: primaryContainer.name is required", s.Name)
+	}
+	for _, port := range s.Ports.Ports {
+		if port.Port < 1 || port.Port > 65535 {
+			return fmt.Errorf("%s: port %s must be between 1 and 65535", s.Name, port.Name)
+		}
+	}
+	if len(s.Ports.Ports) > 0 && s.Ports.Ports[0].Name != "http" {
+		return fmt.Errorf("%s: the first port must be named http", s.Name)
+	}
+	if s.Ports.CanChangePort {
+		// the port of the container and the probes are changed when a service changes its port
+		c := s.PrimaryContainer.Container
+		if len(c.Ports) == 0 || c.ReadinessProbe == nil || c.ReadinessProbe.TCPSocket == nil ||
+			c.LivenessProbe == nil || c.LivenessProbe.TCPSocket == nil {
+			return fmt.Errorf("%s: canChangePort requires a container port, and tcp readiness and liveness probes", s.Name)
+		}
+	}
+	switch s.Strategy.Type {
+	case "", appsv1.RecreateDeploymentStrategyType, appsv1.RollingUpdateDeploymentStrategyType:
+	default:
+		return fmt.Errorf("%s: strategy.type must be %s or %s", s.Name, appsv1.RecreateDeploymentStrategyType, appsv1.RollingUpdateDeploymentStrategyType)
+	}
+	if s.Volumes.PersistentVolumeSize != "" {
+		if _, err := resource.ParseQuantity(s.Volumes.PersistentVolumeSize); err != nil {
+			return fmt.Errorf("%s: volumes.persistentVolumeSize %s is not a valid resource quantity", s.Name, s.Volumes.PersistentVolumeSize)
+		}
+		if s.Volumes.PersistentVolumePath == "" {
+			return fmt.Errorf("%s: volumes.persistentVolumePath is required with a persistentVolumeSize", s.Name)
+		}
+		switch s.Volumes.PersistentVolumeType {
+		case corev1.ReadWriteOnce, corev1.ReadWriteMany:
+		default:
+			return fmt.Errorf("%s: volumes.persistentVolumeType must be %s or %s", s.Name, corev1.ReadWriteOnce, corev1.ReadWriteMany)
+		}
+	} else if s.ProvidesPersistentVolume {
+		return fmt.Errorf("%s: providesPersistentVolume requires volumes.persistentVolumeSize", s.Name)
+	}
+	if s.Volumes.BackupConfiguration.Command != "" && s.Volumes.BackupConfiguration.FileExtension == "" {
+		return fmt.Errorf("%s: volumes.backupConfiguration.fileExtension is required with a backup command", s.Name)
+	}
+	for _, c := range []struct {
+		name      string
+		container ServiceContainer
+	}{
+		{"initContainer", s.InitContainer},
+		{"secondaryContainer", s.SecondaryContainer},
+	} {
+		if c.container.Name == "" && (c.container.Container.Image != "" || len(c.container.Command) > 0) {
+			return fmt.Errorf("%s: %s.name is required", s.Name, c.name)
+		}
+	}
+	if s.InitContainer.Name != "" && s.InitContainer.Container.Image == "" {
+		return fmt.Errorf("%s: initContainer.container.image is required", s.Name)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package servicetypes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadServiceTypes(t *testing.T) {
+	tests := []struct {
+		name           string
+		file           string
+		content        string
+		allowOverrides bool
+		wantTypes      []string
+		wantErr        bool
+	}{
+		{
+			name:      "test1 built in types",
+			wantTypes: []string{"basic", "nginx-php"},
+		},
+		{
+			name:      "test2 additional types",
+			file:      "../testdata/servicetypes/service-types.yml",
+			wantTypes: []string{"basic", "nginx-php", "memcached", "go-api"},
+		},
+		{
+			name: "test3 built in type",
+			content: `- name: basic
+  primaryContainer:
+    name: basic`,
+			wantErr: true,
+		},
+		{
+			name: "test4 built in type with overrides allowed",
+			content: `- name: basic
+  primaryContainer:
+    name: basic`,
+			allowOverrides: true,
+			wantTypes:      []string{"basic", "nginx-php"},
+		},
+		{
+			name: "test5 missing primary container",
+			content: `- name: memcached
+  ports:
+    ports:
+    - name: http
+      port: 11211`,
+			wantErr: true,
+		},
+		{
+			name: "test6 first port not http",
+			content: `- name: memcached
+  ports:
+    ports:
+    - name: memcache
+      port: 11211
+  primaryContainer:
+    name: memcached`,
+			wantErr: true,
+		},
+		{
+			name: "test7 persistent volume without a size",
+			content: `- name: memcached
+  providesPersistentVolume: true
+  primaryContainer:
+    name: memcached`,
+			wantErr: true,
+		},
+		{
+			name: "test8 can change port without tcp probes",
+			content: `- name: memcached
+  ports:
+    canChangePort: true
+  primaryContainer:
+    name: memcached`,
+			wantErr: true,
+		},
+		{
+			name: "test9 defined twice",
+			content: `- name: memcached
+  primaryContainer:
+    name: memcached
+- name: memcached
+  primaryContainer:
+    name: memcached`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer LoadServiceTypes("", false)
+			file := tt.file
+			if tt.content != "" {
+				file = filepath.Join(t.TempDir(), "service-types.yml")
+				if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+					t.Fatalf("%v", err)
+				}
+			}
+			err := LoadServiceTypes(file, tt.allowOverrides)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadServiceTypes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				if len(ServiceTypes) != len(builtinServiceTypes) {
+					t.Errorf("LoadServiceTypes() changed the registry on error")
+				}
+				return
+			}
+			for _, name := range tt.wantTypes {
+				if _, ok := ServiceTypes[name]; !ok {
+					t.Errorf("LoadServiceTypes() missing type %v", name)
+				}
+			}
+			if tt.allowOverrides && !ServiceTypes["basic"].Custom {
+				t.Errorf("LoadServiceTypes() didn't override the built in type")
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package servicetypes
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DBaaSType is a type of database that is provided by a dbaas-operator. A service with the type `<name>` or `<name>-dbaas`
+// creates a consumer of a provider if the dbaas-operator has one for the environment, otherwise it uses the single type.
+type DBaaSType struct {
+	// Name is the type of the provider in the dbaas-operator, eg `mariadb`
+	Name string `json:"name"`
+	// ConsumerKind and ConsumerAPIVersion are the custom resource created for the service, eg `MariaDBConsumer`
+	ConsumerKind       string `json:"consumerKind"`
+	ConsumerAPIVersion string `json:"consumerAPIVersion"`
+	// SingleType is the service type used if there is no provider, eg `mariadb-single`
+	SingleType string `json:"singleType"`
+	// ConsumerSpec is the spec of the consumer, the dbaas environment is added to it
+	ConsumerSpec map[string]interface{} `json:"consumerSpec,omitempty"`
+	// EnvVars are added to the lagoon-env configmap once the consumer has been provisioned
+	EnvVars []DBaaSEnvVar `json:"envVars"`
+}
+
+// DBaaSEnvVar is a variable with a value from the consumer, the name of the variable is prefixed with the service name
+// eg `MARIADB_HOST`
+type DBaaSEnvVar struct {
+	Name string `json:"name"`
+	// Path is the path to the value in the consumer, eg `spec.consumer.services.primary`
+	Path string `json:"path"`
+	// Optional variables are only added if the consumer has a value for them
+	Optional bool `json:"optional,omitempty"`
+}
+
+// the variables the amazeeio/dbaas-operator consumers provide
+var dbaasEnvVars = []DBaaSEnvVar{
+	{Name: "HOST", Path: "spec.consumer.services.primary"},
+	{Name: "USERNAME", Path: "spec.consumer.username"},
+	{Name: "PASSWORD", Path: "spec.consumer.password"},
+	{Name: "DATABASE", Path: "spec.consumer.database"},
+	{Name: "PORT", Path: "spec.provider.port"},
+}
+
+// DBaaSTypes are the types of database that the build can request from a dbaas-operator
+var DBaaSTypes = map[string]DBaaSType{
+	"mariadb": {
+		Name:               "mariadb",
+		ConsumerKind:       "MariaDBConsumer",
+		ConsumerAPIVersion: "mariadb.amazee.io/v1",
+		SingleType:         "mariadb-single",
+		ConsumerSpec: map[string]interface{}{
+			"consumer": map[string]interface{}{"services": map[string]interface{}{}},
+			"provider": map[string]interface{}{},
+		},
+		EnvVars: append(append([]DBaaSEnvVar{}, dbaasEnvVars...),
+			DBaaSEnvVar{Name: "READREPLICA_HOSTS", Path: "spec.consumer.services.replicas", Optional: true},
+		),
+	},
+	"postgres": {
+		Name:               "postgres",
+		ConsumerKind:       "PostgreSQLConsumer",
+		ConsumerAPIVersion: "postgres.amazee.io/v1",
+		SingleType:         "postgres-single",
+		ConsumerSpec: map[string]interface{}{
+			"consumer": map[string]interface{}{"services": map[string]interface{}{}},
+			"provider": map[string]interface{}{},
+		},
+		EnvVars: append(append([]DBaaSEnvVar{}, dbaasEnvVars...),
+			DBaaSEnvVar{Name: "READREPLICA_HOSTS", Path: "spec.consumer.services.replicas", Optional: true},
+		),
+	},
+	"mongodb": {
+		Name:               "mongodb",
+		ConsumerKind:       "MongoDBConsumer",
+		ConsumerAPIVersion: "mongodb.amazee.io/v1",
+		SingleType:         "mongodb-single",
+		ConsumerSpec: map[string]interface{}{
+			"consumer": map[string]interface{}{
+				"auth":     map[string]interface{}{"tls": false},
+				"services": map[string]interface{}{},
+			},
+			"provider": map[string]interface{}{
+				"auth": map[string]interface{}{"tls": false},
+			},
+		},
+		EnvVars: append(append([]DBaaSEnvVar{}, dbaasEnvVars...),
+			DBaaSEnvVar{Name: "AUTHSOURCE", Path: "spec.provider.auth.source"},
+			DBaaSEnvVar{Name: "AUTHMECHANISM", Path: "spec.provider.auth.mechanism"},
+			DBaaSEnvVar{Name: "AUTHTLS", Path: "spec.provider.auth.tls"},
+		),
+	},
+}
+
+// GetDBaaSTypes returns the dbaas types, including any additional types defined in the provided yaml file.
+// Types in the file with the same name as a built in type are refused unless allowOverrides is set, then they replace it.
+func GetDBaaSTypes(file string, allowOverrides bool) (map[string]DBaaSType, error) {
+	dbaasTypes := map[string]DBaaSType{}
+	for name, dbaasType := range DBaaSTypes {
+		dbaasTypes[name] = dbaasType
+	}
+	if file == "" {
+		return dbaasTypes, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read %v: %v", file, err)
+	}
+	additional := []DBaaSType{}
+	if err := yaml.Unmarshal(data, &additional); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal %v: %v", file, err)
+	}
+	loaded := map[string]bool{}
+	for _, dbaasType := range additional {
+		if err := dbaasType.validate(); err != nil {
+			return nil, fmt.Errorf("dbaas type in %v is not valid: %v", file, err)
+		}
+		if loaded[dbaasType.Name] {
+			return nil, fmt.Errorf("dbaas type in %v is not valid: %s is defined more than once", file, dbaasType.Name)
+		}
+		if _, ok := DBaaSTypes[dbaasType.Name]; ok && !allowOverrides {
+			return nil, fmt.Errorf("dbaas type in %v is not valid: %s is a built in dbaas type, overriding built in types is not allowed", file, dbaasType.Name)
+		}
+		dbaasTypes[dbaasType.Name] = dbaasType
+		loaded[dbaasType.Name] = true
+	}
+	return dbaasTypes, nil
+}
+
+func (d DBaaSType) validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if d.ConsumerKind == "" || d.ConsumerAPIVersion == "" {
+		return fmt.Errorf("%s: consumerKind and consumerAPIVersion are required", d.Name)
+	}
+	if _, ok := ServiceTypes[d.SingleType]; !ok {
+		return fmt.Errorf("%s: singleType %q is not a known service type", d.Name, d.SingleType)
+	}
+	for _, envVar := range d.EnvVars {
+		if envVar.Name == "" || envVar.Path == "" {
+			return fmt.Errorf("%s: envVars require a name and a path", d.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package servicetypes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetDBaaSTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		overrides bool
+		wantTypes []string
+		wantErr   bool
+	}{
+		{
+			name:      "test1 built in types",
+			wantTypes: []string{"mariadb", "postgres", "mongodb"},
+		},
+		{
+			name:      "test2 additional types",
+			file:      "../testdata/dbaas/dbaas-types.yml",
+			wantTypes: []string{"mariadb", "postgres", "mongodb", "valkey", "opensearch"},
+		},
+		{
+			name: "test3 unknown single type",
+			content: `- name: valkey
+  consumerKind: ValkeyConsumer
+  consumerAPIVersion: valkey.example.com/v1
+  singleType: valkey-single`,
+			wantErr: true,
+		},
+		{
+			name: "test4 missing consumer kind",
+			content: `- name: valkey
+  singleType: valkey`,
+			wantErr: true,
+		},
+		{
+			name: "test5 env var without a path",
+			content: `- name: valkey
+  consumerKind: ValkeyConsumer
+  consumerAPIVersion: valkey.example.com/v1
+  singleType: valkey
+  envVars:
+  - name: HOST`,
+			wantErr: true,
+		},
+		{
+			name: "test6 built in type override not allowed",
+			content: `- name: mariadb
+  consumerKind: MariaDBConsumer
+  consumerAPIVersion: mariadb.example.com/v1
+  singleType: mariadb-single`,
+			wantErr: true,
+		},
+		{
+			name: "test7 built in type override allowed",
+			content: `- name: mariadb
+  consumerKind: MariaDBConsumer
+  consumerAPIVersion: mariadb.example.com/v1
+  singleType: mariadb-single`,
+			overrides: true,
+			wantTypes: []string{"mariadb", "postgres", "mongodb"},
+		},
+		{
+			name: "test8 type defined more than once",
+			content: `- name: valkey
+  consumerKind: ValkeyConsumer
+  consumerAPIVersion: valkey.example.com/v1
+  singleType: valkey
+- name: valkey
+  consumerKind: ValkeyConsumer
+  consumerAPIVersion: valkey.example.com/v1
+  singleType: valkey`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := tt.file
+			if tt.content != "" {
+				file = filepath.Join(t.TempDir(), "dbaas-types.yml")
+				if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := GetDBaaSTypes(file, tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetDBaaSTypes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.wantTypes) {
+				t.Errorf("GetDBaaSTypes() = %v types, want %v", len(got), len(tt.wantTypes))
+			}
+			for _, name := range tt.wantTypes {
+				if _, ok := got[name]; !ok {
+					t.Errorf("GetDBaaSTypes() missing type %v", name)
+				}
+			}
+		})
+	}
+}
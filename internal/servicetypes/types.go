@@ -5,58 +5,64 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
+// ServiceType is the definition of a lagoon service type, the json names are used by service types loaded from a file
 type ServiceType struct {
-	Name                     string
-	Ports                    ServicePorts
-	Volumes                  ServiceVolume
-	Strategy                 appsv1.DeploymentStrategy
-	PrimaryContainer         ServiceContainer
-	InitContainer            ServiceContainer
-	SecondaryContainer       ServiceContainer
-	PodSecurityContext       ServicePodSecurityContext
-	EnableServiceLinks       bool
-	ProvidesPersistentVolume bool
-	ConsumesPersistentVolume bool
-	AllowAdditionalVolumes   bool
+	Name                     string                    `json:"name"`
+	Ports                    ServicePorts              `json:"ports,omitempty"`
+	Volumes                  ServiceVolume             `json:"volumes,omitempty"`
+	Strategy                 appsv1.DeploymentStrategy `json:"strategy,omitempty"`
+	PrimaryContainer         ServiceContainer          `json:"primaryContainer"`
+	InitContainer            ServiceContainer          `json:"initContainer,omitempty"`
+	SecondaryContainer       ServiceContainer          `json:"secondaryContainer,omitempty"`
+	PodSecurityContext       ServicePodSecurityContext `json:"podSecurityContext,omitempty"`
+	EnableServiceLinks       bool                      `json:"enableServiceLinks,omitempty"`
+	ProvidesPersistentVolume bool                      `json:"providesPersistentVolume,omitempty"`
+	ConsumesPersistentVolume bool                      `json:"consumesPersistentVolume,omitempty"`
+	AllowAdditionalVolumes   bool                      `json:"allowAdditionalVolumes,omitempty"`
+	// AutogeneratedRoutes is only used by service types loaded from a file, the built in types that support
+	// autogenerated routes are listed in the generator
+	AutogeneratedRoutes bool `json:"autogeneratedRoutes,omitempty"`
+	// Custom is set on service types loaded from a file
+	Custom bool `json:"-"`
 }
 
 type ServicePodSecurityContext struct {
-	HasDefault bool
-	FSGroup    int64
+	HasDefault bool  `json:"hasDefault,omitempty"`
+	FSGroup    int64 `json:"fsGroup,omitempty"`
 }
 
 type ServiceContainer struct {
-	Name            string
-	ImagePullPolicy corev1.PullPolicy
-	Container       corev1.Container
+	Name            string            `json:"name,omitempty"`
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	Container       corev1.Container  `json:"container,omitempty"`
 	// define additional volumes here, can leverage 'go template' with generator.ServiceValues
-	Volumes      []corev1.Volume
-	VolumeMounts []corev1.VolumeMount
-	Command      []string
-	FeatureFlags map[string]bool
+	Volumes      []corev1.Volume      `json:"volumes,omitempty"`
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	Command      []string             `json:"command,omitempty"`
+	FeatureFlags map[string]bool      `json:"featureFlags,omitempty"`
 	// define additional variables here, this can be used by types that inherit from another type
-	EnvVars []corev1.EnvVar
+	EnvVars []corev1.EnvVar `json:"envVars,omitempty"`
 }
 
 type ServiceVolume struct {
-	PersistentVolumeSize   string
-	PersistentVolumePath   string
-	PersistentVolumeType   corev1.PersistentVolumeAccessMode
-	SourceFromOtherService string
-	Backup                 bool
-	BackupConfiguration    BackupConfiguration
+	PersistentVolumeSize   string                            `json:"persistentVolumeSize,omitempty"`
+	PersistentVolumePath   string                            `json:"persistentVolumePath,omitempty"`
+	PersistentVolumeType   corev1.PersistentVolumeAccessMode `json:"persistentVolumeType,omitempty"`
+	SourceFromOtherService string                            `json:"sourceFromOtherService,omitempty"`
+	Backup                 bool                              `json:"backup,omitempty"`
+	BackupConfiguration    BackupConfiguration               `json:"backupConfiguration,omitempty"`
 }
 
 type BackupConfiguration struct {
-	Command       string
-	FileExtension string
+	Command       string `json:"command,omitempty"`
+	FileExtension string `json:"fileExtension,omitempty"`
 }
 
 // when defining default ServicePorts for a service, the first port in the list should be the port that could be associated to an ingress
 // the name of this port must be `http`
 type ServicePorts struct {
-	CanChangePort bool
-	Ports         []corev1.ServicePort
+	CanChangePort bool                 `json:"canChangePort,omitempty"`
+	Ports         []corev1.ServicePort `json:"ports,omitempty"`
 }
 
 // this is a map that maps the lagoon service-type that can be provided in the `lagoon.type` label to the default values for that service
@@ -9,9 +9,14 @@ import (
 	"github.com/andreyvit/diff"
 	"github.com/uselagoon/build-deploy-tool/internal/dbaasclient"
 	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
 )
 
 func TestGenerateDBaaSTemplate(t *testing.T) {
+	additionalDBaaSTypes, err := servicetypes.GetDBaaSTypes("../testdata/dbaas/dbaas-types.yml", false)
+	if err != nil {
+		t.Fatal(err)
+	}
 	type args struct {
 		lValues generator.BuildValues
 	}
@@ -147,6 +152,43 @@ func TestGenerateDBaaSTemplate(t *testing.T) {
 			},
 			want: "test-resources/dbaas/result-mariadb-2.yaml",
 		},
+		{
+			name: "test6 - additional dbaas types",
+			args: args{
+				lValues: generator.BuildValues{
+					Project:         "example-project",
+					Environment:     "environment-with-really-really-reall-3fdb",
+					EnvironmentType: "production",
+					Namespace:       "myexample-project-environment-with-really-really-reall-3fdb",
+					BuildType:       "branch",
+					LagoonVersion:   "v2.x.x",
+					Kubernetes:      "generator.local",
+					Branch:          "environment-with-really-really-reall-3fdb",
+					DBaaSTypes:      additionalDBaaSTypes,
+					Services: []generator.ServiceValues{
+						{
+							Name:             "mariadb",
+							OverrideName:     "mariadb",
+							Type:             "mariadb-dbaas",
+							DBaaSEnvironment: "production",
+						},
+						{
+							Name:             "valkey",
+							OverrideName:     "valkey",
+							Type:             "valkey-dbaas",
+							DBaaSEnvironment: "production",
+						},
+						{
+							Name:             "search",
+							OverrideName:     "search",
+							Type:             "opensearch-dbaas",
+							DBaaSEnvironment: "production",
+						},
+					},
+				},
+			},
+			want: "test-resources/dbaas/result-additional-1.yaml",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -7,11 +7,23 @@ import (
 
 // LinkedServiceCalculator checks the provided services to see if there are any linked services
 // linked services are mostly just `nginx-php` but lagoon has the possibility to support more than this in the future
-func LinkedServiceCalculator(services []generator.ServiceValues) []generator.ServiceValues {
+// any sidecars and init containers are removed from the services and added to the service they belong to
+func LinkedServiceCalculator(allServices []generator.ServiceValues) []generator.ServiceValues {
 	linkedMap := make(map[string][]generator.ServiceValues)
 	retServices := []generator.ServiceValues{}
 	linkedOrder := []string{}
 
+	// sidecars and init containers are never deployed on their own, so take them out before working out the linked services
+	services := []generator.ServiceValues{}
+	sidecars := []generator.ServiceValues{}
+	for _, s := range allServices {
+		if s.SidecarOf != "" {
+			sidecars = append(sidecars, s)
+		} else {
+			services = append(services, s)
+		}
+	}
+
 	// go over the services twice to extract just the linked services (the override names will be the same in a linked service)
 	for _, s1 := range services {
 		for _, s2 := range services {
@@ -47,5 +59,18 @@ func LinkedServiceCalculator(services []generator.ServiceValues) []generator.Ser
 		// then add it to the slice of services to return
 		retServices = append(retServices, service)
 	}
+
+	// add the sidecars and init containers to the service they belong to, this can be either service of a linked service
+	for idx, service := range retServices {
+		for _, sidecar := range sidecars {
+			if sidecar.SidecarOf == service.Name || (service.LinkedService != nil && sidecar.SidecarOf == service.LinkedService.Name) {
+				if sidecar.Type == generator.InitContainerServiceType {
+					retServices[idx].InitContainers = append(retServices[idx].InitContainers, sidecar)
+				} else {
+					retServices[idx].Sidecars = append(retServices[idx].Sidecars, sidecar)
+				}
+			}
+		}
+	}
 	return retServices
 }
@@ -10,7 +10,6 @@ import (
 	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -226,6 +225,10 @@ func GenerateDeploymentTemplate(
 			if serviceValues.Replicas != 0 {
 				deployment.Spec.Replicas = helpers.Int32Ptr(serviceValues.Replicas)
 			}
+			if serviceValues.Autoscaling != nil {
+				// the horizontal pod autoscaler manages the replicas
+				deployment.Spec.Replicas = nil
+			}
 			deployment.Spec.Selector = &metav1.LabelSelector{
 				MatchLabels: map[string]string{
 					"app.kubernetes.io/name":     serviceTypeValues.Name,
@@ -410,7 +413,7 @@ func GenerateDeploymentTemplate(
 										},
 									},
 								},
-								InitialDelaySeconds: 1, // @TODO make the timeout/delays configurable?
+								InitialDelaySeconds: 1,
 								TimeoutSeconds:      1,
 							}
 							container.Container.LivenessProbe = &corev1.Probe{
@@ -422,7 +425,7 @@ func GenerateDeploymentTemplate(
 										},
 									},
 								},
-								InitialDelaySeconds: 60, // @TODO make the timeout/delays configurable?
+								InitialDelaySeconds: 60,
 								TimeoutSeconds:      10,
 							}
 						default:
@@ -448,6 +451,10 @@ func GenerateDeploymentTemplate(
 					}
 				}
 			}
+			// merge any probe overrides from the service labels over the service type probes
+			if err := applyProbeOverrides(&container.Container, serviceValues.Probes); err != nil {
+				return nil, fmt.Errorf("the probes for %s are not valid: %v", serviceValues.Name, err)
+			}
 
 			// handle setting the rest of the containers specs with values from the service or build values
 			container.Container.Name = container.Name
@@ -574,33 +581,7 @@ func GenerateDeploymentTemplate(
 			}
 
 			// Below are included to override from service values in tag1 fork
-			if serviceValues.Resources.Requests.Cpu != "" {
-				if container.Container.Resources.Requests == nil {
-					container.Container.Resources.Requests = corev1.ResourceList{}
-				}
-				container.Container.Resources.Requests[v1.ResourceCPU] = resource.MustParse(serviceValues.Resources.Requests.Cpu)
-			}
-
-			if serviceValues.Resources.Requests.Memory != "" {
-				if container.Container.Resources.Requests == nil {
-					container.Container.Resources.Requests = corev1.ResourceList{}
-				}
-				container.Container.Resources.Requests[v1.ResourceMemory] = resource.MustParse(serviceValues.Resources.Requests.Memory)
-			}
-
-			if serviceValues.Resources.Limits.Cpu != "" {
-				if container.Container.Resources.Limits == nil {
-					container.Container.Resources.Limits = corev1.ResourceList{}
-				}
-				container.Container.Resources.Limits[v1.ResourceCPU] = resource.MustParse(serviceValues.Resources.Limits.Cpu)
-			}
-
-			if serviceValues.Resources.Limits.Memory != "" {
-				if container.Container.Resources.Limits == nil {
-					container.Container.Resources.Limits = corev1.ResourceList{}
-				}
-				container.Container.Resources.Limits[v1.ResourceMemory] = resource.MustParse(serviceValues.Resources.Limits.Memory)
-			}
+			applyResourceOverrides(&container.Container, serviceValues.Resources)
 
 			// append the final defined container to the spec
 			deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers, container.Container)
@@ -704,37 +685,27 @@ func GenerateDeploymentTemplate(
 				}
 
 				// Below are included to override from service values in tag1 fork
-				if serviceValues.LinkedService.Resources.Limits.Memory != "" {
-					if linkedContainer.Container.Resources.Limits == nil {
-						linkedContainer.Container.Resources.Limits = corev1.ResourceList{}
-					}
-					linkedContainer.Container.Resources.Limits[v1.ResourceMemory] = resource.MustParse(serviceValues.LinkedService.Resources.Limits.Memory)
-				}
-
-				if serviceValues.LinkedService.Resources.Requests.Cpu != "" {
-					if linkedContainer.Container.Resources.Requests == nil {
-						linkedContainer.Container.Resources.Requests = corev1.ResourceList{}
-					}
-					linkedContainer.Container.Resources.Requests[v1.ResourceCPU] = resource.MustParse(serviceValues.LinkedService.Resources.Requests.Cpu)
-				}
-
-				if serviceValues.LinkedService.Resources.Requests.Memory != "" {
-					if linkedContainer.Container.Resources.Requests == nil {
-						linkedContainer.Container.Resources.Requests = corev1.ResourceList{}
-					}
-					linkedContainer.Container.Resources.Requests[v1.ResourceMemory] = resource.MustParse(serviceValues.LinkedService.Resources.Requests.Memory)
-				}
-
-				if serviceValues.LinkedService.Resources.Limits.Cpu != "" {
-					if linkedContainer.Container.Resources.Limits == nil {
-						linkedContainer.Container.Resources.Limits = corev1.ResourceList{}
-					}
-					linkedContainer.Container.Resources.Limits[v1.ResourceCPU] = resource.MustParse(serviceValues.LinkedService.Resources.Limits.Cpu)
+				applyResourceOverrides(&linkedContainer.Container, serviceValues.LinkedService.Resources)
+				// resources for the secondary container defined on the primary service are used over the linked service
+				if serviceValues.SecondaryResources != nil {
+					applyResourceOverrides(&linkedContainer.Container, *serviceValues.SecondaryResources)
 				}
 
 				deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers, linkedContainer.Container)
 			}
 
+			// add any init containers and sidecars defined in the docker-compose file
+			initContainers, err := generateSidecarContainers(buildValues, serviceValues, serviceValues.InitContainers, container.Container, deployment.Spec.Template.Spec)
+			if err != nil {
+				return nil, err
+			}
+			deployment.Spec.Template.Spec.InitContainers = append(deployment.Spec.Template.Spec.InitContainers, initContainers...)
+			sidecars, err := generateSidecarContainers(buildValues, serviceValues, serviceValues.Sidecars, container.Container, deployment.Spec.Template.Spec)
+			if err != nil {
+				return nil, err
+			}
+			deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers, sidecars...)
+
 			// end deployment template
 			deployments = append(deployments, *deployment)
 		}
@@ -742,6 +713,34 @@ func GenerateDeploymentTemplate(
 	return deployments, nil
 }
 
+// applyResourceOverrides sets any resource requirements from the service values on the container
+func applyResourceOverrides(container *corev1.Container, resources generator.Resources) {
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU:              resources.Requests.Cpu,
+		corev1.ResourceMemory:           resources.Requests.Memory,
+		corev1.ResourceEphemeralStorage: resources.Requests.EphemeralStorage,
+	} {
+		if value != "" {
+			if container.Resources.Requests == nil {
+				container.Resources.Requests = corev1.ResourceList{}
+			}
+			container.Resources.Requests[name] = resource.MustParse(value)
+		}
+	}
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU:              resources.Limits.Cpu,
+		corev1.ResourceMemory:           resources.Limits.Memory,
+		corev1.ResourceEphemeralStorage: resources.Limits.EphemeralStorage,
+	} {
+		if value != "" {
+			if container.Resources.Limits == nil {
+				container.Resources.Limits = corev1.ResourceList{}
+			}
+			container.Resources.Limits[name] = resource.MustParse(value)
+		}
+	}
+}
+
 func TemplateDeployment(item appsv1.Deployment) ([]byte, error) {
 	separator := []byte("---\n")
 	iBytes, err := yaml.Marshal(item)
@@ -9,6 +9,7 @@ import (
 	"github.com/andreyvit/diff"
 	"github.com/compose-spec/compose-go/types"
 	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
 	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
 )
 
@@ -1056,6 +1057,52 @@ func TestGenerateDeploymentTemplate(t *testing.T) {
 			},
 			want: "test-resources/deployment/result-nginx-php-resources-1.yaml",
 		},
+		{
+			name: "test21 - node probe overrides",
+			args: args{
+				buildValues: generator.BuildValues{
+					Project:         "example-project",
+					Environment:     "environment-name",
+					EnvironmentType: "production",
+					Namespace:       "myexample-project-environment-name",
+					BuildType:       "branch",
+					LagoonVersion:   "v2.x.x",
+					Kubernetes:      "generator.local",
+					Branch:          "environment-name",
+					PodSecurityContext: generator.PodSecurityContext{
+						RunAsGroup: 0,
+						RunAsUser:  10000,
+						FsGroup:    10001,
+					},
+					GitSHA:       "0",
+					ConfigMapSha: "32bf1359ac92178c8909f0ef938257b477708aa0d78a5a15ad7c2d7919adf273",
+					ImageReferences: map[string]string{
+						"node": "harbor.example.com/example-project/environment-name/node@latest",
+					},
+					Services: []generator.ServiceValues{
+						{
+							Name:         "node",
+							OverrideName: "node",
+							Type:         "node",
+							Probes: &generator.Probes{
+								Readiness: &generator.Probe{
+									Path:          "/healthz",
+									PeriodSeconds: helpers.Int32Ptr(5),
+								},
+								Liveness: &generator.Probe{
+									InitialDelaySeconds: helpers.Int32Ptr(120),
+								},
+								Startup: &generator.Probe{
+									PeriodSeconds:    helpers.Int32Ptr(10),
+									FailureThreshold: helpers.Int32Ptr(30),
+								},
+							},
+						},
+					},
+				},
+			},
+			want: "test-resources/deployment/result-node-probes-1.yaml",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
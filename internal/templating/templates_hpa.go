@@ -0,0 +1,118 @@
+package templating
+
+import (
+	"fmt"
+
+	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// GenerateHPATemplate generates the horizontal pod autoscalers for any services that autoscale
+func GenerateHPATemplate(
+	buildValues generator.BuildValues,
+) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	var hpas []autoscalingv2.HorizontalPodAutoscaler
+
+	// check linked services
+	checkedServices := LinkedServiceCalculator(buildValues.Services)
+
+	// for all the services that the build values generated
+	// iterate over them and generate any horizontal pod autoscalers
+	for _, serviceValues := range checkedServices {
+		if serviceValues.Autoscaling == nil {
+			continue
+		}
+		serviceTypeValues, ok := servicetypes.ServiceTypes[serviceValues.Type]
+		if !ok {
+			continue
+		}
+		// add the default labels
+		labels := map[string]string{
+			"app.kubernetes.io/managed-by": "build-deploy-tool",
+			"app.kubernetes.io/name":       serviceTypeValues.Name,
+			"app.kubernetes.io/instance":   serviceValues.OverrideName,
+			"lagoon.sh/project":            buildValues.Project,
+			"lagoon.sh/environment":        buildValues.Environment,
+			"lagoon.sh/environmentType":    buildValues.EnvironmentType,
+			"lagoon.sh/buildType":          buildValues.BuildType,
+			"lagoon.sh/template":           fmt.Sprintf("%s-%s", serviceTypeValues.Name, "0.1.0"),
+			"lagoon.sh/service":            serviceValues.OverrideName,
+			"lagoon.sh/service-type":       serviceTypeValues.Name,
+		}
+
+		// add the default annotations
+		annotations := map[string]string{
+			"lagoon.sh/version": buildValues.LagoonVersion,
+		}
+		if buildValues.BuildType == "branch" {
+			annotations["lagoon.sh/branch"] = buildValues.Branch
+		} else if buildValues.BuildType == "pullrequest" {
+			annotations["lagoon.sh/prNumber"] = buildValues.PRNumber
+			annotations["lagoon.sh/prHeadBranch"] = buildValues.PRHeadBranch
+			annotations["lagoon.sh/prBaseBranch"] = buildValues.PRBaseBranch
+		}
+
+		hpa := autoscalingv2.HorizontalPodAutoscaler{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "HorizontalPodAutoscaler",
+				APIVersion: autoscalingv2.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        serviceValues.OverrideName,
+				Labels:      labels,
+				Annotations: annotations,
+			},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+					Kind:       "Deployment",
+					Name:       serviceValues.OverrideName,
+					APIVersion: appsv1.SchemeGroupVersion.String(),
+				},
+				MinReplicas: helpers.Int32Ptr(serviceValues.Autoscaling.MinReplicas),
+				MaxReplicas: serviceValues.Autoscaling.MaxReplicas,
+				Metrics: []autoscalingv2.MetricSpec{
+					{
+						Type: autoscalingv2.ResourceMetricSourceType,
+						Resource: &autoscalingv2.ResourceMetricSource{
+							Name: corev1.ResourceCPU,
+							Target: autoscalingv2.MetricTarget{
+								Type:               autoscalingv2.UtilizationMetricType,
+								AverageUtilization: helpers.Int32Ptr(serviceValues.Autoscaling.TargetCPUUtilization),
+							},
+						},
+					},
+				},
+			},
+		}
+		// validate any labels
+		if err := metavalidation.ValidateLabels(hpa.ObjectMeta.Labels, nil); err != nil {
+			if len(err) != 0 {
+				return nil, fmt.Errorf("the labels for %s are not valid: %v", serviceValues.OverrideName, err)
+			}
+		}
+		// check length of labels
+		if err := helpers.CheckLabelLength(hpa.ObjectMeta.Labels); err != nil {
+			return nil, err
+		}
+		hpas = append(hpas, hpa)
+	}
+	return hpas, nil
+}
+
+// TemplateHPA templates a horizontal pod autoscaler
+func TemplateHPA(item autoscalingv2.HorizontalPodAutoscaler) ([]byte, error) {
+	separator := []byte("---\n")
+	iBytes, err := yaml.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate template: %v", err)
+	}
+	templateYAML := append(separator[:], iBytes[:]...)
+	return templateYAML, nil
+}
@@ -0,0 +1,383 @@
+package templating
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// gatewayHTTPListener is the name of the listener on the gateway that serves insecure traffic
+	gatewayHTTPListener = "http"
+	// gatewayHTTPSListener is the name of the listener on the gateway that serves secure traffic
+	gatewayHTTPSListener = "https"
+)
+
+// Certificate is a cert-manager.io/v1 Certificate, only the fields that are templated are defined
+type Certificate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              CertificateSpec `json:"spec"`
+}
+
+// CertificateSpec is the spec of a cert-manager.io/v1 Certificate
+type CertificateSpec struct {
+	SecretName string               `json:"secretName"`
+	DNSNames   []string             `json:"dnsNames"`
+	IssuerRef  CertificateIssuerRef `json:"issuerRef"`
+}
+
+// CertificateIssuerRef is the issuer of a cert-manager.io/v1 Certificate
+type CertificateIssuerRef struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	Group string `json:"group"`
+}
+
+// GenerateHTTPRouteTemplate generates the Gateway API HTTPRoutes for a route that is attached to a gateway.
+// If insecure traffic is redirected, a second HTTPRoute is attached to the http listener of the gateway to redirect it to https.
+// If the route requests a certificate and a certificate issuer is configured, a cert-manager Certificate is generated for the route
+func GenerateHTTPRouteTemplate(
+	route lagoon.RouteV2,
+	gateway generator.GatewayRef,
+	lValues generator.BuildValues,
+) ([]gatewayv1.HTTPRoute, *Certificate, error) {
+	// the certificates of a gateway are configured on its listeners
+	if route.TLSSecret != "" {
+		return nil, nil, fmt.Errorf("route %s uses tlsSecret, this isn't supported by gateway api routes as the gateway listeners have the certificates", route.Domain)
+	}
+	// gateway api has no standard filters to restrict access, so refuse to make a route public that was meant to be restricted
+	if len(route.AllowCIDRs) > 0 || len(route.DenyCIDRs) > 0 || (route.BasicAuth != nil && !route.BasicAuth.Disabled) {
+		return nil, nil, fmt.Errorf("route %s uses allowCIDRs, denyCIDRs or basicAuth, these aren't supported by gateway api routes", route.Domain)
+	}
+
+	// truncate the route for use in labels and secretname
+	truncatedRouteDomain := routeInstanceName(&route)
+
+	objectMeta := metav1.ObjectMeta{
+		Name: route.IngressName,
+	}
+	objectMeta.Labels, objectMeta.Annotations = routeMetadata(route, lValues, truncatedRouteDomain)
+	if err := applyRouteMetadata(&objectMeta, route); err != nil {
+		return nil, nil, err
+	}
+
+	parentRef := func(sectionName string) gatewayv1.ParentReference {
+		ref := gatewayv1.ParentReference{
+			Name: gatewayv1.ObjectName(gateway.Name),
+		}
+		if gateway.Namespace != "" {
+			ref.Namespace = (*gatewayv1.Namespace)(&gateway.Namespace)
+		}
+		if sectionName != "" {
+			ref.SectionName = (*gatewayv1.SectionName)(helpers.StrPtr(sectionName))
+		}
+		return ref
+	}
+
+	hostnames := []gatewayv1.Hostname{gatewayv1.Hostname(route.Domain)}
+	for _, alternativeName := range route.AlternativeNames {
+		hostnames = append(hostnames, gatewayv1.Hostname(alternativeName))
+	}
+
+	// the response headers that the ingress template adds with nginx snippets
+	headers := []gatewayv1.HTTPHeader{}
+	if route.HSTSEnabled != nil && *route.HSTSEnabled {
+		headers = append(headers, gatewayv1.HTTPHeader{
+			Name:  "Strict-Transport-Security",
+			Value: routeHSTSHeader(route),
+		})
+	}
+	if lValues.EnvironmentType == "development" || route.Autogenerated {
+		headers = append(headers, gatewayv1.HTTPHeader{
+			Name:  "X-Robots-Tag",
+			Value: "noindex, nofollow",
+		})
+	}
+	// and any response headers of the route, sorted so the template is the same every time
+	headerNames := []string{}
+	for name := range route.ResponseHeaders {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		headers = append(headers, gatewayv1.HTTPHeader{
+			Name:  gatewayv1.HTTPHeaderName(name),
+			Value: route.ResponseHeaders[name],
+		})
+	}
+	// each rule gets its own copy of the filters, as some rules add more filters
+	filters := func() []gatewayv1.HTTPRouteFilter {
+		if len(headers) == 0 {
+			return nil
+		}
+		return []gatewayv1.HTTPRouteFilter{
+			{
+				Type: gatewayv1.HTTPRouteFilterResponseHeaderModifier,
+				ResponseHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+					Set: headers,
+				},
+			},
+		}
+	}
+	pathMatch := func(path string) []gatewayv1.HTTPRouteMatch {
+		return []gatewayv1.HTTPRouteMatch{
+			{
+				Path: &gatewayv1.HTTPPathMatch{
+					Type:  ptr.To(gatewayv1.PathMatchPathPrefix),
+					Value: helpers.StrPtr(path),
+				},
+			},
+		}
+	}
+
+	// the default path sends everything to the service of the route, and any path routes send their path to another service.
+	// gateway api matches the longest path first, so the order of the rules doesn't matter
+	paths := append([]lagoon.PathRoute{{ToService: route.LagoonService, Path: "/"}}, route.PathRoutes...)
+	rules := []gatewayv1.HTTPRouteRule{}
+	for _, pr := range paths {
+		backendService, port, err := routeServicePort(lValues, pr.ToService)
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't generate the httproute for %s: %v", route.Domain, err)
+		}
+		rule := gatewayv1.HTTPRouteRule{
+			Matches: pathMatch(pr.Path),
+			Filters: filters(),
+			BackendRefs: []gatewayv1.HTTPBackendRef{
+				{
+					BackendRef: gatewayv1.BackendRef{
+						BackendObjectReference: gatewayv1.BackendObjectReference{
+							Name: gatewayv1.ObjectName(backendService),
+							Port: ptr.To(gatewayv1.PortNumber(port)),
+						},
+					},
+				},
+			},
+		}
+		if pr.Rewrite != "" {
+			rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{
+				Type: gatewayv1.HTTPRouteFilterURLRewrite,
+				URLRewrite: &gatewayv1.HTTPURLRewriteFilter{
+					Path: &gatewayv1.HTTPPathModifier{
+						Type:               gatewayv1.PrefixMatchHTTPPathModifier,
+						ReplacePrefixMatch: helpers.StrPtr(pr.Rewrite),
+					},
+				},
+			})
+		}
+		rules = append(rules, rule)
+	}
+	if route.Canary != nil {
+		// split the requests for the default path between the service of the route and the canary
+		backendService, namespace, port, err := canaryBackend(route, lValues)
+		if err != nil {
+			return nil, nil, err
+		}
+		if namespace != "" {
+			// a backend in another namespace needs a ReferenceGrant in that namespace, which a build of this environment can't create
+			return nil, nil, fmt.Errorf("route %s has a canary in the %s environment, this isn't supported by gateway api routes", route.Domain, route.Canary.Environment)
+		}
+		canaryRef := gatewayv1.HTTPBackendRef{
+			BackendRef: gatewayv1.BackendRef{
+				BackendObjectReference: gatewayv1.BackendObjectReference{
+					Name: gatewayv1.ObjectName(backendService),
+					Port: ptr.To(gatewayv1.PortNumber(port)),
+				},
+				Weight: ptr.To(int32(route.Canary.Weight)),
+			},
+		}
+		rules[0].BackendRefs[0].BackendRef.Weight = ptr.To(int32(100 - route.Canary.Weight))
+		rules[0].BackendRefs = append(rules[0].BackendRefs, canaryRef)
+	}
+	for _, redirect := range route.Redirects {
+		path := redirect.Path
+		if path == "" {
+			// the whole route is redirected, so the redirect is the only rule of the route
+			path = "/"
+			rules = []gatewayv1.HTTPRouteRule{}
+		}
+		redirectFilter, err := httpRouteRedirectFilter(redirect)
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't generate the httproute for %s: %v", route.Domain, err)
+		}
+		rules = append(rules, gatewayv1.HTTPRouteRule{
+			Matches: pathMatch(path),
+			Filters: append(filters(), redirectFilter),
+		})
+	}
+
+	httpRoute := gatewayv1.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HTTPRoute",
+			APIVersion: gatewayv1.GroupVersion.String(),
+		},
+		ObjectMeta: objectMeta,
+	}
+	httpRoute.Spec.Hostnames = hostnames
+	httpRoute.Spec.Rules = rules
+	httpRoutes := []gatewayv1.HTTPRoute{}
+	if *route.Insecure == "Allow" {
+		// serve the route on every listener of the gateway
+		httpRoute.Spec.ParentRefs = []gatewayv1.ParentReference{parentRef("")}
+		httpRoutes = append(httpRoutes, httpRoute)
+	} else {
+		// serve the route on the https listener, and redirect the http listener to https
+		httpRoute.Spec.ParentRefs = []gatewayv1.ParentReference{parentRef(gatewayHTTPSListener)}
+		redirect := gatewayv1.HTTPRoute{
+			TypeMeta: httpRoute.TypeMeta,
+		}
+		httpRoute.ObjectMeta.DeepCopyInto(&redirect.ObjectMeta)
+		redirect.ObjectMeta.Name = redirectRouteName(route)
+		redirect.Spec.ParentRefs = []gatewayv1.ParentReference{parentRef(gatewayHTTPListener)}
+		redirect.Spec.Hostnames = hostnames
+		redirect.Spec.Rules = []gatewayv1.HTTPRouteRule{
+			{
+				Filters: []gatewayv1.HTTPRouteFilter{
+					{
+						Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+						RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+							Scheme:     helpers.StrPtr("https"),
+							StatusCode: helpers.IntPtr(301),
+						},
+					},
+				},
+			},
+		}
+		httpRoutes = append(httpRoutes, httpRoute, redirect)
+	}
+
+	if route.Canary != nil {
+		// the canary label is how the route is found to change the weight of the canary
+		labels := map[string]string{
+			"lagoon.sh/canary": "true",
+		}
+		for key, value := range httpRoutes[0].ObjectMeta.Labels {
+			labels[key] = value
+		}
+		httpRoutes[0].ObjectMeta.Labels = labels
+	}
+
+	// the gateway terminates tls, so the certificate is requested directly from cert-manager if there is an issuer to use.
+	// an issuer of the route is used instead of the issuer of the gateway
+	issuer := lValues.GatewayCertificateIssuer
+	if route.Issuer != "" {
+		issuer = &generator.CertificateIssuer{Kind: "Issuer", Name: route.Issuer}
+	}
+	if route.ClusterIssuer != "" {
+		issuer = &generator.CertificateIssuer{Kind: "ClusterIssuer", Name: route.ClusterIssuer}
+	}
+	if !*route.TLSAcme || issuer == nil {
+		return httpRoutes, nil, nil
+	}
+	secretName, dnsNames := routeTLS(route, lValues, truncatedRouteDomain)
+	certificate := &Certificate{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Certificate",
+			APIVersion: "cert-manager.io/v1",
+		},
+		Spec: CertificateSpec{
+			SecretName: secretName,
+			DNSNames:   dnsNames,
+			IssuerRef: CertificateIssuerRef{
+				Name:  issuer.Name,
+				Kind:  issuer.Kind,
+				Group: "cert-manager.io",
+			},
+		},
+	}
+	// the certificate only needs the labels of the route, the annotations are for the controllers that watch routes
+	certificate.ObjectMeta = metav1.ObjectMeta{
+		Name:   secretName,
+		Labels: map[string]string{},
+		Annotations: map[string]string{
+			"lagoon.sh/version": lValues.LagoonVersion,
+		},
+	}
+	for key, value := range httpRoute.ObjectMeta.Labels {
+		certificate.ObjectMeta.Labels[key] = value
+	}
+	return httpRoutes, certificate, nil
+}
+
+// httpRouteRedirectFilter returns the filter that performs a redirect
+func httpRouteRedirectFilter(redirect lagoon.Redirect) (gatewayv1.HTTPRouteFilter, error) {
+	requestRedirect := &gatewayv1.HTTPRequestRedirectFilter{
+		StatusCode: helpers.IntPtr(302),
+	}
+	if redirect.Permanent {
+		requestRedirect.StatusCode = helpers.IntPtr(301)
+	}
+	path := redirect.To
+	if !strings.HasPrefix(redirect.To, "/") {
+		target, err := url.Parse(redirect.To)
+		if err != nil {
+			return gatewayv1.HTTPRouteFilter{}, fmt.Errorf("redirect to %s is not valid: %v", redirect.To, err)
+		}
+		requestRedirect.Scheme = helpers.StrPtr(target.Scheme)
+		requestRedirect.Hostname = (*gatewayv1.PreciseHostname)(helpers.StrPtr(target.Hostname()))
+		if target.Port() != "" {
+			port, err := strconv.Atoi(target.Port())
+			if err != nil {
+				return gatewayv1.HTTPRouteFilter{}, fmt.Errorf("redirect to %s is not valid: %v", redirect.To, err)
+			}
+			requestRedirect.Port = ptr.To(gatewayv1.PortNumber(port))
+		}
+		path = target.Path
+		if path == "" {
+			path = "/"
+		}
+	}
+	if redirect.PreservePath {
+		// the path of the request is added to the path of the url
+		if path != "/" {
+			requestRedirect.Path = &gatewayv1.HTTPPathModifier{
+				Type:               gatewayv1.PrefixMatchHTTPPathModifier,
+				ReplacePrefixMatch: helpers.StrPtr(path),
+			}
+		}
+	} else {
+		requestRedirect.Path = &gatewayv1.HTTPPathModifier{
+			Type:            gatewayv1.FullPathHTTPPathModifier,
+			ReplaceFullPath: helpers.StrPtr(path),
+		}
+	}
+	return gatewayv1.HTTPRouteFilter{
+		Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+		RequestRedirect: requestRedirect,
+	}, nil
+}
+
+// redirectRouteName returns the name of the HTTPRoute that redirects insecure traffic for a route
+func redirectRouteName(route lagoon.RouteV2) string {
+	return routeObjectName(route.IngressName, "redirect")
+}
+
+func TemplateHTTPRoute(httpRoutes []gatewayv1.HTTPRoute, certificate *Certificate) ([]byte, error) {
+	separator := []byte("---\n")
+	var templateYAML []byte
+	if certificate != nil {
+		cBytes, err := yaml.Marshal(certificate)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't generate template: %v", err)
+		}
+		templateYAML = append(templateYAML, append(separator[:], cBytes[:]...)...)
+	}
+	for _, httpRoute := range httpRoutes {
+		iBytes, err := yaml.Marshal(httpRoute)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't generate template: %v", err)
+		}
+		templateYAML = append(templateYAML, append(separator[:], iBytes[:]...)...)
+	}
+	return templateYAML, nil
+}
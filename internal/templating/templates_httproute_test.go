@@ -0,0 +1,231 @@
+package templating
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+)
+
+func TestGenerateHTTPRouteTemplate(t *testing.T) {
+	type args struct {
+		route   lagoon.RouteV2
+		gateway generator.GatewayRef
+		values  generator.BuildValues
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "autogenerated1 insecure allow with certificate",
+			args: args{
+				route: lagoon.RouteV2{
+					Domain:        "nginx-example-project-environment-with-really-really-reall-3fdb.lagoon.local",
+					LagoonService: "nginx",
+					Insecure:      helpers.StrPtr("Allow"),
+					TLSAcme:       helpers.BoolPtr(true),
+					Autogenerated: true,
+					Labels: map[string]string{
+						"lagoon.sh/autogenerated":    "true",
+						"app.kubernetes.io/name":     "autogenerated-ingress",
+						"app.kubernetes.io/instance": "nginx",
+						"lagoon.sh/service":          "nginx",
+						"lagoon.sh/service-type":     "nginx-php",
+						"lagoon.sh/template":         "autogenerated-ingress-0.1.0",
+					},
+					IngressName: "nginx",
+				},
+				gateway: generator.GatewayRef{
+					Name: "public",
+				},
+				values: generator.BuildValues{
+					Project:         "example-project",
+					Environment:     "environment-with-really-really-reall-3fdb",
+					EnvironmentType: "development",
+					BuildType:       "branch",
+					LagoonVersion:   "v2.x.x",
+					Branch:          "environment-with-really-really-reall-3fdb",
+					Services: []generator.ServiceValues{
+						{
+							Name:                          "nginx",
+							OverrideName:                  "nginx",
+							Type:                          "nginx-php",
+							ShortAutogeneratedRouteDomain: "nginx.abcdefgh.lagoon.local",
+						},
+					},
+					GatewayCertificateIssuer: &generator.CertificateIssuer{
+						Kind: "Issuer",
+						Name: "letsencrypt",
+					},
+				},
+			},
+			want: "test-resources/httproute/result-autogenerated1.yaml",
+		},
+		{
+			name: "wildcard1 no certificate",
+			args: args{
+				route: lagoon.RouteV2{
+					Domain:        "example.com",
+					LagoonService: "nginx",
+					Insecure:      helpers.StrPtr("Redirect"),
+					TLSAcme:       helpers.BoolPtr(false),
+					Wildcard:      helpers.BoolPtr(true),
+					HSTSEnabled:   helpers.BoolPtr(true),
+					HSTSMaxAge:    31536000,
+					IngressName:   "wildcard-example.com",
+				},
+				gateway: generator.GatewayRef{
+					Name:      "public",
+					Namespace: "lagoon-gateway",
+				},
+				values: generator.BuildValues{
+					Project:         "example-project",
+					Environment:     "main",
+					EnvironmentType: "production",
+					BuildType:       "branch",
+					LagoonVersion:   "v2.x.x",
+					Branch:          "main",
+					Services: []generator.ServiceValues{
+						{
+							Name:         "nginx",
+							OverrideName: "nginx",
+							Type:         "nginx-php",
+						},
+					},
+					GatewayCertificateIssuer: &generator.CertificateIssuer{
+						Kind: "ClusterIssuer",
+						Name: "letsencrypt",
+					},
+				},
+			},
+			want: "test-resources/httproute/result-wildcard1.yaml",
+		},
+		{
+			name: "wildcard2 certificate from the cluster issuer of the route",
+			args: args{
+				route: lagoon.RouteV2{
+					Domain:        "example.com",
+					LagoonService: "nginx",
+					Insecure:      helpers.StrPtr("Redirect"),
+					TLSAcme:       helpers.BoolPtr(true),
+					Wildcard:      helpers.BoolPtr(true),
+					ClusterIssuer: "letsencrypt-dns01",
+					IngressName:   "wildcard-example.com",
+				},
+				gateway: generator.GatewayRef{
+					Name:      "public",
+					Namespace: "lagoon-gateway",
+				},
+				values: generator.BuildValues{
+					Project:         "example-project",
+					Environment:     "main",
+					EnvironmentType: "production",
+					BuildType:       "branch",
+					LagoonVersion:   "v2.x.x",
+					Branch:          "main",
+					Services: []generator.ServiceValues{
+						{
+							Name:         "nginx",
+							OverrideName: "nginx",
+							Type:         "nginx-php",
+						},
+					},
+					GatewayCertificateIssuer: &generator.CertificateIssuer{
+						Kind: "Issuer",
+						Name: "letsencrypt",
+					},
+				},
+			},
+			want: "test-resources/httproute/result-wildcard2.yaml",
+		},
+		{
+			name: "tls secret",
+			args: args{
+				route: lagoon.RouteV2{
+					Domain:        "example.com",
+					LagoonService: "nginx",
+					Insecure:      helpers.StrPtr("Redirect"),
+					TLSAcme:       helpers.BoolPtr(false),
+					TLSSecret:     "example-com-ev",
+					IngressName:   "example.com",
+				},
+				gateway: generator.GatewayRef{
+					Name: "public",
+				},
+				values: generator.BuildValues{
+					Project:         "example-project",
+					Environment:     "main",
+					EnvironmentType: "production",
+					BuildType:       "branch",
+					Services: []generator.ServiceValues{
+						{
+							Name:         "nginx",
+							OverrideName: "nginx",
+							Type:         "nginx-php",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing service",
+			args: args{
+				route: lagoon.RouteV2{
+					Domain:        "example.com",
+					LagoonService: "varnish",
+					Insecure:      helpers.StrPtr("Redirect"),
+					TLSAcme:       helpers.BoolPtr(true),
+					IngressName:   "example.com",
+				},
+				gateway: generator.GatewayRef{
+					Name: "public",
+				},
+				values: generator.BuildValues{
+					Project:         "example-project",
+					Environment:     "main",
+					EnvironmentType: "production",
+					BuildType:       "branch",
+					Services: []generator.ServiceValues{
+						{
+							Name:         "nginx",
+							OverrideName: "nginx",
+							Type:         "nginx-php",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, certificate, err := GenerateHTTPRouteTemplate(tt.args.route, tt.args.gateway, tt.args.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateHTTPRouteTemplate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			r1, err := os.ReadFile(tt.want)
+			if err != nil {
+				t.Errorf("couldn't read file %v: %v", tt.want, err)
+			}
+			gotR, err := TemplateHTTPRoute(got, certificate)
+			if err != nil {
+				t.Errorf("couldn't generate template  %v", err)
+			}
+			if !reflect.DeepEqual(string(gotR), string(r1)) {
+				t.Errorf("GenerateHTTPRouteTemplate() = \n%v", diff.LineDiff(string(r1), string(gotR)))
+			}
+		})
+	}
+}
@@ -2,18 +2,18 @@ package templating
 
 import (
 	"fmt"
-	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/uselagoon/build-deploy-tool/internal/generator"
 	"github.com/uselagoon/build-deploy-tool/internal/helpers"
 	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	corev1 "k8s.io/api/core/v1"
 	networkv1 "k8s.io/api/networking/v1"
-	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
-	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/yaml"
 )
 
@@ -24,14 +24,7 @@ func GenerateIngressTemplate(
 ) (*networkv1.Ingress, error) {
 
 	// truncate the route for use in labels and secretname
-	truncatedRouteDomain := route.Domain
-	if len(truncatedRouteDomain) >= 53 {
-		subdomain := strings.Split(truncatedRouteDomain, ".")[0]
-		if errs := utilvalidation.IsValidLabelValue(subdomain); errs != nil {
-			subdomain = subdomain[:53]
-		}
-		truncatedRouteDomain = fmt.Sprintf("%s-%s", strings.Split(subdomain, ".")[0], helpers.GetMD5HashWithNewLine(route.Domain)[:5])
-	}
+	truncatedRouteDomain := routeInstanceName(&route)
 
 	// create the ingress object for templating
 	ingress := &networkv1.Ingress{}
@@ -41,86 +34,11 @@ func GenerateIngressTemplate(
 	}
 	ingress.ObjectMeta.Name = route.IngressName
 
-	// if this is a wildcard ingress, handle templating that here
-	if route.Wildcard != nil && *route.Wildcard {
-		truncatedRouteDomain = fmt.Sprintf("wildcard-%s", truncatedRouteDomain)
-		if len(truncatedRouteDomain) >= 53 {
-			subdomain := strings.Split(truncatedRouteDomain, "-")[0]
-			if errs := utilvalidation.IsValidLabelValue(subdomain); errs != nil {
-				subdomain = subdomain[:53]
-			}
-			truncatedRouteDomain = fmt.Sprintf("%s-%s", strings.Split(subdomain, "-")[0], helpers.GetMD5HashWithNewLine(route.Domain)[:5])
-		}
-		// set the domain to include the wildcard prefix
-		route.Domain = fmt.Sprintf("*.%s", route.Domain)
-	}
-
-	// add the default labels
-	ingress.ObjectMeta.Labels = map[string]string{
-		"lagoon.sh/autogenerated":      "false",
-		"app.kubernetes.io/name":       "custom-ingress",
-		"app.kubernetes.io/instance":   truncatedRouteDomain,
-		"app.kubernetes.io/managed-by": "build-deploy-tool",
-		"lagoon.sh/template":           "custom-ingress-0.1.0",
-		"lagoon.sh/service":            truncatedRouteDomain,
-		"lagoon.sh/service-type":       "custom-ingress",
-		"lagoon.sh/project":            lValues.Project,
-		"lagoon.sh/environment":        lValues.Environment,
-		"lagoon.sh/environmentType":    lValues.EnvironmentType,
-		"lagoon.sh/buildType":          lValues.BuildType,
-	}
-	additionalLabels := map[string]string{}
-
-	// add the default annotations
-	ingress.ObjectMeta.Annotations = map[string]string{
-		"kubernetes.io/tls-acme": strconv.FormatBool(*route.TLSAcme),
-		"fastly.amazee.io/watch": strconv.FormatBool(route.Fastly.Watch),
-		"lagoon.sh/version":      lValues.LagoonVersion,
-	}
+	// add the default labels and annotations
+	ingress.ObjectMeta.Labels, ingress.ObjectMeta.Annotations = routeMetadata(route, lValues, truncatedRouteDomain)
+	ingress.ObjectMeta.Annotations["kubernetes.io/tls-acme"] = strconv.FormatBool(*route.TLSAcme)
 	additionalAnnotations := map[string]string{}
 
-	if lValues.EnvironmentType == "production" && !route.Autogenerated {
-		if route.Migrate != nil {
-			additionalLabels["activestandby.lagoon.sh/migrate"] = strconv.FormatBool(*route.Migrate)
-		} else {
-			additionalLabels["activestandby.lagoon.sh/migrate"] = "false"
-		}
-	}
-	if lValues.EnvironmentType == "production" {
-		// monitoring is only available in production environments
-		additionalAnnotations["monitor.stakater.com/enabled"] = "false"
-		primaryIngress, _ := url.Parse(lValues.Route)
-		// check if monitoring enabled, route isn't autogenerated, and the primary ingress from the .lagoon.yml is this processed routedomain
-		// and enable monitoring on the primary ingress only.
-		if lValues.Monitoring.Enabled && !route.Autogenerated && primaryIngress.Host == route.Domain {
-			additionalLabels["lagoon.sh/primaryIngress"] = "true"
-
-			// only add the monitring annotations if monitoring is enabled
-			additionalAnnotations["monitor.stakater.com/enabled"] = "true"
-			additionalAnnotations["uptimerobot.monitor.stakater.com/alert-contacts"] = "unconfigured"
-			if lValues.Monitoring.AlertContact != "" {
-				additionalAnnotations["uptimerobot.monitor.stakater.com/alert-contacts"] = lValues.Monitoring.AlertContact
-			}
-			if lValues.Monitoring.StatusPageID != "" {
-				additionalAnnotations["uptimerobot.monitor.stakater.com/status-pages"] = lValues.Monitoring.StatusPageID
-			}
-			additionalAnnotations["uptimerobot.monitor.stakater.com/interval"] = "60"
-		}
-		if route.MonitoringPath != "" {
-			additionalAnnotations["monitor.stakater.com/overridePath"] = route.MonitoringPath
-		}
-	}
-	if route.Fastly.ServiceID != "" {
-		additionalAnnotations["fastly.amazee.io/service-id"] = route.Fastly.ServiceID
-	}
-	if lValues.BuildType == "branch" {
-		additionalAnnotations["lagoon.sh/branch"] = lValues.Branch
-	} else if lValues.BuildType == "pullrequest" {
-		additionalAnnotations["lagoon.sh/prNumber"] = lValues.PRNumber
-		additionalAnnotations["lagoon.sh/prHeadBranch"] = lValues.PRHeadBranch
-		additionalAnnotations["lagoon.sh/prBaseBranch"] = lValues.PRBaseBranch
-
-	}
 	if *route.Insecure == "Allow" {
 		additionalAnnotations["nginx.ingress.kubernetes.io/ssl-redirect"] = "false"
 		additionalAnnotations["ingress.kubernetes.io/ssl-redirect"] = "false"
@@ -128,32 +46,15 @@ func GenerateIngressTemplate(
 		additionalAnnotations["nginx.ingress.kubernetes.io/ssl-redirect"] = "true"
 		additionalAnnotations["ingress.kubernetes.io/ssl-redirect"] = "true"
 	}
+	// the x-robots-tag and hsts headers are still added with snippets, only the response headers of the route are in the
+	// custom headers configmap. moving these would need every controller to allow them in global-allowed-response-headers
 	if lValues.EnvironmentType == "development" || route.Autogenerated {
 		additionalAnnotations["nginx.ingress.kubernetes.io/server-snippet"] = "add_header X-Robots-Tag \"noindex, nofollow\";\n"
 	}
 
-	// if idling request verification is in the `.lagoon.yml` and true, add the annotation. this supports production and development environment types
-	// in the event that production environments support idling properly that option could be available to then
-	// idle standby environments or production environments generally in opensource lagoon
-	if route.RequestVerification != nil && *route.RequestVerification {
-		// @TODO: this will eventually be changed to a `lagoon.sh` instead of `amazee.io` namespaced annotation in the future once
-		// aergia is fully integrated into the uselagoon namespace
-		additionalAnnotations["idling.amazee.io/disable-request-verification"] = "true"
-	} else {
-		// otherwise force false
-		additionalAnnotations["idling.amazee.io/disable-request-verification"] = "false"
-	}
-
 	// check if a user has defined hsts configuration
 	if route.HSTSEnabled != nil && *route.HSTSEnabled {
-		hstsHeader := fmt.Sprintf("more_set_headers \"Strict-Transport-Security: max-age=%d", route.HSTSMaxAge)
-		if route.HSTSIncludeSubdomains != nil && *route.HSTSIncludeSubdomains {
-			hstsHeader = fmt.Sprintf("%s%s", hstsHeader, ";includeSubDomains")
-		}
-		if route.HSTSPreload != nil && *route.HSTSPreload {
-			hstsHeader = fmt.Sprintf("%s%s", hstsHeader, ";preload")
-		}
-		hstsHeader = fmt.Sprintf("%s\"", hstsHeader)
+		hstsHeader := fmt.Sprintf("more_set_headers \"Strict-Transport-Security: %s\"", routeHSTSHeader(route))
 		// if someone has already set a configuration-snippet annotation, then add the hsts header
 		// to the top of the existing annotation before it is added to the ingress object
 		if value, ok := route.Annotations["nginx.ingress.kubernetes.io/configuration-snippet"]; ok {
@@ -178,67 +79,59 @@ func GenerateIngressTemplate(
 		additionalAnnotations["acme.cert-manager.io/http01-ingress-class"] = route.IngressClass
 	}
 
-	// add any additional labels
-	for key, value := range additionalLabels {
-		ingress.ObjectMeta.Labels[key] = value
-	}
-	// add any additional annotations
-	for key, value := range additionalAnnotations {
-		ingress.ObjectMeta.Annotations[key] = value
+	// request the certificate from a specific cert-manager issuer
+	if route.Issuer != "" {
+		additionalAnnotations["cert-manager.io/issuer"] = route.Issuer
 	}
-	// add any annotations that the route had to overwrite any previous annotations
-	for key, value := range route.Annotations {
-		ingress.ObjectMeta.Annotations[key] = value
+	if route.ClusterIssuer != "" {
+		additionalAnnotations["cert-manager.io/cluster-issuer"] = route.ClusterIssuer
 	}
-	// add any labels that the route had to overwrite any previous labels
-	for key, value := range route.Labels {
-		ingress.ObjectMeta.Labels[key] = value
+
+	// response headers are added by ingress-nginx from a configmap, see GenerateRouteHeadersTemplate
+	if len(route.ResponseHeaders) > 0 {
+		additionalAnnotations["nginx.ingress.kubernetes.io/custom-headers"] = fmt.Sprintf("%s/%s", lValues.Namespace, routeHeadersName(truncatedRouteDomain))
 	}
-	// validate any annotations
-	if err := apivalidation.ValidateAnnotations(ingress.ObjectMeta.Annotations, nil); err != nil {
-		if len(err) != 0 {
-			return nil, fmt.Errorf("the annotations for %s are not valid: %v", route.Domain, err)
+	// redirect the whole route if requested
+	for _, redirect := range route.Redirects {
+		if redirect.Path == "" {
+			key, value := ingressRedirectAnnotation(redirect)
+			additionalAnnotations[key] = value
 		}
 	}
-	// validate any labels
-	if err := metavalidation.ValidateLabels(ingress.ObjectMeta.Labels, nil); err != nil {
-		if len(err) != 0 {
-			return nil, fmt.Errorf("the labels for %s are not valid: %v", route.Domain, err)
+
+	// restrict who can access the route
+	if len(route.AllowCIDRs) > 0 {
+		additionalAnnotations["nginx.ingress.kubernetes.io/whitelist-source-range"] = strings.Join(route.AllowCIDRs, ",")
+	}
+	if len(route.DenyCIDRs) > 0 {
+		additionalAnnotations["nginx.ingress.kubernetes.io/denylist-source-range"] = strings.Join(route.DenyCIDRs, ",")
+	}
+	if route.BasicAuth != nil && !route.BasicAuth.Disabled {
+		additionalAnnotations["nginx.ingress.kubernetes.io/auth-type"] = "basic"
+		additionalAnnotations["nginx.ingress.kubernetes.io/auth-secret"] = route.BasicAuth.Secret
+		realm := route.BasicAuth.Realm
+		if realm == "" {
+			realm = "Authentication Required"
 		}
+		additionalAnnotations["nginx.ingress.kubernetes.io/auth-realm"] = realm
 	}
 
-	// set up the secretname for tls
-	if route.Autogenerated {
-		// autogenerated use the service name
-		ingress.Spec.TLS = []networkv1.IngressTLS{
-			{
-				SecretName: fmt.Sprintf("%s-tls", route.LagoonService),
-			},
-		}
-	} else {
-		// everything else uses the truncated
-		ingress.Spec.TLS = []networkv1.IngressTLS{
-			{
-				// use the truncated route domain here as we add `-tls`
-				// if a domain that is 253 chars long is used this will then exceed
-				// the 253 char limit on kubernetes names
-				SecretName: fmt.Sprintf("%s-tls", truncatedRouteDomain),
-			},
-		}
+	// add any additional annotations
+	for key, value := range additionalAnnotations {
+		ingress.ObjectMeta.Annotations[key] = value
+	}
+	if err := applyRouteMetadata(&ingress.ObjectMeta, route); err != nil {
+		return nil, err
 	}
 
-	// autogenerated domains that are too long break when creating the acme challenge k8s resource
-	// this injects a shorter domain into the tls spec that is used in the k8s challenge
-	// use the compose service name to check this, as this is how Services are populated from the compose generation
-	for _, service := range lValues.Services {
-		if service.OverrideName == route.LagoonService {
-			if service.ShortAutogeneratedRouteDomain != "" && len(route.Domain) > 63 {
-				ingress.Spec.TLS[0].Hosts = append(ingress.Spec.TLS[0].Hosts, service.ShortAutogeneratedRouteDomain)
-			}
-		}
+	// set up the secretname and hosts for tls
+	secretName, hosts := routeTLS(route, lValues, truncatedRouteDomain)
+	ingress.Spec.TLS = []networkv1.IngressTLS{
+		{
+			SecretName: secretName,
+			Hosts:      hosts,
+		},
 	}
-	// add the main domain to the tls spec now
-	ingress.Spec.TLS[0].Hosts = append(ingress.Spec.TLS[0].Hosts, route.Domain)
 
 	// default service port is http in all lagoon deployments
 	// this should be the port that usually would be accessible via an ingress if the service would normally
@@ -283,41 +176,15 @@ func GenerateIngressTemplate(
 
 	// check for any path based routes defined against this ingress
 	for _, pr := range route.PathRoutes {
-		// default path routes to the http named backend
-		pathPort := networkv1.ServiceBackendPort{
-			Name: "http",
-		}
-		backendServiceName := pr.ToService
-		// if a port override service name has been provided because 'lagoon.service.usecomposeports' is defined against a service
-		// look it up the provided service against the computed additional ports
-		// and extract that ports backend name to use
-		for _, service := range lValues.Services {
-			// if the toService is the default service name, not a port specific override but additionalserviceports is more than 0
-			// then this is the "default" service that is being references
-			if pr.ToService == service.OverrideName && len(service.AdditionalServicePorts) > 0 {
-				// extract the first port from the additional ports to use as the path port
-				// as the first port in the list is the "default" port
-				pathPort = GenerateServiceBackendPort(service.AdditionalServicePorts[0])
-			}
-			// otherwise if the user has specified a specific 'servicename-port' in their toService
-			// look that up instead and serve the backend as requested
-			for _, addPort := range service.AdditionalServicePorts {
-				if addPort.ServiceName == pr.ToService {
-					pathPort = GenerateServiceBackendPort(addPort)
-					backendServiceName = addPort.ServiceOverrideName
-				}
-			}
+		if pr.Rewrite != "" {
+			// path routes that rewrite the path are in their own ingress, see GenerateIngressPathTemplates
+			continue
 		}
 		// append the ingress paths with the computed details
 		paths = append(paths, networkv1.HTTPIngressPath{
 			Path:     pr.Path,
 			PathType: &pt,
-			Backend: networkv1.IngressBackend{
-				Service: &networkv1.IngressServiceBackend{
-					Name: backendServiceName,
-					Port: pathPort,
-				},
-			},
+			Backend:  ingressPathBackend(lValues, pr.ToService),
 		})
 	}
 	// add the main domain as the first rule in the spec
@@ -333,7 +200,6 @@ func GenerateIngressTemplate(
 	}
 	// check if any alternative names were provided and add them to the spec
 	for _, alternativeName := range route.AlternativeNames {
-		ingress.Spec.TLS[0].Hosts = append(ingress.Spec.TLS[0].Hosts, alternativeName)
 		altName := networkv1.IngressRule{
 			Host: alternativeName,
 			IngressRuleValue: networkv1.IngressRuleValue{
@@ -358,6 +224,277 @@ func GenerateIngressTemplate(
 	return ingress, nil
 }
 
+// ingressPathBackend returns the backend for a path route to the provided service
+func ingressPathBackend(lValues generator.BuildValues, toService string) networkv1.IngressBackend {
+	// default path routes to the http named backend
+	pathPort := networkv1.ServiceBackendPort{
+		Name: "http",
+	}
+	backendServiceName := toService
+	// if a port override service name has been provided because 'lagoon.service.usecomposeports' is defined against a service
+	// look it up the provided service against the computed additional ports
+	// and extract that ports backend name to use
+	for _, service := range lValues.Services {
+		// if the toService is the default service name, not a port specific override but additionalserviceports is more than 0
+		// then this is the "default" service that is being references
+		if toService == service.OverrideName && len(service.AdditionalServicePorts) > 0 {
+			// extract the first port from the additional ports to use as the path port
+			// as the first port in the list is the "default" port
+			pathPort = GenerateServiceBackendPort(service.AdditionalServicePorts[0])
+		}
+		// otherwise if the user has specified a specific 'servicename-port' in their toService
+		// look that up instead and serve the backend as requested
+		for _, addPort := range service.AdditionalServicePorts {
+			if addPort.ServiceName == toService {
+				pathPort = GenerateServiceBackendPort(addPort)
+				backendServiceName = addPort.ServiceOverrideName
+			}
+		}
+	}
+	return networkv1.IngressBackend{
+		Service: &networkv1.IngressServiceBackend{
+			Name: backendServiceName,
+			Port: pathPort,
+		},
+	}
+}
+
+// ingressRedirectAnnotation returns the ingress-nginx annotation that performs a redirect
+func ingressRedirectAnnotation(redirect lagoon.Redirect) (string, string) {
+	to := redirect.To
+	if redirect.PreservePath {
+		to = fmt.Sprintf("%s$request_uri", strings.TrimSuffix(to, "/"))
+	}
+	if redirect.Permanent {
+		return "nginx.ingress.kubernetes.io/permanent-redirect", to
+	}
+	return "nginx.ingress.kubernetes.io/temporal-redirect", to
+}
+
+// derivedIngress returns a copy of the main ingress of a route, for an additional ingress that shares its hosts.
+// The certificate, monitoring and fastly are handled by the main ingress, so those annotations are removed from the copy.
+// The tls-acme annotation is removed rather than set to false, as the build removes the certificate in the tls secret
+// of any ingress with tls-acme set to false, and the secret is the one the main ingress uses
+func derivedIngress(ingress *networkv1.Ingress, name string) *networkv1.Ingress {
+	derived := &networkv1.Ingress{
+		TypeMeta: ingress.TypeMeta,
+	}
+	ingress.ObjectMeta.DeepCopyInto(&derived.ObjectMeta)
+	ingress.Spec.DeepCopyInto(&derived.Spec)
+	derived.ObjectMeta.Name = name
+	delete(derived.ObjectMeta.Annotations, "kubernetes.io/tls-acme")
+	for key := range derived.ObjectMeta.Annotations {
+		if strings.HasPrefix(key, "cert-manager.io/") || strings.HasPrefix(key, "acme.cert-manager.io/") {
+			delete(derived.ObjectMeta.Annotations, key)
+		}
+	}
+	if _, ok := derived.ObjectMeta.Annotations["monitor.stakater.com/enabled"]; ok {
+		derived.ObjectMeta.Annotations["monitor.stakater.com/enabled"] = "false"
+	}
+	derived.ObjectMeta.Annotations["fastly.amazee.io/watch"] = "false"
+	delete(derived.ObjectMeta.Annotations, "fastly.amazee.io/service-id")
+	delete(derived.ObjectMeta.Labels, "lagoon.sh/primaryIngress")
+	delete(derived.ObjectMeta.Annotations, "nginx.ingress.kubernetes.io/permanent-redirect")
+	delete(derived.ObjectMeta.Annotations, "nginx.ingress.kubernetes.io/temporal-redirect")
+	return derived
+}
+
+// GenerateIngressPathTemplates generates the additional ingresses that a route needs for the paths that are redirected or rewritten.
+// ingress-nginx applies redirect and rewrite annotations to every path of an ingress, so each of these paths is in its own ingress
+// for the main domain of the route. These ingresses share the tls secret of the main ingress, but don't request or remove a certificate
+func GenerateIngressPathTemplates(
+	route lagoon.RouteV2,
+	lValues generator.BuildValues,
+	ingress *networkv1.Ingress,
+) ([]*networkv1.Ingress, error) {
+	pathIngresses := []*networkv1.Ingress{}
+	if len(ingress.Spec.Rules) == 0 || ingress.Spec.Rules[0].HTTP == nil {
+		return pathIngresses, nil
+	}
+	mainRule := ingress.Spec.Rules[0]
+	newPathIngress := func(path string, pathType networkv1.PathType, backend networkv1.IngressBackend) *networkv1.Ingress {
+		pathIngress := derivedIngress(ingress, routeObjectName(ingress.ObjectMeta.Name, fmt.Sprintf("path-%s", helpers.GetMD5HashWithNewLine(path)[:8])))
+		pathIngress.Spec.Rules = []networkv1.IngressRule{
+			{
+				Host: mainRule.Host,
+				IngressRuleValue: networkv1.IngressRuleValue{
+					HTTP: &networkv1.HTTPIngressRuleValue{
+						Paths: []networkv1.HTTPIngressPath{
+							{
+								Path:     path,
+								PathType: &pathType,
+								Backend:  backend,
+							},
+						},
+					},
+				},
+			},
+		}
+		return pathIngress
+	}
+
+	for _, redirect := range route.Redirects {
+		if redirect.Path == "" {
+			continue
+		}
+		// the redirect annotation doesn't use the backend, but an ingress needs one
+		if strings.HasPrefix(redirect.To, "/") {
+			// ingress-nginx only redirects to urls, so a path on the route is redirected to on the domain of the route
+			if strings.HasPrefix(mainRule.Host, "*.") {
+				return nil, fmt.Errorf("redirect of path %s on %s must be to a url, wildcard routes can't redirect to a path", redirect.Path, route.Domain)
+			}
+			redirect.To = fmt.Sprintf("https://%s%s", mainRule.Host, redirect.To)
+		}
+		pathIngress := newPathIngress(redirect.Path, networkv1.PathTypePrefix, mainRule.HTTP.Paths[0].Backend)
+		key, value := ingressRedirectAnnotation(redirect)
+		pathIngress.ObjectMeta.Annotations[key] = value
+		pathIngresses = append(pathIngresses, pathIngress)
+	}
+	for _, pr := range route.PathRoutes {
+		if pr.Rewrite == "" {
+			continue
+		}
+		// capture the rest of the path after the prefix so it can be added to the rewritten path
+		path := fmt.Sprintf("%s(/|$)(.*)", regexp.QuoteMeta(strings.TrimSuffix(pr.Path, "/")))
+		pathIngress := newPathIngress(path, networkv1.PathTypeImplementationSpecific, ingressPathBackend(lValues, pr.ToService))
+		pathIngress.ObjectMeta.Annotations["nginx.ingress.kubernetes.io/use-regex"] = "true"
+		pathIngress.ObjectMeta.Annotations["nginx.ingress.kubernetes.io/rewrite-target"] = fmt.Sprintf("%s/$2", strings.TrimSuffix(pr.Rewrite, "/"))
+		pathIngresses = append(pathIngresses, pathIngress)
+	}
+	return pathIngresses, nil
+}
+
+// GenerateIngressCanaryTemplate generates the ingress-nginx canary ingress that sends the weight of the canary of a route
+// to the canary service. Only the requests that would be sent to the service of the route are split, path routes are not.
+// If the canary is in another environment, an ExternalName service for the service in that environment is generated
+// for the canary ingress to use. If the route doesn't have a canary, nil is returned
+func GenerateIngressCanaryTemplate(
+	route lagoon.RouteV2,
+	lValues generator.BuildValues,
+	ingress *networkv1.Ingress,
+) (*networkv1.Ingress, *corev1.Service, error) {
+	if route.Canary == nil {
+		return nil, nil, nil
+	}
+	canaryIngress := derivedIngress(ingress, routeObjectName(ingress.ObjectMeta.Name, "canary"))
+	canaryIngress.ObjectMeta.Labels["lagoon.sh/canary"] = "true"
+	canaryIngress.ObjectMeta.Annotations["nginx.ingress.kubernetes.io/canary"] = "true"
+	canaryIngress.ObjectMeta.Annotations["nginx.ingress.kubernetes.io/canary-weight"] = strconv.Itoa(route.Canary.Weight)
+
+	backendService, namespace, port, err := canaryBackend(route, lValues)
+	if err != nil {
+		return nil, nil, err
+	}
+	canaryService := route.Canary.Service
+	if canaryService == "" {
+		canaryService = route.LagoonService
+	}
+	backend := ingressPathBackend(lValues, canaryService)
+	var externalService *corev1.Service
+	if namespace != "" {
+		// an ingress can only use services in its own namespace
+		externalService = &corev1.Service{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Service",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("canary-%s", helpers.GetMD5HashWithNewLine(route.IngressName)[:8]),
+				Labels: map[string]string{},
+				Annotations: map[string]string{
+					"lagoon.sh/version": lValues.LagoonVersion,
+				},
+			},
+			Spec: corev1.ServiceSpec{
+				Type:         corev1.ServiceTypeExternalName,
+				ExternalName: fmt.Sprintf("%s.%s.svc.cluster.local", backendService, namespace),
+				Ports: []corev1.ServicePort{
+					{
+						Name:       "http",
+						Port:       port,
+						TargetPort: intstr.FromInt32(port),
+						Protocol:   corev1.ProtocolTCP,
+					},
+				},
+			},
+		}
+		for key, value := range canaryIngress.ObjectMeta.Labels {
+			externalService.ObjectMeta.Labels[key] = value
+		}
+		delete(externalService.ObjectMeta.Labels, "lagoon.sh/canary")
+		// the build finds the canary services it no longer generates with this label
+		externalService.ObjectMeta.Labels["lagoon.sh/canary-service"] = "true"
+		backend = networkv1.IngressBackend{
+			Service: &networkv1.IngressServiceBackend{
+				Name: externalService.ObjectMeta.Name,
+				Port: networkv1.ServiceBackendPort{
+					Number: port,
+				},
+			},
+		}
+	}
+
+	// only the default path of each host is split
+	pt := networkv1.PathTypePrefix
+	for idx := range canaryIngress.Spec.Rules {
+		canaryIngress.Spec.Rules[idx].HTTP = &networkv1.HTTPIngressRuleValue{
+			Paths: []networkv1.HTTPIngressPath{
+				{
+					Path:     "/",
+					PathType: &pt,
+					Backend:  backend,
+				},
+			},
+		}
+	}
+	return canaryIngress, externalService, nil
+}
+
+// GenerateRouteHeadersTemplate generates the configmap that ingress-nginx reads the response headers of a route from.
+// If the route doesn't have any response headers, nil is returned
+func GenerateRouteHeadersTemplate(
+	route lagoon.RouteV2,
+	lValues generator.BuildValues,
+) (*corev1.ConfigMap, error) {
+	if len(route.ResponseHeaders) == 0 {
+		return nil, nil
+	}
+	truncatedRouteDomain := routeInstanceName(&route)
+	configMap := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: routeHeadersName(truncatedRouteDomain),
+			Annotations: map[string]string{
+				"lagoon.sh/version": lValues.LagoonVersion,
+			},
+		},
+		Data: map[string]string{},
+	}
+	configMap.ObjectMeta.Labels, _ = routeMetadata(route, lValues, truncatedRouteDomain)
+	// monitoring is handled by the ingress
+	delete(configMap.ObjectMeta.Labels, "lagoon.sh/primaryIngress")
+	for key, value := range route.Labels {
+		configMap.ObjectMeta.Labels[key] = value
+	}
+	if err := metavalidation.ValidateLabels(configMap.ObjectMeta.Labels, nil); err != nil {
+		if len(err) != 0 {
+			return nil, fmt.Errorf("the labels for %s are not valid: %v", route.Domain, err)
+		}
+	}
+	for name, value := range route.ResponseHeaders {
+		configMap.Data[name] = value
+	}
+	return configMap, nil
+}
+
+// routeHeadersName returns the name of the configmap that holds the response headers of a route
+func routeHeadersName(truncatedRouteDomain string) string {
+	return fmt.Sprintf("%s-headers", truncatedRouteDomain)
+}
+
 func TemplateIngress(ingress *networkv1.Ingress) ([]byte, error) {
 	separator := []byte("---\n")
 	var templateYAML []byte
@@ -369,3 +506,15 @@ func TemplateIngress(ingress *networkv1.Ingress) ([]byte, error) {
 	templateYAML = append(templateYAML, restoreResult[:]...)
 	return templateYAML, nil
 }
+
+func TemplateConfigMap(configMap *corev1.ConfigMap) ([]byte, error) {
+	separator := []byte("---\n")
+	var templateYAML []byte
+	cBytes, err := yaml.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate template: %v", err)
+	}
+	restoreResult := append(separator[:], cBytes[:]...)
+	templateYAML = append(templateYAML, restoreResult[:]...)
+	return templateYAML, nil
+}
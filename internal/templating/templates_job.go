@@ -0,0 +1,196 @@
+package templating
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// GenerateJobTemplate generates the jobs for any services with the type job, these are run once before the deployments are rolled out
+func GenerateJobTemplate(
+	buildValues generator.BuildValues,
+) ([]batchv1.Job, error) {
+	var result []batchv1.Job
+
+	// check linked services
+	checkedServices := LinkedServiceCalculator(buildValues.Services)
+
+	for _, serviceValues := range checkedServices {
+		if serviceValues.Type != generator.JobServiceType || serviceValues.Job == nil {
+			continue
+		}
+		// add the default labels
+		labels := map[string]string{
+			"app.kubernetes.io/managed-by": "build-deploy-tool",
+			"app.kubernetes.io/name":       generator.JobServiceType,
+			"app.kubernetes.io/instance":   serviceValues.OverrideName,
+			"lagoon.sh/project":            buildValues.Project,
+			"lagoon.sh/environment":        buildValues.Environment,
+			"lagoon.sh/environmentType":    buildValues.EnvironmentType,
+			"lagoon.sh/buildType":          buildValues.BuildType,
+			"lagoon.sh/template":           fmt.Sprintf("%s-%s", generator.JobServiceType, "0.1.0"),
+			"lagoon.sh/service":            serviceValues.OverrideName,
+			"lagoon.sh/service-type":       generator.JobServiceType,
+		}
+
+		// add the default annotations
+		annotations := map[string]string{
+			"lagoon.sh/version": buildValues.LagoonVersion,
+		}
+		if buildValues.BuildType == "branch" {
+			annotations["lagoon.sh/branch"] = buildValues.Branch
+		} else if buildValues.BuildType == "pullrequest" {
+			annotations["lagoon.sh/prNumber"] = buildValues.PRNumber
+			annotations["lagoon.sh/prHeadBranch"] = buildValues.PRHeadBranch
+			annotations["lagoon.sh/prBaseBranch"] = buildValues.PRBaseBranch
+		}
+		// validate any annotations
+		if err := apivalidation.ValidateAnnotations(annotations, nil); err != nil {
+			if len(err) != 0 {
+				return nil, fmt.Errorf("the annotations for %s are not valid: %v", serviceValues.OverrideName, err)
+			}
+		}
+		// validate any labels
+		if err := metavalidation.ValidateLabels(labels, nil); err != nil {
+			if len(err) != 0 {
+				return nil, fmt.Errorf("the labels for %s are not valid: %v", serviceValues.OverrideName, err)
+			}
+		}
+		// check length of labels
+		if err := helpers.CheckLabelLength(labels); err != nil {
+			return nil, err
+		}
+
+		job := &batchv1.Job{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Job",
+				APIVersion: fmt.Sprintf("%s/%s", batchv1.SchemeGroupVersion.Group, batchv1.SchemeGroupVersion.Version),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        serviceValues.OverrideName,
+				Labels:      labels,
+				Annotations: annotations,
+			},
+		}
+		job.Spec.BackoffLimit = helpers.Int32Ptr(serviceValues.Job.BackoffLimit)
+		job.Spec.ActiveDeadlineSeconds = helpers.Int64Ptr(serviceValues.Job.ActiveDeadlineSeconds)
+
+		// start job pod template
+		job.Spec.Template.ObjectMeta = metav1.ObjectMeta{
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		}
+		for key, value := range labels {
+			job.Spec.Template.ObjectMeta.Labels[key] = value
+		}
+		for key, value := range annotations {
+			job.Spec.Template.ObjectMeta.Annotations[key] = value
+		}
+		job.Spec.Template.ObjectMeta.Annotations["lagoon.sh/configMapSha"] = buildValues.ConfigMapSha
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+		job.Spec.Template.Spec.EnableServiceLinks = helpers.BoolPtr(false)
+		job.Spec.Template.Spec.PriorityClassName = fmt.Sprintf("lagoon-priority-%s", buildValues.EnvironmentType)
+		if buildValues.PodSecurityContext.RunAsUser != 0 {
+			job.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{
+				RunAsUser:  helpers.Int64Ptr(buildValues.PodSecurityContext.RunAsUser),
+				RunAsGroup: helpers.Int64Ptr(buildValues.PodSecurityContext.RunAsGroup),
+				FSGroup:    helpers.Int64Ptr(buildValues.PodSecurityContext.FsGroup),
+			}
+		}
+
+		// handle any image pull secrets, add the default one first
+		pullsecrets := []corev1.LocalObjectReference{
+			{
+				Name: generator.DefaultImagePullSecret,
+			},
+		}
+		sort.Slice(buildValues.ContainerRegistry, func(i, j int) bool {
+			return buildValues.ContainerRegistry[i].Name < buildValues.ContainerRegistry[j].Name
+		})
+		for _, pullsecret := range buildValues.ContainerRegistry {
+			pullsecrets = append(pullsecrets, corev1.LocalObjectReference{
+				Name: pullsecret.SecretName,
+			})
+		}
+		job.Spec.Template.Spec.ImagePullSecrets = pullsecrets
+
+		container := corev1.Container{
+			Name:            serviceValues.Name,
+			ImagePullPolicy: corev1.PullAlways,
+			Command:         serviceValues.Command,
+		}
+		if val, ok := buildValues.ImageReferences[serviceValues.Name]; ok {
+			container.Image = val
+		} else {
+			return nil, fmt.Errorf("no image reference was found for job %s", serviceValues.Name)
+		}
+		container.Env = []corev1.EnvVar{
+			{
+				Name:  "LAGOON_GIT_SHA",
+				Value: buildValues.GitSHA,
+			},
+			{
+				Name:  "SERVICE_NAME",
+				Value: serviceValues.OverrideName,
+			},
+		}
+		container.EnvFrom = lagoonEnvFrom(buildValues)
+
+		// mount any dynamic secrets, and the persistent volume of another service if one is defined
+		for _, dsv := range buildValues.DynamicSecretVolumes {
+			job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+				Name: dsv.Name,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: dsv.Secret.SecretName,
+						Optional:   &dsv.Secret.Optional,
+					},
+				},
+			})
+		}
+		for _, dsm := range buildValues.DynamicSecretMounts {
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      dsm.Name,
+				MountPath: dsm.MountPath,
+				ReadOnly:  dsm.ReadOnly,
+			})
+		}
+		if serviceValues.PersistentVolumeName != "" && serviceValues.PersistentVolumePath != "" {
+			job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+				Name: serviceValues.PersistentVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: serviceValues.PersistentVolumeName,
+					},
+				},
+			})
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      serviceValues.PersistentVolumeName,
+				MountPath: serviceValues.PersistentVolumePath,
+			})
+		}
+		applyResourceOverrides(&container, serviceValues.Resources)
+		job.Spec.Template.Spec.Containers = []corev1.Container{container}
+
+		result = append(result, *job)
+	}
+	return result, nil
+}
+
+func TemplateJob(item batchv1.Job) ([]byte, error) {
+	separator := []byte("---\n")
+	iBytes, err := yaml.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate template: %v", err)
+	}
+	templateYAML := append(separator[:], iBytes[:]...)
+	return templateYAML, nil
+}
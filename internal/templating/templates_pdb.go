@@ -0,0 +1,151 @@
+package templating
+
+import (
+	"fmt"
+
+	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+// GeneratePDBTemplate generates a pod disruption budget for every deployment that runs more than one replica,
+// so that node drains can't evict all the pods of a service at once
+func GeneratePDBTemplate(
+	buildValues generator.BuildValues,
+) ([]policyv1.PodDisruptionBudget, error) {
+	var pdbs []policyv1.PodDisruptionBudget
+
+	// check linked services
+	checkedServices := LinkedServiceCalculator(buildValues.Services)
+
+	// for all the services that the build values generated
+	// iterate over them and generate any pod disruption budgets
+	for _, serviceValues := range checkedServices {
+		serviceTypeValues, ok := servicetypes.ServiceTypes[serviceValues.Type]
+		if !ok || serviceReplicas(serviceValues) < 2 {
+			continue
+		}
+		// add the default labels
+		labels := map[string]string{
+			"app.kubernetes.io/managed-by": "build-deploy-tool",
+			"app.kubernetes.io/name":       serviceTypeValues.Name,
+			"app.kubernetes.io/instance":   serviceValues.OverrideName,
+			"lagoon.sh/project":            buildValues.Project,
+			"lagoon.sh/environment":        buildValues.Environment,
+			"lagoon.sh/environmentType":    buildValues.EnvironmentType,
+			"lagoon.sh/buildType":          buildValues.BuildType,
+			"lagoon.sh/template":           fmt.Sprintf("%s-%s", serviceTypeValues.Name, "0.1.0"),
+			"lagoon.sh/service":            serviceValues.OverrideName,
+			"lagoon.sh/service-type":       serviceTypeValues.Name,
+		}
+
+		// add the default annotations
+		annotations := map[string]string{
+			"lagoon.sh/version": buildValues.LagoonVersion,
+		}
+		if buildValues.BuildType == "branch" {
+			annotations["lagoon.sh/branch"] = buildValues.Branch
+		} else if buildValues.BuildType == "pullrequest" {
+			annotations["lagoon.sh/prNumber"] = buildValues.PRNumber
+			annotations["lagoon.sh/prHeadBranch"] = buildValues.PRHeadBranch
+			annotations["lagoon.sh/prBaseBranch"] = buildValues.PRBaseBranch
+		}
+
+		pdb := policyv1.PodDisruptionBudget{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "PodDisruptionBudget",
+				APIVersion: policyv1.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        serviceValues.OverrideName,
+				Labels:      labels,
+				Annotations: annotations,
+			},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				// the same selector as the deployment
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"app.kubernetes.io/name":     serviceTypeValues.Name,
+						"app.kubernetes.io/instance": serviceValues.OverrideName,
+					},
+				},
+			},
+		}
+		if serviceValues.PodDisruptionBudget != nil {
+			pdb.Spec.MinAvailable = serviceValues.PodDisruptionBudget.MinAvailable
+			pdb.Spec.MaxUnavailable = serviceValues.PodDisruptionBudget.MaxUnavailable
+			if err := checkDisruptionBudget(serviceValues, pdb.Spec); err != nil {
+				return nil, err
+			}
+		} else {
+			maxUnavailable := intstr.FromInt32(1)
+			pdb.Spec.MaxUnavailable = &maxUnavailable
+		}
+		// validate any labels
+		if err := metavalidation.ValidateLabels(pdb.ObjectMeta.Labels, nil); err != nil {
+			if len(err) != 0 {
+				return nil, fmt.Errorf("the labels for %s are not valid: %v", serviceValues.OverrideName, err)
+			}
+		}
+		// check length of labels
+		if err := helpers.CheckLabelLength(pdb.ObjectMeta.Labels); err != nil {
+			return nil, err
+		}
+		pdbs = append(pdbs, pdb)
+	}
+	return pdbs, nil
+}
+
+// serviceReplicas returns the number of replicas the deployment for a service runs, or the minimum if it autoscales
+func serviceReplicas(serviceValues generator.ServiceValues) int32 {
+	if serviceValues.Autoscaling != nil {
+		return serviceValues.Autoscaling.MinReplicas
+	}
+	if serviceValues.Replicas != 0 {
+		return serviceValues.Replicas
+	}
+	return 1
+}
+
+// checkDisruptionBudget returns an error if the disruption budget of a service would never allow any of its pods to be evicted,
+// as that blocks node drains until the budget is changed. Percentages are rounded up, the same as the disruption controller
+func checkDisruptionBudget(serviceValues generator.ServiceValues, spec policyv1.PodDisruptionBudgetSpec) error {
+	replicas := int(serviceReplicas(serviceValues))
+	if spec.MinAvailable != nil {
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(spec.MinAvailable, replicas, true)
+		if err != nil {
+			return fmt.Errorf("the value of the label lagoon.pdb.min-available for %s is not valid: %v", serviceValues.Name, err)
+		}
+		if minAvailable >= replicas {
+			return fmt.Errorf("the label lagoon.pdb.min-available for %s is %s, this must be less than the %d replicas of the service so that a pod can be evicted",
+				serviceValues.Name, spec.MinAvailable.String(), replicas)
+		}
+	}
+	if spec.MaxUnavailable != nil {
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(spec.MaxUnavailable, replicas, true)
+		if err != nil {
+			return fmt.Errorf("the value of the label lagoon.pdb.max-unavailable for %s is not valid: %v", serviceValues.Name, err)
+		}
+		if maxUnavailable < 1 {
+			return fmt.Errorf("the label lagoon.pdb.max-unavailable for %s is %s, this must allow at least one pod to be evicted",
+				serviceValues.Name, spec.MaxUnavailable.String())
+		}
+	}
+	return nil
+}
+
+// TemplatePDB templates a pod disruption budget
+func TemplatePDB(item policyv1.PodDisruptionBudget) ([]byte, error) {
+	separator := []byte("---\n")
+	iBytes, err := yaml.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate template: %v", err)
+	}
+	templateYAML := append(separator[:], iBytes[:]...)
+	return templateYAML, nil
+}
@@ -0,0 +1,111 @@
+package templating
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestGeneratePDBTemplate(t *testing.T) {
+	intOrString := func(s string) *intstr.IntOrString {
+		value := intstr.Parse(s)
+		return &value
+	}
+	tests := []struct {
+		name       string
+		replicas   int32
+		pdb        *generator.PodDisruptionBudget
+		wantPDBs   int
+		wantErrMsg string
+	}{
+		{
+			name:     "test1 single replica has no budget",
+			replicas: 1,
+			pdb:      &generator.PodDisruptionBudget{MinAvailable: intOrString("1")},
+		},
+		{
+			name:     "test2 default budget",
+			replicas: 2,
+			wantPDBs: 1,
+		},
+		{
+			name:     "test3 min available below the replicas",
+			replicas: 3,
+			pdb:      &generator.PodDisruptionBudget{MinAvailable: intOrString("2")},
+			wantPDBs: 1,
+		},
+		{
+			name:       "test4 min available the same as the replicas",
+			replicas:   2,
+			pdb:        &generator.PodDisruptionBudget{MinAvailable: intOrString("2")},
+			wantErrMsg: "the label lagoon.pdb.min-available for myservice is 2, this must be less than the 2 replicas of the service so that a pod can be evicted",
+		},
+		{
+			name:       "test5 min available of all the replicas",
+			replicas:   3,
+			pdb:        &generator.PodDisruptionBudget{MinAvailable: intOrString("100%")},
+			wantErrMsg: "the label lagoon.pdb.min-available for myservice is 100%",
+		},
+		{
+			name:       "test6 min available percentage rounded up to all the replicas",
+			replicas:   2,
+			pdb:        &generator.PodDisruptionBudget{MinAvailable: intOrString("60%")},
+			wantErrMsg: "the label lagoon.pdb.min-available for myservice is 60%",
+		},
+		{
+			name:     "test7 max unavailable percentage",
+			replicas: 2,
+			pdb:      &generator.PodDisruptionBudget{MaxUnavailable: intOrString("50%")},
+			wantPDBs: 1,
+		},
+		{
+			name:       "test8 max unavailable of none",
+			replicas:   2,
+			pdb:        &generator.PodDisruptionBudget{MaxUnavailable: intOrString("0")},
+			wantErrMsg: "the label lagoon.pdb.max-unavailable for myservice is 0, this must allow at least one pod to be evicted",
+		},
+		{
+			name:       "test9 max unavailable of no percent",
+			replicas:   4,
+			pdb:        &generator.PodDisruptionBudget{MaxUnavailable: intOrString("0%")},
+			wantErrMsg: "the label lagoon.pdb.max-unavailable for myservice is 0%",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buildValues := generator.BuildValues{
+				Project:         "example-project",
+				Environment:     "environment-name",
+				EnvironmentType: "production",
+				Namespace:       "myexample-project-environment-name",
+				BuildType:       "branch",
+				LagoonVersion:   "v2.x.x",
+				Branch:          "environment-name",
+				Services: []generator.ServiceValues{
+					{
+						Name:                "myservice",
+						OverrideName:        "myservice",
+						Type:                "basic",
+						Replicas:            tt.replicas,
+						PodDisruptionBudget: tt.pdb,
+					},
+				},
+			}
+			got, err := GeneratePDBTemplate(buildValues)
+			if tt.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrMsg) {
+					t.Fatalf("GeneratePDBTemplate() error = %v, want %v", err, tt.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GeneratePDBTemplate() error = %v", err)
+			}
+			if len(got) != tt.wantPDBs {
+				t.Errorf("GeneratePDBTemplate() = %d budgets, want %d", len(got), tt.wantPDBs)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package templating
+
+import (
+	"fmt"
+
+	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// applyProbeOverrides merges the probe overrides of a service over the probes of its container.
+// A startup probe uses the same check as the readiness probe (or the liveness probe) unless a path or port is provided
+func applyProbeOverrides(container *corev1.Container, probes *generator.Probes) error {
+	if probes == nil {
+		return nil
+	}
+	// the startup probe falls back to the check of the service type probes, before they are overridden
+	fallback := container.ReadinessProbe
+	if fallback == nil {
+		fallback = container.LivenessProbe
+	}
+	startup, err := mergeProbe(container.StartupProbe, probes.Startup, fallback)
+	if err != nil {
+		return fmt.Errorf("the startup probe is not valid: %v", err)
+	}
+	readiness, err := mergeProbe(container.ReadinessProbe, probes.Readiness, nil)
+	if err != nil {
+		return fmt.Errorf("the readiness probe is not valid: %v", err)
+	}
+	liveness, err := mergeProbe(container.LivenessProbe, probes.Liveness, nil)
+	if err != nil {
+		return fmt.Errorf("the liveness probe is not valid: %v", err)
+	}
+	container.StartupProbe = startup
+	container.ReadinessProbe = readiness
+	container.LivenessProbe = liveness
+	return nil
+}
+
+// mergeProbe returns a copy of the probe with the override applied, if there is no probe the check is copied from the fallback
+func mergeProbe(probe *corev1.Probe, override *generator.Probe, fallback *corev1.Probe) (*corev1.Probe, error) {
+	if override == nil {
+		return probe, nil
+	}
+	if override.Disabled {
+		return nil, nil
+	}
+	merged := &corev1.Probe{}
+	if probe != nil {
+		merged = probe.DeepCopy()
+	} else if fallback != nil {
+		merged.ProbeHandler = *fallback.ProbeHandler.DeepCopy()
+	}
+	port := probePort(merged.ProbeHandler)
+	if override.Port != 0 {
+		p := intstr.FromInt32(override.Port)
+		port = &p
+	}
+	if override.Path != "" {
+		if port == nil {
+			return nil, fmt.Errorf("a path is defined, but there is no port to check it on")
+		}
+		merged.ProbeHandler = corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: override.Path,
+				Port: *port,
+			},
+		}
+	} else if override.Port != 0 {
+		if merged.ProbeHandler.HTTPGet != nil {
+			merged.ProbeHandler.HTTPGet.Port = *port
+		} else {
+			merged.ProbeHandler = corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: *port,
+				},
+			}
+		}
+	}
+	if merged.ProbeHandler.Exec == nil && merged.ProbeHandler.HTTPGet == nil &&
+		merged.ProbeHandler.TCPSocket == nil && merged.ProbeHandler.GRPC == nil {
+		return nil, fmt.Errorf("there is no check defined, a path or port is required")
+	}
+	if override.InitialDelaySeconds != nil {
+		merged.InitialDelaySeconds = *override.InitialDelaySeconds
+	}
+	if override.PeriodSeconds != nil {
+		merged.PeriodSeconds = *override.PeriodSeconds
+	}
+	if override.TimeoutSeconds != nil {
+		merged.TimeoutSeconds = *override.TimeoutSeconds
+	}
+	if override.SuccessThreshold != nil {
+		merged.SuccessThreshold = *override.SuccessThreshold
+	}
+	if override.FailureThreshold != nil {
+		merged.FailureThreshold = *override.FailureThreshold
+	}
+	return merged, nil
+}
+
+// probePort returns the port a probe checks, or nil if it doesn't check a port
+func probePort(handler corev1.ProbeHandler) *intstr.IntOrString {
+	switch {
+	case handler.HTTPGet != nil:
+		return &handler.HTTPGet.Port
+	case handler.TCPSocket != nil:
+		return &handler.TCPSocket.Port
+	case handler.GRPC != nil:
+		p := intstr.FromInt32(handler.GRPC.Port)
+		return &p
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package templating
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	"github.com/uselagoon/build-deploy-tool/internal/helpers"
+	"github.com/uselagoon/build-deploy-tool/internal/lagoon"
+	"github.com/uselagoon/build-deploy-tool/internal/servicetypes"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// routeInstanceName returns the truncated route domain used in the labels and tls secret name of a route,
+// if the route is a wildcard route the domain of the route is changed to include the wildcard prefix
+func routeInstanceName(route *lagoon.RouteV2) string {
+	truncatedRouteDomain := route.Domain
+	if len(truncatedRouteDomain) >= 53 {
+		subdomain := strings.Split(truncatedRouteDomain, ".")[0]
+		if errs := utilvalidation.IsValidLabelValue(subdomain); errs != nil {
+			subdomain = subdomain[:53]
+		}
+		truncatedRouteDomain = fmt.Sprintf("%s-%s", strings.Split(subdomain, ".")[0], helpers.GetMD5HashWithNewLine(route.Domain)[:5])
+	}
+
+	// if this is a wildcard ingress, handle templating that here
+	if route.Wildcard != nil && *route.Wildcard {
+		truncatedRouteDomain = fmt.Sprintf("wildcard-%s", truncatedRouteDomain)
+		if len(truncatedRouteDomain) >= 53 {
+			subdomain := strings.Split(truncatedRouteDomain, "-")[0]
+			if errs := utilvalidation.IsValidLabelValue(subdomain); errs != nil {
+				subdomain = subdomain[:53]
+			}
+			truncatedRouteDomain = fmt.Sprintf("%s-%s", strings.Split(subdomain, "-")[0], helpers.GetMD5HashWithNewLine(route.Domain)[:5])
+		}
+		// set the domain to include the wildcard prefix
+		route.Domain = fmt.Sprintf("*.%s", route.Domain)
+	}
+	return truncatedRouteDomain
+}
+
+// routeMetadata returns the labels and annotations that every route has, no matter which backend it is rendered with
+func routeMetadata(route lagoon.RouteV2, lValues generator.BuildValues, truncatedRouteDomain string) (map[string]string, map[string]string) {
+	labels := map[string]string{
+		"lagoon.sh/autogenerated":      "false",
+		"app.kubernetes.io/name":       "custom-ingress",
+		"app.kubernetes.io/instance":   truncatedRouteDomain,
+		"app.kubernetes.io/managed-by": "build-deploy-tool",
+		"lagoon.sh/template":           "custom-ingress-0.1.0",
+		"lagoon.sh/service":            truncatedRouteDomain,
+		"lagoon.sh/service-type":       "custom-ingress",
+		"lagoon.sh/project":            lValues.Project,
+		"lagoon.sh/environment":        lValues.Environment,
+		"lagoon.sh/environmentType":    lValues.EnvironmentType,
+		"lagoon.sh/buildType":          lValues.BuildType,
+	}
+	annotations := map[string]string{
+		"fastly.amazee.io/watch": strconv.FormatBool(route.Fastly.Watch),
+		"lagoon.sh/version":      lValues.LagoonVersion,
+	}
+
+	if lValues.EnvironmentType == "production" && !route.Autogenerated {
+		if route.Migrate != nil {
+			labels["activestandby.lagoon.sh/migrate"] = strconv.FormatBool(*route.Migrate)
+		} else {
+			labels["activestandby.lagoon.sh/migrate"] = "false"
+		}
+	}
+	if lValues.EnvironmentType == "production" {
+		// monitoring is only available in production environments
+		annotations["monitor.stakater.com/enabled"] = "false"
+		primaryIngress, _ := url.Parse(lValues.Route)
+		// check if monitoring enabled, route isn't autogenerated, and the primary ingress from the .lagoon.yml is this processed routedomain
+		// and enable monitoring on the primary ingress only.
+		if lValues.Monitoring.Enabled && !route.Autogenerated && primaryIngress.Host == route.Domain {
+			labels["lagoon.sh/primaryIngress"] = "true"
+
+			// only add the monitring annotations if monitoring is enabled
+			annotations["monitor.stakater.com/enabled"] = "true"
+			annotations["uptimerobot.monitor.stakater.com/alert-contacts"] = "unconfigured"
+			if lValues.Monitoring.AlertContact != "" {
+				annotations["uptimerobot.monitor.stakater.com/alert-contacts"] = lValues.Monitoring.AlertContact
+			}
+			if lValues.Monitoring.StatusPageID != "" {
+				annotations["uptimerobot.monitor.stakater.com/status-pages"] = lValues.Monitoring.StatusPageID
+			}
+			annotations["uptimerobot.monitor.stakater.com/interval"] = "60"
+		}
+		if route.MonitoringPath != "" {
+			annotations["monitor.stakater.com/overridePath"] = route.MonitoringPath
+		}
+	}
+	if route.Fastly.ServiceID != "" {
+		annotations["fastly.amazee.io/service-id"] = route.Fastly.ServiceID
+	}
+	if route.TLSSecret != "" {
+		// the certificate in this secret is provided by the user, it is never removed by the build even though
+		// tls-acme is false for this route
+		annotations["lagoon.sh/tls-secret-provided"] = "true"
+	}
+	if lValues.BuildType == "branch" {
+		annotations["lagoon.sh/branch"] = lValues.Branch
+	} else if lValues.BuildType == "pullrequest" {
+		annotations["lagoon.sh/prNumber"] = lValues.PRNumber
+		annotations["lagoon.sh/prHeadBranch"] = lValues.PRHeadBranch
+		annotations["lagoon.sh/prBaseBranch"] = lValues.PRBaseBranch
+	}
+
+	// if idling request verification is in the `.lagoon.yml` and true, add the annotation. this supports production and development environment types
+	// in the event that production environments support idling properly that option could be available to then
+	// idle standby environments or production environments generally in opensource lagoon
+	if route.RequestVerification != nil && *route.RequestVerification {
+		// @TODO: this will eventually be changed to a `lagoon.sh` instead of `amazee.io` namespaced annotation in the future once
+		// aergia is fully integrated into the uselagoon namespace
+		annotations["idling.amazee.io/disable-request-verification"] = "true"
+	} else {
+		// otherwise force false
+		annotations["idling.amazee.io/disable-request-verification"] = "false"
+	}
+	return labels, annotations
+}
+
+// applyRouteMetadata adds the labels and annotations defined on the route, overwriting any previous values, then validates them
+func applyRouteMetadata(objectMeta *metav1.ObjectMeta, route lagoon.RouteV2) error {
+	// add any annotations that the route had to overwrite any previous annotations
+	for key, value := range route.Annotations {
+		objectMeta.Annotations[key] = value
+	}
+	// add any labels that the route had to overwrite any previous labels
+	for key, value := range route.Labels {
+		objectMeta.Labels[key] = value
+	}
+	// validate any annotations
+	if err := apivalidation.ValidateAnnotations(objectMeta.Annotations, nil); err != nil {
+		if len(err) != 0 {
+			return fmt.Errorf("the annotations for %s are not valid: %v", route.Domain, err)
+		}
+	}
+	// validate any labels
+	if err := metavalidation.ValidateLabels(objectMeta.Labels, nil); err != nil {
+		if len(err) != 0 {
+			return fmt.Errorf("the labels for %s are not valid: %v", route.Domain, err)
+		}
+	}
+	return nil
+}
+
+// routeObjectName returns the name of an additional object for a route, keeping the name within the length limit and unique to the route
+func routeObjectName(name, suffix string) string {
+	objectName := fmt.Sprintf("%s-%s", name, suffix)
+	if len(objectName) > utilvalidation.DNS1123SubdomainMaxLength {
+		objectName = fmt.Sprintf("%s-%s-%s", name[:utilvalidation.DNS1123SubdomainMaxLength-len(suffix)-7], helpers.GetMD5HashWithNewLine(name)[:5], suffix)
+	}
+	return objectName
+}
+
+// canaryBackend returns the service, namespace and port that the canary of a route sends requests to.
+// The namespace is empty if the service is in this environment. The port is looked up from the services of this environment,
+// so a service in another environment must use the same port
+func canaryBackend(route lagoon.RouteV2, lValues generator.BuildValues) (string, string, int32, error) {
+	service := route.Canary.Service
+	if service == "" {
+		service = route.LagoonService
+	}
+	backendService, port, err := routeServicePort(lValues, service)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("couldn't find the canary service for %s: %v", route.Domain, err)
+	}
+	namespace := ""
+	if route.Canary.Environment != "" {
+		namespace = fmt.Sprintf("%s-%s", lValues.Project, route.Canary.Environment)
+	}
+	return backendService, namespace, port, nil
+}
+
+// routeTLS returns the name of the secret the certificate for a route is stored in, and the hosts the certificate is for
+func routeTLS(route lagoon.RouteV2, lValues generator.BuildValues, truncatedRouteDomain string) (string, []string) {
+	// autogenerated use the service name
+	secretName := fmt.Sprintf("%s-tls", route.LagoonService)
+	if !route.Autogenerated {
+		// everything else uses the truncated route domain here as we add `-tls`
+		// if a domain that is 253 chars long is used this will then exceed
+		// the 253 char limit on kubernetes names
+		secretName = fmt.Sprintf("%s-tls", truncatedRouteDomain)
+	}
+	if route.TLSSecret != "" {
+		// the certificate is provided in a secret of the environment
+		secretName = route.TLSSecret
+	}
+
+	hosts := []string{}
+	// autogenerated domains that are too long break when creating the acme challenge k8s resource
+	// this injects a shorter domain into the tls spec that is used in the k8s challenge
+	// use the compose service name to check this, as this is how Services are populated from the compose generation
+	for _, service := range lValues.Services {
+		if service.OverrideName == route.LagoonService {
+			if service.ShortAutogeneratedRouteDomain != "" && len(route.Domain) > 63 {
+				hosts = append(hosts, service.ShortAutogeneratedRouteDomain)
+			}
+		}
+	}
+	// add the main domain and any alternative names
+	hosts = append(hosts, route.Domain)
+	hosts = append(hosts, route.AlternativeNames...)
+	return secretName, hosts
+}
+
+// routeHSTSHeader returns the value of the strict-transport-security header for a route
+func routeHSTSHeader(route lagoon.RouteV2) string {
+	hstsHeader := fmt.Sprintf("max-age=%d", route.HSTSMaxAge)
+	if route.HSTSIncludeSubdomains != nil && *route.HSTSIncludeSubdomains {
+		hstsHeader = fmt.Sprintf("%s%s", hstsHeader, ";includeSubDomains")
+	}
+	if route.HSTSPreload != nil && *route.HSTSPreload {
+		hstsHeader = fmt.Sprintf("%s%s", hstsHeader, ";preload")
+	}
+	return hstsHeader
+}
+
+// routeServicePort returns the kubernetes service and the port number that a route to the provided service is sent to.
+// Gateway API backends can't use named ports, so the port is looked up from the ports of the service
+func routeServicePort(lValues generator.BuildValues, serviceName string) (string, int32, error) {
+	for _, service := range lValues.Services {
+		// if a specific 'servicename-port' has been provided, use that port
+		for _, addPort := range service.AdditionalServicePorts {
+			if addPort.ServiceName == serviceName {
+				return addPort.ServiceOverrideName, int32(addPort.ServicePort.Target), nil
+			}
+		}
+		if service.OverrideName != serviceName {
+			continue
+		}
+		// the first additional port is the "default" port of the service
+		if len(service.AdditionalServicePorts) > 0 {
+			return service.OverrideName, int32(service.AdditionalServicePorts[0].ServicePort.Target), nil
+		}
+		// otherwise it is the first port of the service type, which is always the http port
+		if serviceType, ok := servicetypes.ServiceTypes[service.Type]; ok && len(serviceType.Ports.Ports) > 0 {
+			if serviceType.Ports.CanChangePort && service.ServicePort != 0 {
+				return service.OverrideName, service.ServicePort, nil
+			}
+			return service.OverrideName, serviceType.Ports.Ports[0].Port, nil
+		}
+	}
+	return "", 0, fmt.Errorf("couldn't find a http port for the service %s", serviceName)
+}
@@ -0,0 +1,75 @@
+package templating
+
+import (
+	"fmt"
+
+	"github.com/uselagoon/build-deploy-tool/internal/generator"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// generateSidecarContainers creates the containers for the sidecars or init containers of the service, they use their own image,
+// command and resources, and share the volumes that are mounted in the primary container
+func generateSidecarContainers(
+	buildValues generator.BuildValues,
+	serviceValues generator.ServiceValues,
+	sidecars []generator.ServiceValues,
+	primary corev1.Container,
+	podSpec corev1.PodSpec,
+) ([]corev1.Container, error) {
+	containers := []corev1.Container{}
+	for _, sidecar := range sidecars {
+		for _, c := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+			if c.Name == sidecar.Name {
+				return nil, fmt.Errorf("%s %s of service %s has the same name as a container in the service", sidecar.Type, sidecar.Name, serviceValues.Name)
+			}
+		}
+		container := corev1.Container{
+			Name:            sidecar.Name,
+			ImagePullPolicy: corev1.PullAlways,
+			Command:         sidecar.Command,
+		}
+		if val, ok := buildValues.ImageReferences[sidecar.Name]; ok {
+			container.Image = val
+		} else {
+			return nil, fmt.Errorf("no image reference was found for %s %s of service %s", sidecar.Type, sidecar.Name, serviceValues.Name)
+		}
+		container.Env = []corev1.EnvVar{
+			{
+				Name:  "LAGOON_GIT_SHA",
+				Value: buildValues.GitSHA,
+			},
+			{
+				Name:  "SERVICE_NAME",
+				Value: serviceValues.OverrideName,
+			},
+		}
+		container.EnvFrom = lagoonEnvFrom(buildValues)
+		container.VolumeMounts = append(container.VolumeMounts, primary.VolumeMounts...)
+		applyResourceOverrides(&container, sidecar.Resources)
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+// lagoonEnvFrom returns the lagoon-env configmap and any dbaas secrets for a container to consume
+func lagoonEnvFrom(buildValues generator.BuildValues) []corev1.EnvFromSource {
+	envFrom := []corev1.EnvFromSource{
+		{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: "lagoon-env",
+				},
+			},
+		},
+	}
+	for _, dds := range buildValues.DynamicDBaaSSecrets {
+		envFrom = append(envFrom, corev1.EnvFromSource{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: dds,
+				},
+			},
+		})
+	}
+	return envFrom
+}
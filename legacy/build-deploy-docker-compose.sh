@@ -355,6 +355,30 @@ else
 	echo "lagoon-linter found no issues with the .lagoon.yml file"
 fi
 
+##############################################
+### check that the services referenced in the .lagoon.yml exist in the docker-compose file
+### this is a warning for now, as lagoon has previously ignored these references
+##############################################
+set +e
+lyrOutput=$(bash -c 'build-deploy-tool validate references; exit $?' 2>&1)
+lyrExit=$?
+set -e
+if [ "${lyrExit}" != "0" ]; then
+  ((++BUILD_WARNING_COUNT))
+  echo "
+##############################################
+Warning!
+There are references in your .lagoon.yml file to services that don't exist in your docker-compose file.
+Anything using these references will not work as expected, and should be fixed.
+"
+  echo "${lyrOutput}"
+  echo "
+##############################################"
+  currentStepEnd="$(date +"%Y-%m-%d %H:%M:%S")"
+  patchBuildStep "${buildStartTime}" "${previousStepEnd}" "${currentStepEnd}" "${NAMESPACE}" "lagoonYmlReferencesWarning" ".lagoon.yml References" "true"
+  previousStepEnd=${currentStepEnd}
+fi
+
 ##################
 # build deploy-tool can collect this value now from the lagoon.yml file
 # this means further use of `LAGOON_GIT_SHA` can eventually be
@@ -498,12 +522,17 @@ do
   # The ImageName is the same as the Name of the Docker Compose ServiceName
   IMAGE_NAME=$COMPOSE_SERVICE
 
+  # services that identify dbaas reports as a dbaas type, including the types from the dbaas types file, have no image
+  IS_DBAAS_SERVICE=false
   for DBAAS_ENTRY in "${DBAAS[@]}"
   do
     IFS=':' read -ra DBAAS_ENTRY_SPLIT <<< "$DBAAS_ENTRY"
     DBAAS_SERVICE_NAME=${DBAAS_ENTRY_SPLIT[0]}
     DBAAS_SERVICE_TYPE=${DBAAS_ENTRY_SPLIT[1]}
     if [ "$DBAAS_SERVICE_NAME" == "$SERVICE_NAME" ]; then
+      if [[ "$DBAAS_SERVICE_TYPE" == *-dbaas ]]; then
+        IS_DBAAS_SERVICE=true
+      fi
       if [ "$SERVICE_TYPE" == "mariadb" ]; then
         SERVICE_TYPE=$DBAAS_SERVICE_TYPE
       fi
@@ -522,6 +551,7 @@ do
       [[ "$SERVICE_TYPE" != "postgres-shared" ]] &&
       [[ "$SERVICE_TYPE" != "postgres-dbaas" ]] &&
       [[ "$SERVICE_TYPE" != "mongodb-dbaas" ]] &&
+      [[ "$IS_DBAAS_SERVICE" != "true" ]] &&
       [[ "$SERVICE_TYPE" != "mongodb-shared" ]]; then
     # Generate list of images to build
     IMAGES+=("${IMAGE_NAME}")
@@ -848,7 +878,9 @@ else
 fi
 
 # identify any autognerated resources based on their resource name
+# routes attached to a gateway are httproutes, so the ingress of a route that has moved to a gateway is removed, and the other way around
 AUTOGEN_INGRESS=$(build-deploy-tool identify created-ingress | jq -r '.autogenerated[]')
+AUTOGEN_HTTPROUTES=$(build-deploy-tool identify created-httproute | jq -r '.autogenerated[]')
 AUTOGEN_ROUTES=$(kubectl -n ${NAMESPACE} get ingress --no-headers -l "lagoon.sh/autogenerated=true" | cut -d " " -f 1 | xargs)
 MATCHED_AUTOGEN=false
 DELETE_AUTOGEN=()
@@ -865,12 +897,37 @@ for AR in $AUTOGEN_ROUTES; do
   MATCHED_AUTOGEN=false
 done
 for DA in ${!DELETE_AUTOGEN[@]}; do
-  # delete any autogenerated ingress in the namespace as they are disabled
+  # delete any autogenerated ingress in the namespace as they are disabled or replaced by a httproute
   if kubectl -n ${NAMESPACE} get ingress ${DELETE_AUTOGEN[$DA]} &> /dev/null; then
-    echo ">> Removing autogenerated ingress for ${DELETE_AUTOGEN[$DA]} because it was disabled"
+    if echo "${AUTOGEN_HTTPROUTES}" | grep -qxF "${DELETE_AUTOGEN[$DA]}"; then
+      echo ">> Removing autogenerated ingress for ${DELETE_AUTOGEN[$DA]} because it has been replaced by a httproute"
+    else
+      echo ">> Removing autogenerated ingress for ${DELETE_AUTOGEN[$DA]} because it was disabled"
+    fi
     kubectl -n ${NAMESPACE} delete ingress ${DELETE_AUTOGEN[$DA]}
   fi
 done
+AUTOGEN_ROUTES=$(kubectl -n ${NAMESPACE} get httproute --no-headers -l "lagoon.sh/autogenerated=true,acme.cert-manager.io/http01-solver!=true" 2> /dev/null | cut -d " " -f 1 | xargs)
+DELETE_AUTOGEN=()
+for AR in $AUTOGEN_ROUTES; do
+  for AI in $AUTOGEN_HTTPROUTES; do
+    if [ "${AR}" == "${AI}" ]; then
+      MATCHED_AUTOGEN=true
+      continue
+    fi
+  done
+  if [ "${MATCHED_AUTOGEN}" != "true" ]; then
+    DELETE_AUTOGEN+=($AR)
+  fi
+  MATCHED_AUTOGEN=false
+done
+for DA in ${!DELETE_AUTOGEN[@]}; do
+  # delete any autogenerated httproute in the namespace as they are disabled or replaced by an ingress
+  if kubectl -n ${NAMESPACE} get httproute ${DELETE_AUTOGEN[$DA]} &> /dev/null; then
+    echo ">> Removing autogenerated httproute for ${DELETE_AUTOGEN[$DA]} because it was disabled or replaced by an ingress"
+    kubectl -n ${NAMESPACE} delete httproute ${DELETE_AUTOGEN[$DA]}
+  fi
+done
 
 for SERVICE_TYPES_ENTRY in "${SERVICE_TYPES[@]}"
 do
@@ -965,6 +1022,31 @@ done
 # collect the routes that Lagoon thinks it should have based on the .lagoon.yml and any routes that have come from the api
 # using the build-deploy-tool generator
 YAML_ROUTES_TO_JSON=$(build-deploy-tool identify created-ingress | jq -r '.secondary[]')
+YAML_HTTPROUTES_TO_JSON=$(build-deploy-tool identify created-httproute | jq -r '.secondary[]')
+
+# an ingress or httproute that has been replaced because the route backend changed is always removed, as the route is still served.
+# the path and canary ingresses of a route, and the redirect httproute of a route, go with it
+REMAINING_ROUTES=""
+for SINGLE_ROUTE in ${CURRENT_ROUTES}; do
+  if echo "${YAML_HTTPROUTES_TO_JSON}" | grep -qxF -e "${SINGLE_ROUTE}" -e "${SINGLE_ROUTE%-canary}" -e "${SINGLE_ROUTE%-path-*}"; then
+    echo ">> Removing ingress ${SINGLE_ROUTE} because it has been replaced by a httproute"
+    kubectl -n ${NAMESPACE} delete ingress ${SINGLE_ROUTE}
+  else
+    REMAINING_ROUTES="${REMAINING_ROUTES} ${SINGLE_ROUTE}"
+  fi
+done
+CURRENT_ROUTES=${REMAINING_ROUTES}
+CURRENT_HTTPROUTES=$(kubectl -n ${NAMESPACE} get httproute -l "lagoon.sh/autogenerated!=true,acme.cert-manager.io/http01-solver!=true,lagoon.sh/remove!=false" --no-headers 2> /dev/null | cut -d " " -f 1 | xargs)
+REMAINING_ROUTES=""
+for SINGLE_ROUTE in ${CURRENT_HTTPROUTES}; do
+  if echo "${YAML_ROUTES_TO_JSON}" | grep -qxF -e "${SINGLE_ROUTE}" -e "${SINGLE_ROUTE%-redirect}"; then
+    echo ">> Removing httproute ${SINGLE_ROUTE} because it has been replaced by an ingress"
+    kubectl -n ${NAMESPACE} delete httproute ${SINGLE_ROUTE}
+  else
+    REMAINING_ROUTES="${REMAINING_ROUTES} ${SINGLE_ROUTE}"
+  fi
+done
+CURRENT_HTTPROUTES=${REMAINING_ROUTES}
 
 MATCHED_INGRESS=false
 DELETE_INGRESS=()
@@ -982,9 +1064,22 @@ for SINGLE_ROUTE in ${CURRENT_ROUTES}; do
   fi
   MATCHED_INGRESS=false
 done
+DELETE_HTTPROUTE=()
+for SINGLE_ROUTE in ${CURRENT_HTTPROUTES}; do
+  for YAML_ROUTE in ${YAML_HTTPROUTES_TO_JSON}; do
+    if [ "${SINGLE_ROUTE}" == "${YAML_ROUTE}" ]; then
+      MATCHED_INGRESS=true
+      continue
+    fi
+  done
+  if [ "${MATCHED_INGRESS}" != "true" ]; then
+    DELETE_HTTPROUTE+=($SINGLE_ROUTE)
+  fi
+  MATCHED_INGRESS=false
+done
 
 CLEANUP_WARNINGS="false"
-if [ ${#DELETE_INGRESS[@]} -ne 0 ]; then
+if [ ${#DELETE_INGRESS[@]} -ne 0 ] || [ ${#DELETE_HTTPROUTE[@]} -ne 0 ]; then
   CLEANUP_WARNINGS="true"
   ((++BUILD_WARNING_COUNT))
   echo ">> Lagoon detected routes that have been removed from the .lagoon.yml or Lagoon API"
@@ -1010,10 +1105,50 @@ if [ ${#DELETE_INGRESS[@]} -ne 0 ]; then
       echo "> The route '${DI}' would be removed"
     fi
   done
+  for DI in ${DELETE_HTTPROUTE[@]}
+  do
+    if [ "$(featureFlag CLEANUP_REMOVED_LAGOON_ROUTES)" = enabled ]; then
+      if kubectl -n ${NAMESPACE} get httproute ${DI} &> /dev/null; then
+        echo ">> Removing httproute ${DI}"
+        kubectl -n ${NAMESPACE} delete httproute ${DI}
+      fi
+    else
+      echo "> The route '${DI}' would be removed"
+    fi
+  done
 else
   echo "No route cleanup required"
 fi
 
+# remove the certificates of gateway routes that are no longer generated, unless the httproute they were for has been kept.
+# certificates that cert-manager creates for an ingress are owned by the ingress, and are removed with it
+YAML_CERTIFICATES_TO_JSON=$(build-deploy-tool identify created-httproute | jq -r '.certificates[]')
+KEPT_ROUTE_INSTANCES=$(kubectl -n ${NAMESPACE} get httproute -o json 2> /dev/null | jq -r --arg generated "$(echo ${YAML_HTTPROUTES_TO_JSON} ${AUTOGEN_HTTPROUTES})" \
+  '($generated | split(" ")) as $names | .items[] | select(.metadata.name as $name | $names | index($name) | not) | .metadata.labels["app.kubernetes.io/instance"] // empty')
+CURRENT_CERTIFICATES=$(kubectl -n ${NAMESPACE} get certificate -l "app.kubernetes.io/managed-by=build-deploy-tool" -o json 2> /dev/null | jq -r '.items[] | select(.metadata.ownerReferences == null) | "\(.metadata.name) \(.metadata.labels["app.kubernetes.io/instance"] // "")"')
+while read -r CERTIFICATE CERTIFICATE_INSTANCE; do
+  if [ -z "${CERTIFICATE}" ] || echo "${YAML_CERTIFICATES_TO_JSON}" | grep -qxF "${CERTIFICATE}"; then
+    continue
+  fi
+  if [ -n "${CERTIFICATE_INSTANCE}" ] && echo "${KEPT_ROUTE_INSTANCES}" | grep -qxF "${CERTIFICATE_INSTANCE}"; then
+    continue
+  fi
+  echo ">> Removing certificate ${CERTIFICATE} because it is no longer used by a route"
+  kubectl -n ${NAMESPACE} delete certificate ${CERTIFICATE}
+done <<< "${CURRENT_CERTIFICATES}"
+
+# remove the services of canaries in other environments that are no longer generated, unless an ingress that has been kept still uses them
+YAML_CANARY_SERVICES_TO_JSON=$(build-deploy-tool identify created-ingress | jq -r '.canaryServices[]?')
+USED_CANARY_SERVICES=$(kubectl -n ${NAMESPACE} get ingress -o json 2> /dev/null | jq -r '.items[].spec.rules[]?.http.paths[]?.backend.service.name // empty' | sort -u)
+CURRENT_CANARY_SERVICES=$(kubectl -n ${NAMESPACE} get service -l "lagoon.sh/canary-service=true" --no-headers 2> /dev/null | cut -d " " -f 1 | xargs)
+for CANARY_SERVICE in ${CURRENT_CANARY_SERVICES}; do
+  if echo "${YAML_CANARY_SERVICES_TO_JSON}" | grep -qxF "${CANARY_SERVICE}" || echo "${USED_CANARY_SERVICES}" | grep -qxF "${CANARY_SERVICE}"; then
+    continue
+  fi
+  echo ">> Removing canary service ${CANARY_SERVICE} because it is no longer used by a route"
+  kubectl -n ${NAMESPACE} delete service ${CANARY_SERVICE}
+done
+
 currentStepEnd="$(date +"%Y-%m-%d %H:%M:%S")"
 patchBuildStep "${buildStartTime}" "${previousStepEnd}" "${currentStepEnd}" "${NAMESPACE}" "routeCleanupComplete" "Route/Ingress Cleanup" "${CLEANUP_WARNINGS}"
 
@@ -1067,8 +1202,18 @@ if [[ "$CUSTOM_ROUTES_DISABLED" == true ]] && [[ "$AUTOGEN_ROUTES_DISABLED" == t
   ROUTE=""
 fi
 
+# routeURLs prints the urls of the ingresses and httproutes that match a label selector with the correct schema and comma separated.
+# the httproutes that redirect the http listener of a gateway to https are skipped, and each url is only printed once
+# as the path and canary ingresses of a route have the same host as the route
+function routeURLs() {
+  {
+    kubectl -n ${NAMESPACE} get ingress --sort-by='{.metadata.name}' -l "${1}" -o json 2> /dev/null | jq -r '.items[] | (if .spec.tls then "https://" else "http://" end) as $schema | .spec.rules[]? | $schema + .host'
+    kubectl -n ${NAMESPACE} get httproute --sort-by='{.metadata.name}' -l "${1}" -o json 2> /dev/null | jq -r '.items[] | select(all(.spec.parentRefs[]?; .sectionName != "http")) | .spec.hostnames[]? | "https://" + .'
+  } | awk '!seen[$0]++' | paste -sd, -
+}
+
 # Load all routes with correct schema and comma separated
-ROUTES=$(kubectl -n ${NAMESPACE} get ingress --sort-by='{.metadata.name}' -l "acme.cert-manager.io/http01-solver!=true" -o=go-template --template='{{range $indexItems, $ingress := .items}}{{if $indexItems}},{{end}}{{$tls := .spec.tls}}{{range $indexRule, $rule := .spec.rules}}{{if $indexRule}},{{end}}{{if $tls}}https://{{else}}http://{{end}}{{.host}}{{end}}{{end}}')
+ROUTES=$(routeURLs "acme.cert-manager.io/http01-solver!=true")
 
 # swap dioscuri for activestanby label
 for ingress in $(kubectl  -n ${NAMESPACE} get ingress -l "dioscuri.amazee.io/migrate" -o json | jq -r '.items[] | @base64'); do
@@ -1090,12 +1235,12 @@ done
 ACTIVE_ROUTES=""
 STANDBY_ROUTES=""
 if [ ! -z "${STANDBY_ENVIRONMENT}" ]; then
-ACTIVE_ROUTES=$(kubectl -n ${NAMESPACE} get ingress --sort-by='{.metadata.name}' -l "activestandby.lagoon.sh/migrate=true" -o=go-template --template='{{range $indexItems, $ingress := .items}}{{if $indexItems}},{{end}}{{$tls := .spec.tls}}{{range $indexRule, $rule := .spec.rules}}{{if $indexRule}},{{end}}{{if $tls}}https://{{else}}http://{{end}}{{.host}}{{end}}{{end}}')
-STANDBY_ROUTES=$(kubectl -n ${NAMESPACE} get ingress --sort-by='{.metadata.name}' -l "activestandby.lagoon.sh/migrate=true" -o=go-template --template='{{range $indexItems, $ingress := .items}}{{if $indexItems}},{{end}}{{$tls := .spec.tls}}{{range $indexRule, $rule := .spec.rules}}{{if $indexRule}},{{end}}{{if $tls}}https://{{else}}http://{{end}}{{.host}}{{end}}{{end}}')
+ACTIVE_ROUTES=$(routeURLs "activestandby.lagoon.sh/migrate=true")
+STANDBY_ROUTES=$(routeURLs "activestandby.lagoon.sh/migrate=true")
 fi
 
 # Get list of autogenerated routes
-AUTOGENERATED_ROUTES=$(kubectl -n ${NAMESPACE} get ingress --sort-by='{.metadata.name}' -l "lagoon.sh/autogenerated=true" -o=go-template --template='{{range $indexItems, $ingress := .items}}{{if $indexItems}},{{end}}{{$tls := .spec.tls}}{{range $indexRule, $rule := .spec.rules}}{{if $indexRule}},{{end}}{{if $tls}}https://{{else}}http://{{end}}{{.host}}{{end}}{{end}}')
+AUTOGENERATED_ROUTES=$(routeURLs "lagoon.sh/autogenerated=true")
 
 yq3 write -i -- /kubectl-build-deploy/values.yaml 'route' "$ROUTE"
 yq3 write -i -- /kubectl-build-deploy/values.yaml 'routes' "$ROUTES"
@@ -1199,6 +1344,12 @@ do
         . /kubectl-build-deploy/scripts/exec-kubectl-mongodb-dbaas.sh
         ;;
 
+    *-dbaas)
+        # any other dbaas types use the variables defined for the type in the dbaas type registry
+        unset IMAGES_PULL[$SERVICE_NAME]
+        build-deploy-tool deploy dbaas-variables --service ${SERVICE_NAME}
+        ;;
+
     *)
         echo "DBAAS Type ${SERVICE_TYPE} not implemented"; exit 1;
 
@@ -1455,8 +1606,10 @@ done
 
 echo "=== BEGIN deployment template for services ==="
 LAGOON_SERVICES_YAML_FOLDER="/kubectl-build-deploy/lagoon/service-deployments"
+# jobs are templated into their own folder, kubectl can't apply them as the template of a job can't be changed
+LAGOON_JOBS_YAML_FOLDER="/kubectl-build-deploy/lagoon/jobs"
 mkdir -p $LAGOON_SERVICES_YAML_FOLDER
-build-deploy-tool template lagoon-services --saved-templates-path ${LAGOON_SERVICES_YAML_FOLDER} --images /kubectl-build-deploy/images.yaml
+build-deploy-tool template lagoon-services --saved-templates-path ${LAGOON_SERVICES_YAML_FOLDER} --jobs-path ${LAGOON_JOBS_YAML_FOLDER} --images /kubectl-build-deploy/images.yaml
 
 currentStepEnd="$(date +"%Y-%m-%d %H:%M:%S")"
 patchBuildStep "${buildStartTime}" "${previousStepEnd}" "${currentStepEnd}" "${NAMESPACE}" "deploymentTemplatingComplete" "Deployment Templating" "false"
@@ -1481,42 +1634,83 @@ if [ "$(ls -A $LAGOON_SERVICES_YAML_FOLDER/)" ]; then
   # cat $LAGOON_SERVICES_YAML_FOLDER/pvcs.yaml
   # cat $LAGOON_SERVICES_YAML_FOLDER/deployments.yaml
   # cat $LAGOON_SERVICES_YAML_FOLDER/cronjobs.yaml
-  if [ -n "$(ls -A $LAGOON_SERVICES_YAML_FOLDER/ 2>/dev/null)" ]; then
+  if [ -n "$(ls -A $LAGOON_JOBS_YAML_FOLDER/ 2>/dev/null)" ]; then
+    echo "=== job templates for services ==="
+    ls -A $LAGOON_JOBS_YAML_FOLDER
+    find $LAGOON_SERVICES_YAML_FOLDER $LAGOON_JOBS_YAML_FOLDER -type f -exec cat {} \;
+    # deploy apply runs the jobs once the volumes and secrets they use are applied, and only applies the deployments
+    # if every job succeeds. routes and other resources are applied separately, so nothing is pruned here
+    if ! build-deploy-tool deploy apply --namespace ${NAMESPACE} --path $LAGOON_SERVICES_YAML_FOLDER --path $LAGOON_JOBS_YAML_FOLDER --prune=false; then
+      echo "##############################################"
+      echo "STEP Applying Deployments: Failed at $(date +"%Y-%m-%d %H:%M:%S") ($(date +"%Z"))"
+      echo "The information above could be useful in helping debug what went wrong"
+      echo "##############################################"
+      exit 1
+    fi
+  elif [ -n "$(ls -A $LAGOON_SERVICES_YAML_FOLDER/ 2>/dev/null)" ]; then
     find $LAGOON_SERVICES_YAML_FOLDER -type f -exec cat {} \;
     kubectl apply -n ${NAMESPACE} -f $LAGOON_SERVICES_YAML_FOLDER/
   fi
 fi
 
-##############################################
-### WAIT FOR POST-ROLLOUT TO BE FINISHED
-##############################################
-
-for SERVICE_TYPES_ENTRY in "${SERVICE_TYPES[@]}"
-do
-
-  IFS=':' read -ra SERVICE_TYPES_ENTRY_SPLIT <<< "$SERVICE_TYPES_ENTRY"
-
-  SERVICE_NAME=${SERVICE_TYPES_ENTRY_SPLIT[0]}
-  SERVICE_TYPE=${SERVICE_TYPES_ENTRY_SPLIT[1]}
-
-  # check if this service is a dbaas service and transform the service_type accordingly
-  for DBAAS_ENTRY in "${DBAAS[@]}"
-  do
-    IFS=':' read -ra DBAAS_ENTRY_SPLIT <<< "$DBAAS_ENTRY"
-    DB_SERVICE_NAME=${DBAAS_ENTRY_SPLIT[0]}
-    DB_SERVICE_TYPE=${DBAAS_ENTRY_SPLIT[1]}
-    if [ $SERVICE_NAME == $DB_SERVICE_NAME ]; then
-      SERVICE_TYPE=$DB_SERVICE_TYPE
+# services are applied without pruning, so remove any horizontal pod autoscalers the tool created that are no longer generated
+CURRENT_HPAS=$(kubectl -n ${NAMESPACE} get hpa -l app.kubernetes.io/managed-by=build-deploy-tool --no-headers 2>/dev/null | cut -d " " -f 1 | xargs)
+MATCHED_HPA=false
+DELETE_HPAS=()
+GENERATED_HPAS=$(find $LAGOON_SERVICES_YAML_FOLDER -maxdepth 1 -type f -name 'hpa-*.yaml' 2>/dev/null | sed -e 's|.*/hpa-||' -e 's|\.yaml$||' | xargs)
+for SINGLE_HPA in $CURRENT_HPAS; do
+  for GENERATED_HPA in $GENERATED_HPAS; do
+    if [ "${SINGLE_HPA}" == "${GENERATED_HPA}" ]; then
+      MATCHED_HPA=true
+      continue
     fi
   done
+  if [ "${MATCHED_HPA}" != "true" ]; then
+    DELETE_HPAS+=($SINGLE_HPA)
+  fi
+  MATCHED_HPA=false
+done
+for DH in ${!DELETE_HPAS[@]}; do
+  echo ">> Removing horizontalpodautoscaler ${DELETE_HPAS[$DH]} because it was removed"
+  kubectl -n ${NAMESPACE} delete hpa ${DELETE_HPAS[$DH]}
+done
 
-  if [[ $SERVICE_TYPE == *"-dbaas" ]]; then
-    echo "nothing to monitor for $SERVICE_TYPE"
-  else
-    . /kubectl-build-deploy/scripts/exec-monitor-deploy.sh
+# the same goes for pod disruption budgets
+CURRENT_PDBS=$(kubectl -n ${NAMESPACE} get pdb -l app.kubernetes.io/managed-by=build-deploy-tool --no-headers 2>/dev/null | cut -d " " -f 1 | xargs)
+MATCHED_PDB=false
+DELETE_PDBS=()
+GENERATED_PDBS=$(find $LAGOON_SERVICES_YAML_FOLDER -maxdepth 1 -type f -name 'pdb-*.yaml' 2>/dev/null | sed -e 's|.*/pdb-||' -e 's|\.yaml$||' | xargs)
+for SINGLE_PDB in $CURRENT_PDBS; do
+  for GENERATED_PDB in $GENERATED_PDBS; do
+    if [ "${SINGLE_PDB}" == "${GENERATED_PDB}" ]; then
+      MATCHED_PDB=true
+      continue
+    fi
+  done
+  if [ "${MATCHED_PDB}" != "true" ]; then
+    DELETE_PDBS+=($SINGLE_PDB)
   fi
+  MATCHED_PDB=false
+done
+for DP in ${!DELETE_PDBS[@]}; do
+  echo ">> Removing poddisruptionbudget ${DELETE_PDBS[$DP]} because it was removed"
+  kubectl -n ${NAMESPACE} delete pdb ${DELETE_PDBS[$DP]}
 done
 
+##############################################
+### WAIT FOR POST-ROLLOUT TO BE FINISHED
+##############################################
+
+# wait for all the deployments to roll out at the same time, if any fail a summary of why is shown for each service
+# the deployments are read from the service templates that were just applied, dbaas services don't have a deployment so there is nothing to monitor for them
+if ! build-deploy-tool deploy wait --path $LAGOON_SERVICES_YAML_FOLDER; then
+  echo "##############################################"
+  echo "STEP Applying Deployments: Failed at $(date +"%Y-%m-%d %H:%M:%S") ($(date +"%Z"))"
+  echo "The information above could be useful in helping debug what went wrong"
+  echo "##############################################"
+  exit 1
+fi
+
 currentStepEnd="$(date +"%Y-%m-%d %H:%M:%S")"
 patchBuildStep "${buildStartTime}" "${previousStepEnd}" "${currentStepEnd}" "${NAMESPACE}" "deploymentApplyComplete" "Applying Deployments" "false"
 previousStepEnd=${currentStepEnd}
@@ -1594,7 +1788,8 @@ if kubectl -n ${NAMESPACE} get configmap docker-compose-yaml &> /dev/null; then
 fi
 
 # remove any certificates for tls-acme false ingress to prevent reissuing attempts
-TLS_FALSE_INGRESSES=$(kubectl -n ${NAMESPACE} get ingress -o json | jq -r '.items[] | select(.metadata.annotations["kubernetes.io/tls-acme"] == "false") | .metadata.name')
+# ingresses that use a certificate the user provided in a tlsSecret are skipped, the secret isn't one the build created
+TLS_FALSE_INGRESSES=$(kubectl -n ${NAMESPACE} get ingress -o json | jq -r '.items[] | select(.metadata.annotations["kubernetes.io/tls-acme"] == "false") | select(.metadata.annotations["lagoon.sh/tls-secret-provided"] != "true") | .metadata.name')
 for TLS_FALSE_INGRESS in $TLS_FALSE_INGRESSES; do
   TLS_SECRETS=$(kubectl -n ${NAMESPACE} get ingress ${TLS_FALSE_INGRESS} -o json | jq -r '.spec.tls[]?.secretName')
   for TLS_SECRET in $TLS_SECRETS; do